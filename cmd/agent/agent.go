@@ -0,0 +1,35 @@
+package agent
+
+import (
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+
+	"github.com/spf13/cobra"
+)
+
+// NewAgentCmd creates the agent command group.
+func NewAgentCmd(f *cmdutil.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Manage morpher agents",
+		Long:  `Manage the morpher agents registered with the controller.`,
+	}
+
+	cmd.AddCommand(
+		newListCmd(f),
+		newGetCmd(f),
+		newDescribeCmd(f),
+		newInstallCmd(f),
+		newUninstallCmd(f),
+		newCordonCmd(f),
+		newUncordonCmd(f),
+		newDrainCmd(f),
+		newLabelCmd(f),
+		newAnnotateCmd(f),
+		newLogsCmd(f),
+		newLogLevelCmd(f),
+		newUpgradeCmd(f),
+		newTokenCmd(f),
+	)
+
+	return cmd
+}
@@ -0,0 +1,1013 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+	"github.com/morpher-vm/morpherctl/internal/install"
+	"github.com/morpher-vm/morpherctl/internal/sshtest"
+	"github.com/morpher-vm/morpherctl/internal/upgrade"
+	"github.com/morpher-vm/morpherctl/pkg/client"
+	"github.com/morpher-vm/morpherctl/pkg/fakecontroller"
+)
+
+// runAgentCmd runs the agent command group against a fake controller with demo data.
+func runAgentCmd(t *testing.T, fake *fakecontroller.Server, output string, args ...string) (string, string, error) {
+	t.Helper()
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	streams, _, out, errOut := cmdutil.NewTestIOStreams()
+	f := cmdutil.NewFactory(streams)
+	f.OutputFormat = output
+	f.ControllerClient = func() (*client.Client, error) {
+		return client.NewClient(server.URL, time.Second, ""), nil
+	}
+
+	cmd := NewAgentCmd(f)
+	cmd.SilenceUsage = true
+	cmd.SetArgs(args)
+	cmd.SetOut(streams.Out)
+	cmd.SetErr(streams.ErrOut)
+	err := cmd.ExecuteContext(context.Background())
+	return out.String(), errOut.String(), err
+}
+
+func TestAgentList(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		expectedOut string
+	}{
+		{
+			name:        "should list agents sorted by id",
+			args:        []string{"list"},
+			expectedOut: "agent-1 agent-2 agent-3 ",
+		},
+		{
+			name:        "should filter by status",
+			args:        []string{"list", "--status", client.AgentNotReady},
+			expectedOut: "agent-3 ",
+		},
+		{
+			name:        "should filter by selector",
+			args:        []string{"list", "-l", "zone=b"},
+			expectedOut: "agent-2 agent-3 ",
+		},
+		{
+			name:        "should sort by heartbeat with most recent first",
+			args:        []string{"list", "--sort-by", "heartbeat"},
+			expectedOut: "agent-1 agent-2 agent-3 ",
+		},
+		{
+			name:        "should sort by hostname",
+			args:        []string{"list", "--sort-by", "hostname"},
+			expectedOut: "agent-1 agent-2 agent-3 ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, _, err := runAgentCmd(t, fakecontroller.New(fakecontroller.WithDemoData()),
+				"jsonpath={range [*]}{.id} {end}", tt.args...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedOut, out)
+		})
+	}
+
+	t.Run("should print table with active migrations and labels", func(t *testing.T) {
+		out, _, err := runAgentCmd(t, fakecontroller.New(fakecontroller.WithDemoData()), "table", "list", "--status", "ready")
+		require.NoError(t, err)
+
+		lines := splitLines(out)
+		require.Len(t, lines, 3)
+		assert.Regexp(t, `^ID\s+HOSTNAME\s+VERSION\s+STATUS\s+LAST HEARTBEAT\s+MIGRATIONS\s+LABELS$`, lines[0])
+		assert.Regexp(t, `^agent-1\s+node1\.example\.com\s+v1\.0\.0\s+ready\s+\d+s\s+1\s+role=compute,zone=a$`, lines[1])
+	})
+
+	t.Run("should report empty result on stderr", func(t *testing.T) {
+		out, errOut, err := runAgentCmd(t, fakecontroller.New(), "table", "list")
+		require.NoError(t, err)
+		assert.Empty(t, out)
+		assert.Equal(t, "No agents found.\n", errOut)
+	})
+
+	t.Run("should sort versions semantically", func(t *testing.T) {
+		fake := fakecontroller.New(fakecontroller.WithAgents(
+			client.Agent{ID: "a", Version: "v1.10.0"},
+			client.Agent{ID: "b", Version: "unknown"},
+			client.Agent{ID: "c", Version: "v1.9.0"},
+			client.Agent{ID: "d", Version: "1.9.1"},
+		))
+		out, _, err := runAgentCmd(t, fake, "jsonpath={range [*]}{.id} {end}", "list", "--sort-by", "version")
+		require.NoError(t, err)
+		assert.Equal(t, "c d a b ", out)
+	})
+
+	t.Run("should reject unknown sort field", func(t *testing.T) {
+		_, _, err := runAgentCmd(t, fakecontroller.New(), "table", "list", "--sort-by", "color")
+		require.Error(t, err)
+		assert.Equal(t, errdefs.KindUsage, errdefs.KindOf(err))
+	})
+}
+
+func TestAgentGet(t *testing.T) {
+	t.Run("should print agent as object", func(t *testing.T) {
+		out, _, err := runAgentCmd(t, fakecontroller.New(fakecontroller.WithDemoData()),
+			"jsonpath={.hostname} {.active_migrations}", "get", "agent-2")
+		require.NoError(t, err)
+		assert.Equal(t, "node2.example.com 1", out)
+	})
+
+	t.Run("should print the same keys as list and describe", func(t *testing.T) {
+		keys := func(data []byte) []string {
+			var object map[string]any
+			require.NoError(t, json.Unmarshal(data, &object))
+			return slices.Sorted(maps.Keys(object))
+		}
+		expected := []string{"active_migrations", "address", "hostname", "id", "labels", "last_heartbeat",
+			"registered_at", "schedulable", "status", "version"}
+
+		out, _, err := runAgentCmd(t, fakecontroller.New(fakecontroller.WithDemoData()), "json", "get", "agent-2")
+		require.NoError(t, err)
+		assert.Equal(t, expected, keys([]byte(out)))
+
+		out, _, err = runAgentCmd(t, fakecontroller.New(fakecontroller.WithDemoData()), "json", "list")
+		require.NoError(t, err)
+		var list []json.RawMessage
+		require.NoError(t, json.Unmarshal([]byte(out), &list))
+		require.NotEmpty(t, list)
+		assert.Equal(t, expected, keys(list[1]))
+
+		out, _, err = runAgentCmd(t, fakecontroller.New(fakecontroller.WithDemoData()), "json", "describe", "agent-2")
+		require.NoError(t, err)
+		assert.Subset(t, keys([]byte(out)), expected)
+	})
+
+	t.Run("should report unknown agent as not found", func(t *testing.T) {
+		_, _, err := runAgentCmd(t, fakecontroller.New(), "table", "get", "missing")
+		require.Error(t, err)
+		assert.Equal(t, errdefs.KindNotFound, errdefs.KindOf(err))
+		assert.Contains(t, err.Error(), "agent not found")
+	})
+}
+
+func TestAgentDescribe(t *testing.T) {
+	t.Run("should print sections", func(t *testing.T) {
+		out, _, err := runAgentCmd(t, fakecontroller.New(fakecontroller.WithDemoData()),
+			"table", "describe", "agent-3", "--heartbeats", "2")
+		require.NoError(t, err)
+
+		lines := splitLines(out)
+		assert.Equal(t, "ID:                  agent-3", lines[0])
+		assert.Contains(t, lines, "Labels:              role=storage")
+		assert.Contains(t, lines, "                     zone=b")
+		assert.Contains(t, lines, "  Memory:         64Gi")
+		assert.Contains(t, lines, "  Source Hypervisors:          vmware")
+		assert.Contains(t, lines, "  Log Level:      debug")
+		assert.Contains(t, lines, "Heartbeats (last 2):")
+		assert.Contains(t, out, "HeartbeatMissed")
+
+		heartbeats := slices.Index(lines, "Heartbeats (last 2):")
+		require.GreaterOrEqual(t, heartbeats, 0)
+		assert.Equal(t, "Events:", lines[heartbeats+4])
+	})
+
+	t.Run("should leave out disabled sections", func(t *testing.T) {
+		out, _, err := runAgentCmd(t, fakecontroller.New(fakecontroller.WithDemoData()),
+			"table", "describe", "agent-1", "--heartbeats", "0", "--events", "0")
+		require.NoError(t, err)
+		assert.NotContains(t, out, "Heartbeats")
+		assert.NotContains(t, out, "Events:")
+	})
+
+	t.Run("should print heartbeats and events as object", func(t *testing.T) {
+		out, _, err := runAgentCmd(t, fakecontroller.New(fakecontroller.WithDemoData()),
+			"jsonpath={.config.install_path} {.host.cpu_cores} {.heartbeats[0].status} {.events[0].reason}",
+			"describe", "agent-3")
+		require.NoError(t, err)
+		assert.Equal(t, "/opt/morpher 8 not_ready HeartbeatMissed", out)
+	})
+
+	t.Run("should print none for an agent without details", func(t *testing.T) {
+		fake := fakecontroller.New(fakecontroller.WithAgents(client.Agent{ID: "bare"}))
+		out, _, err := runAgentCmd(t, fake, "table", "describe", "bare")
+		require.NoError(t, err)
+		assert.Contains(t, splitLines(out), "  OS:             <none>")
+		assert.Contains(t, splitLines(out), "    <none>")
+	})
+
+	t.Run("should reject negative limits", func(t *testing.T) {
+		_, _, err := runAgentCmd(t, fakecontroller.New(), "table", "describe", "agent-1", "--events", "-1")
+		assert.Equal(t, errdefs.KindUsage, errdefs.KindOf(err))
+	})
+
+	t.Run("should report unknown agent as not found", func(t *testing.T) {
+		_, _, err := runAgentCmd(t, fakecontroller.New(), "table", "describe", "missing")
+		assert.Equal(t, errdefs.KindNotFound, errdefs.KindOf(err))
+	})
+}
+
+func TestAgentInstall(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "morpher-agent")
+	require.NoError(t, os.WriteFile(binary, []byte("agent"), 0o755))
+	flags := []string{"install", "--local", "--binary", binary, "--install-path", "/opt/morpher",
+		"--controller-url", "https://controller:9000", "--join-token", "s3cr3t"}
+
+	t.Run("should install below root without starting the service", func(t *testing.T) {
+		root := t.TempDir()
+		out, _, err := runAgentCmd(t, fakecontroller.New(), "name", append(flags, "--root", root)...)
+		require.NoError(t, err)
+		assert.Equal(t, "/opt/morpher/bin/morpher-agent\n/opt/morpher/etc/agent.yaml\n/etc/systemd/system/morpher-agent.service\n", out)
+
+		config, err := os.ReadFile(filepath.Join(root, "opt/morpher/etc/agent.yaml"))
+		require.NoError(t, err)
+		assert.Contains(t, string(config), "join_token: s3cr3t")
+	})
+
+	t.Run("should not create a join token below root", func(t *testing.T) {
+		root := t.TempDir()
+		fake := fakecontroller.New()
+		out, _, err := runAgentCmd(t, fake, "table", "install", "--local", "--binary", binary,
+			"--controller-url", "https://controller:9000", "--root", root)
+		require.NoError(t, err)
+		assert.Contains(t, out, "No join token was created since --root is set: add controller.join_token to /opt/morpher/etc/agent.yaml")
+		assert.Zero(t, fake.RequestCount())
+
+		config, err := os.ReadFile(filepath.Join(root, "opt/morpher/etc/agent.yaml"))
+		require.NoError(t, err)
+		assert.NotContains(t, string(config), "join_token")
+	})
+
+	t.Run("should not create a join token for an invalid binary", func(t *testing.T) {
+		fake := fakecontroller.New()
+		_, _, err := runAgentCmd(t, fake, "table", "install", "--local", "--binary", binary, "--sha256", "abc",
+			"--controller-url", "https://controller:9000")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "checksum mismatch")
+		assert.Zero(t, fake.RequestCount())
+	})
+
+	t.Run("should not create a join token in dry run", func(t *testing.T) {
+		fake := fakecontroller.New()
+		out, _, err := runAgentCmd(t, fake, "table", "install", "--local", "--binary", binary,
+			"--controller-url", "https://controller:9000", "--dry-run")
+		require.NoError(t, err)
+		assert.Contains(t, out, "# A join token is created when installing without --dry-run.")
+		assert.Zero(t, fake.RequestCount())
+	})
+
+	t.Run("should print files without writing them", func(t *testing.T) {
+		root := t.TempDir()
+		out, _, err := runAgentCmd(t, fakecontroller.New(), "table", append(flags, "--root", root, "--dry-run")...)
+		require.NoError(t, err)
+		assert.Contains(t, out, "# "+filepath.ToSlash(root)+"/etc/systemd/system/morpher-agent.service (0644)")
+		assert.NotContains(t, out, "s3cr3t")
+		assert.NotContains(t, out, "systemctl")
+
+		entries, err := os.ReadDir(root)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("should print commands that start the service", func(t *testing.T) {
+		out, _, err := runAgentCmd(t, fakecontroller.New(), "table", append(flags, "--dry-run")...)
+		require.NoError(t, err)
+		assert.Contains(t, out, "$ systemctl enable --now morpher-agent.service")
+	})
+
+	t.Run("should reject checksum mismatch", func(t *testing.T) {
+		_, _, err := runAgentCmd(t, fakecontroller.New(), "table", append(flags, "--dry-run", "--sha256", "0000")...)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "checksum mismatch")
+	})
+
+	t.Run("should reject invalid log level", func(t *testing.T) {
+		_, _, err := runAgentCmd(t, fakecontroller.New(), "table", append(flags, "--dry-run", "--log-level", "loud")...)
+		assert.Equal(t, errdefs.KindUsage, errdefs.KindOf(err))
+	})
+
+	t.Run("should require local", func(t *testing.T) {
+		_, _, err := runAgentCmd(t, fakecontroller.New(), "table", "install", "--binary", binary)
+		assert.Equal(t, errdefs.KindUsage, errdefs.KindOf(err))
+	})
+}
+
+// newSSHServer starts an SSH server running commands with sh and returns it
+// with a key file and known_hosts file to connect with.
+func newSSHServer(t *testing.T, handler sshtest.Handler) (*sshtest.Server, string, string) {
+	t.Helper()
+
+	for _, tool := range []string{"sh", "sha256sum"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s is not available", tool)
+		}
+	}
+
+	signer, key, err := sshtest.NewKey()
+	require.NoError(t, err)
+	server, err := sshtest.NewServer(handler, signer.PublicKey())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = server.Close() })
+
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "id_ed25519")
+	knownHosts := filepath.Join(dir, "known_hosts")
+	require.NoError(t, os.WriteFile(keyFile, key, 0o600))
+	require.NoError(t, os.WriteFile(knownHosts, []byte(server.KnownHosts()), 0o600))
+	return server, keyFile, knownHosts
+}
+
+// stagingHandler runs commands with sh, with the absolute paths of an
+// installation moved below root, and pretends that systemctl succeeds.
+func stagingHandler(root string) sshtest.Handler {
+	return func(command string, stdin io.Reader, stdout, stderr io.Writer) int {
+		if strings.Contains(command, "systemctl") {
+			return 0
+		}
+		command = strings.ReplaceAll(command, " /opt/", " "+root+"/opt/")
+		command = strings.ReplaceAll(command, " /etc/", " "+root+"/etc/")
+		return sshtest.ShellHandler(command, stdin, stdout, stderr)
+	}
+}
+
+func TestAgentInstall_SSH(t *testing.T) {
+	server, keyFile, knownHosts := newSSHServer(t, sshtest.ShellHandler)
+	binary := filepath.Join(t.TempDir(), "morpher-agent")
+	require.NoError(t, os.WriteFile(binary, []byte("agent"), 0o755))
+
+	root := t.TempDir()
+	flags := []string{"install", "--binary", binary, "--install-path", "/opt/morpher",
+		"--controller-url", "https://controller:9000", "--join-token", "s3cr3t", "--ssh-key", keyFile, "--known-hosts", knownHosts, "--root", root}
+
+	t.Run("should report every host", func(t *testing.T) {
+		out, _, err := runAgentCmd(t, fakecontroller.New(), "table",
+			append(flags, "--ssh", "root@"+server.Addr()+",127.0.0.1:1")...)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed on 1 of 2 hosts")
+
+		lines := splitLines(out)
+		require.Len(t, lines, 3)
+		assert.Regexp(t, `^root@127\.0\.0\.1:\d+ +installed +<none>$`, lines[1])
+		assert.Regexp(t, `^root@127\.0\.0\.1:1 +failed +failed to connect`, lines[2])
+
+		_, err = os.Stat(filepath.Join(root, "opt/morpher/bin/morpher-agent"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("should create a join token for the hosts", func(t *testing.T) {
+		root := t.TempDir()
+		staging, keyFile, knownHosts := newSSHServer(t, stagingHandler(root))
+		fake := fakecontroller.New()
+		out, _, err := runAgentCmd(t, fake, "table", "install", "--binary", binary, "--controller-url", "https://controller:9000",
+			"--join-token-ttl", "10m", "--ssh", "root@"+staging.Addr(), "--ssh-key", keyFile, "--known-hosts", knownHosts)
+		require.NoError(t, err)
+
+		match := regexp.MustCompile(`Created join token (\w+) valid for 10m0s and 1 registrations`).FindStringSubmatch(out)
+		require.NotNil(t, match, out)
+		token, ok := fake.JoinToken(match[1])
+		require.True(t, ok)
+		assert.Equal(t, 1, token.MaxUses)
+
+		config, err := os.ReadFile(filepath.Join(root, "opt/morpher/etc/agent.yaml"))
+		require.NoError(t, err)
+		assert.Contains(t, string(config), "join_token: "+match[1]+".")
+	})
+
+	t.Run("should revoke the join token when no host was installed", func(t *testing.T) {
+		fake := fakecontroller.New()
+		out, _, err := runAgentCmd(t, fake, "table", "install", "--binary", binary, "--controller-url", "https://controller:9000",
+			"--ssh", "root@127.0.0.1:1", "--ssh-key", keyFile, "--known-hosts", knownHosts)
+		require.Error(t, err)
+
+		match := regexp.MustCompile(`Revoked join token (\w+) since no agent was installed`).FindStringSubmatch(out)
+		require.NotNil(t, match, out)
+		token, ok := fake.JoinToken(match[1])
+		require.True(t, ok)
+		assert.Equal(t, client.TokenRevoked, token.Status(time.Now()))
+	})
+
+	t.Run("should print hosts in dry run", func(t *testing.T) {
+		out, _, err := runAgentCmd(t, fakecontroller.New(), "table",
+			append(flags, "--ssh", "admin@hv1,hv2", "--dry-run")...)
+		require.NoError(t, err)
+		assert.Contains(t, out, "# Hosts: admin@hv1:22, admin@hv2:22\n")
+	})
+
+	t.Run("should reject local and ssh together", func(t *testing.T) {
+		_, _, err := runAgentCmd(t, fakecontroller.New(), "table", append(flags, "--ssh", "hv1", "--local")...)
+		assert.Equal(t, errdefs.KindUsage, errdefs.KindOf(err))
+	})
+}
+
+func TestAgentUninstall(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "morpher-agent")
+	require.NoError(t, os.WriteFile(binary, []byte("agent"), 0o755))
+
+	installAt := func(t *testing.T) string {
+		t.Helper()
+		root := t.TempDir()
+		_, _, err := runAgentCmd(t, fakecontroller.New(), "name", "install", "--local", "--binary", binary,
+			"--install-path", "/opt/morpher", "--controller-url", "https://controller:9000", "--root", root)
+		require.NoError(t, err)
+		return root
+	}
+	flags := []string{"uninstall", "--local", "--install-path", "/opt/morpher"}
+
+	t.Run("should deregister the agent and remove its files", func(t *testing.T) {
+		root := installAt(t)
+		fake := fakecontroller.New(fakecontroller.WithDemoData())
+		out, _, err := runAgentCmd(t, fake, "json", append(flags, "--root", root, "--agent", "agent-3")...)
+		require.NoError(t, err)
+
+		var reports []map[string]any
+		require.NoError(t, json.Unmarshal([]byte(out), &reports))
+		require.Len(t, reports, 1)
+		assert.Equal(t, "agent-3", reports[0]["agent"])
+		assert.Equal(t, true, reports[0]["deregistered"])
+		assert.Contains(t, reports[0]["removed"], "/opt/morpher/bin/morpher-agent")
+		assert.Contains(t, reports[0]["removed"], "/etc/systemd/system/morpher-agent.service")
+		assert.Empty(t, reports[0]["left_behind"])
+
+		_, err = os.Stat(filepath.Join(root, "opt/morpher"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+
+		_, _, err = runAgentCmd(t, fake, "table", "get", "agent-3")
+		assert.Equal(t, errdefs.KindNotFound, errdefs.KindOf(err))
+	})
+
+	t.Run("should refuse an agent with active migrations", func(t *testing.T) {
+		root := installAt(t)
+		_, _, err := runAgentCmd(t, fakecontroller.New(fakecontroller.WithDemoData()), "table",
+			append(flags, "--root", root, "--agent", "agent-1")...)
+		assert.Equal(t, errdefs.KindConflict, errdefs.KindOf(err))
+
+		_, err = os.Stat(filepath.Join(root, "opt/morpher/bin/morpher-agent"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("should uninstall an agent with active migrations with force", func(t *testing.T) {
+		root := installAt(t)
+		out, _, err := runAgentCmd(t, fakecontroller.New(fakecontroller.WithDemoData()), "table",
+			append(flags, "--root", root, "--agent", "agent-1", "--force")...)
+		require.NoError(t, err)
+		assert.Contains(t, out, "agent-1 (deregistered)")
+	})
+
+	t.Run("should remove files of an unregistered agent", func(t *testing.T) {
+		root := installAt(t)
+		out, _, err := runAgentCmd(t, fakecontroller.New(), "table", append(flags, "--root", root)...)
+		require.NoError(t, err)
+		assert.Regexp(t, `Agent: +<none>`, out)
+		assert.Regexp(t, `Left Behind: +<none>`, out)
+	})
+
+	t.Run("should reject an unknown agent", func(t *testing.T) {
+		_, _, err := runAgentCmd(t, fakecontroller.New(), "table", append(flags, "--root", t.TempDir(), "--agent", "agent-9")...)
+		assert.Equal(t, errdefs.KindNotFound, errdefs.KindOf(err))
+	})
+
+	t.Run("should reject agent with several hosts", func(t *testing.T) {
+		_, _, err := runAgentCmd(t, fakecontroller.New(), "table", "uninstall", "--ssh", "hv1,hv2", "--agent", "agent-1")
+		assert.Equal(t, errdefs.KindUsage, errdefs.KindOf(err))
+	})
+}
+
+func TestAgentUninstall_SSH(t *testing.T) {
+	server, keyFile, knownHosts := newSSHServer(t, sshtest.ShellHandler)
+
+	t.Run("should report every occurrence of a host given twice", func(t *testing.T) {
+		host := "root@" + server.Addr()
+		out, _, err := runAgentCmd(t, fakecontroller.New(), "json", "uninstall", "--ssh", host+","+host,
+			"--ssh-key", keyFile, "--known-hosts", knownHosts, "--root", t.TempDir())
+		require.NoError(t, err)
+
+		var reports []map[string]any
+		require.NoError(t, json.Unmarshal([]byte(out), &reports))
+		require.Len(t, reports, 2)
+		assert.Equal(t, host, reports[0]["host"])
+		assert.Equal(t, host, reports[1]["host"])
+		assert.NotContains(t, reports[0], "error")
+		assert.NotContains(t, reports[1], "error")
+	})
+}
+
+// stopFailingTarget is a local target on which the service cannot be
+// stopped.
+type stopFailingTarget struct {
+	install.LocalTarget
+}
+
+func (t stopFailingTarget) Run(_ context.Context, command []string) error {
+	if slices.Contains(command, "disable") {
+		return errors.New("systemctl disable failed: unit is masked")
+	}
+	return nil
+}
+
+func TestUninstaller_StopFails(t *testing.T) {
+	server := httptest.NewServer(fakecontroller.New())
+	t.Cleanup(server.Close)
+	u := uninstaller{
+		client: client.NewClient(server.URL, time.Second, ""),
+		opts:   uninstallOptions{installPath: "/opt/morpher"},
+	}
+	target := stopFailingTarget{install.LocalTarget{Root: t.TempDir()}}
+
+	t.Run("should fail without force", func(t *testing.T) {
+		report, err := u.uninstall(context.Background(), "hv1", target)
+		require.ErrorContains(t, err, "failed to stop the agent")
+		assert.False(t, report.ServiceStopped)
+	})
+
+	t.Run("should warn with force", func(t *testing.T) {
+		u.opts.force = true
+		report, err := u.uninstall(context.Background(), "hv1", target)
+		require.NoError(t, err)
+		assert.False(t, report.ServiceStopped)
+		assert.Equal(t, []string{"failed to stop the agent: systemctl disable failed: unit is masked"}, report.Warnings)
+
+		rows := uninstallReports{report}.TableRows(false)
+		assert.Contains(t, rows, []string{"Service:", "not stopped"})
+		assert.Contains(t, rows, []string{"Warnings:", report.Warnings[0]})
+	})
+}
+
+func TestAgentCordon(t *testing.T) {
+	t.Run("should cordon and uncordon agents", func(t *testing.T) {
+		fake := fakecontroller.New(fakecontroller.WithDemoData())
+		out, _, err := runAgentCmd(t, fake, "table", "cordon", "agent-1", "agent-2")
+		require.NoError(t, err)
+		lines := splitLines(out)
+		require.Len(t, lines, 3)
+		assert.Regexp(t, `^ID\s+HOSTNAME\s`, lines[0])
+		assert.Regexp(t, `^agent-1\s+\S+\s+v1\.0\.0\s+ready,cordoned\s`, lines[1])
+		assert.Regexp(t, `^agent-2\s+\S+\s+v1\.0\.0\s+ready,cordoned\s`, lines[2])
+		agent, _ := fake.Agent("agent-2")
+		assert.False(t, agent.Schedulable)
+
+		out, _, err = runAgentCmd(t, fake, "table", "list")
+		require.NoError(t, err)
+		lines = splitLines(out)
+		require.Len(t, lines, 4)
+		assert.Regexp(t, `^agent-1\s+\S+\s+v1\.0\.0\s+ready,cordoned\s`, lines[1])
+		assert.Regexp(t, `^agent-3\s+\S+\s+v0\.9\.2\s+not_ready,cordoned\s`, lines[3])
+
+		out, _, err = runAgentCmd(t, fake, "name", "uncordon", "agent-2")
+		require.NoError(t, err)
+		assert.Equal(t, "agent-2\n", out)
+		agent, _ = fake.Agent("agent-2")
+		assert.True(t, agent.Schedulable)
+	})
+
+	t.Run("should print agents cordoned before a failure", func(t *testing.T) {
+		out, _, err := runAgentCmd(t, fakecontroller.New(fakecontroller.WithDemoData()), "name", "cordon", "agent-1", "agent-9", "agent-2")
+		assert.Equal(t, errdefs.KindNotFound, errdefs.KindOf(err))
+		assert.Equal(t, "agent-1\n", out)
+	})
+
+	t.Run("should drain an idle agent", func(t *testing.T) {
+		out, errOut, err := runAgentCmd(t, fakecontroller.New(fakecontroller.WithDemoData()), "table", "drain", "agent-3")
+		require.NoError(t, err)
+		assert.Empty(t, errOut)
+		assert.Equal(t, "Draining agent agent-3\nAgent agent-3 drained\n", out)
+	})
+
+	t.Run("should keep structured drain output clean", func(t *testing.T) {
+		out, errOut, err := runAgentCmd(t, fakecontroller.New(fakecontroller.WithDemoData()), "json", "drain", "agent-3")
+		require.NoError(t, err)
+		assert.Equal(t, "Draining agent agent-3\nAgent agent-3 drained\n", errOut)
+		assert.JSONEq(t, "[]", out)
+	})
+
+	t.Run("should time out while migrations are active", func(t *testing.T) {
+		fake := fakecontroller.New(fakecontroller.WithDemoData())
+		_, _, err := runAgentCmd(t, fake, "table", "drain", "agent-1", "--timeout", "20ms", "--poll", "5ms")
+		assert.Equal(t, errdefs.KindTimeout, errdefs.KindOf(err))
+		assert.ErrorContains(t, err, "still has 1 active migrations")
+		assert.ErrorContains(t, err, "use --force")
+
+		agent, _ := fake.Agent("agent-1")
+		assert.False(t, agent.Schedulable)
+	})
+
+	t.Run("should print handed off migrations with snake_case keys", func(t *testing.T) {
+		fake := fakecontroller.New(fakecontroller.WithDemoData())
+		out, _, err := runAgentCmd(t, fake, "jsonpath={range [*]}{.id} {.vm_name} {.source_agent} {.phase}{end}",
+			"drain", "agent-1", "--timeout", "0", "--poll", "5ms", "--force")
+		require.NoError(t, err)
+		assert.Equal(t, "mig-2 db-01 agent-2 cancelled", out)
+	})
+
+	t.Run("should tell a running drain from a cordon", func(t *testing.T) {
+		fake := fakecontroller.New(fakecontroller.WithAgents(
+			client.Agent{ID: "agent-1", Status: "ready", Annotations: map[string]string{client.DrainingAnnotation: "2026-10-18T08:00:00Z"}},
+			client.Agent{ID: "agent-2", Status: "ready"},
+		))
+		_, _, err := runAgentCmd(t, fake, "table", "cordon", "agent-2")
+		require.NoError(t, err)
+
+		out, _, err := runAgentCmd(t, fake, "table", "list")
+		require.NoError(t, err)
+		lines := splitLines(out)
+		require.Len(t, lines, 3)
+		assert.Regexp(t, `^agent-1\s.*\sready,draining\s`, lines[1])
+		assert.Regexp(t, `^agent-2\s.*\sready,cordoned\s`, lines[2])
+	})
+
+	t.Run("should end draining when the drain stops", func(t *testing.T) {
+		fake := fakecontroller.New(fakecontroller.WithDemoData())
+		_, _, err := runAgentCmd(t, fake, "table", "drain", "agent-1", "--timeout", "20ms", "--poll", "5ms")
+		require.Error(t, err)
+
+		agent, _ := fake.Agent("agent-1")
+		assert.NotContains(t, agent.Annotations, client.DrainingAnnotation)
+		out, _, err := runAgentCmd(t, fake, "table", "get", "agent-1")
+		require.NoError(t, err)
+		assert.Regexp(t, `\sready,cordoned\s`, splitLines(out)[1])
+	})
+
+	t.Run("should hand off migrations with force", func(t *testing.T) {
+		fake := fakecontroller.New(fakecontroller.WithDemoData())
+		out, _, err := runAgentCmd(t, fake, "table", "drain", "agent-1", "--timeout", "20ms", "--poll", "5ms", "--force")
+		require.NoError(t, err)
+		lines := splitLines(out)
+		require.Len(t, lines, 4)
+		assert.Equal(t, "Draining agent agent-1", lines[0])
+		assert.Regexp(t, `^ID +VM +SOURCE +TARGET +PHASE +MESSAGE$`, lines[1])
+		assert.Regexp(t, `^mig-2 +db-01 +agent-2 +agent-1 +cancelled +cancelled while draining agent-1: no agent can take over$`, lines[2])
+		assert.Equal(t, "Agent agent-1 drained", lines[3])
+	})
+}
+
+func TestAgentLabel(t *testing.T) {
+	t.Run("should add and remove labels", func(t *testing.T) {
+		fake := fakecontroller.New(fakecontroller.WithDemoData())
+		out, _, err := runAgentCmd(t, fake, "table", "label", "agent-1", "site=dc1", "tier=gold", "role-")
+		require.NoError(t, err)
+		lines := splitLines(out)
+		require.Len(t, lines, 2)
+		assert.Regexp(t, `^agent-1\s.*\ssite=dc1,tier=gold,zone=a$`, lines[1])
+
+		agent, _ := fake.Agent("agent-1")
+		assert.Equal(t, map[string]string{"site": "dc1", "tier": "gold", "zone": "a"}, agent.Labels)
+
+		out, _, err = runAgentCmd(t, fake, "jsonpath={range [*]}{.id} {end}", "list", "--selector", "tier in (gold,silver),!role")
+		require.NoError(t, err)
+		assert.Equal(t, "agent-1 ", out)
+	})
+
+	t.Run("should require overwrite to change values", func(t *testing.T) {
+		fake := fakecontroller.New(fakecontroller.WithDemoData())
+		_, _, err := runAgentCmd(t, fake, "table", "label", "agent-1", "zone=b")
+		assert.Equal(t, errdefs.KindConflict, errdefs.KindOf(err))
+		assert.ErrorContains(t, err, "use --overwrite")
+
+		_, _, err = runAgentCmd(t, fake, "table", "label", "agent-1", "zone=b", "--overwrite")
+		require.NoError(t, err)
+		agent, _ := fake.Agent("agent-1")
+		assert.Equal(t, "b", agent.Labels["zone"])
+	})
+
+	t.Run("should annotate with free-form values", func(t *testing.T) {
+		fake := fakecontroller.New(fakecontroller.WithDemoData())
+		out, _, err := runAgentCmd(t, fake, "jsonpath={.annotations.owner}", "annotate", "agent-2", "owner=storage team")
+		require.NoError(t, err)
+		assert.Equal(t, "storage team", out)
+
+		agent, _ := fake.Agent("agent-2")
+		assert.Equal(t, "storage team", agent.Annotations["owner"])
+	})
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+	}{
+		{
+			name:          "should reject arguments without value or removal",
+			args:          []string{"label", "agent-1", "site"},
+			expectedError: `invalid label "site": expected key=value or key-`,
+		},
+		{
+			name:          "should reject invalid label values",
+			args:          []string{"label", "agent-1", "owner=storage team"},
+			expectedError: `invalid value "storage team"`,
+		},
+		{
+			name:          "should reject keys given twice",
+			args:          []string{"annotate", "agent-1", "owner=a", "owner-"},
+			expectedError: `annotation "owner" is given more than once`,
+		},
+		{
+			name:          "should reject invalid selectors",
+			args:          []string{"list", "--selector", "site in (dc1"},
+			expectedError: `invalid selector "site in (dc1": missing ")" after the values of "in"`,
+		},
+		{
+			name:          "should reject invalid upgrade selectors",
+			args:          []string{"upgrade", "--to", "v1.0.1", "--selector", "site notin"},
+			expectedError: `invalid selector "site notin": expected "(" after "notin" at the end`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := runAgentCmd(t, fakecontroller.New(fakecontroller.WithDemoData()), "table", tt.args...)
+			assert.Equal(t, errdefs.KindUsage, errdefs.KindOf(err))
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestAgentLogs(t *testing.T) {
+	t.Run("should print logs since a duration", func(t *testing.T) {
+		out, _, err := runAgentCmd(t, fakecontroller.New(fakecontroller.WithDemoData()), "table", "logs", "agent-1", "--since", "10m")
+		require.NoError(t, err)
+
+		lines := splitLines(out)
+		require.Len(t, lines, 2)
+		assert.Regexp(t, `^\S+ INFO  migration started migration=mig-2 vm=db-01$`, lines[0])
+		assert.Regexp(t, `^\S+ WARN  disk copy throttled migration=mig-2$`, lines[1])
+	})
+
+	t.Run("should filter and color entries", func(t *testing.T) {
+		out, _, err := runAgentCmd(t, fakecontroller.New(fakecontroller.WithDemoData()), "table",
+			"logs", "agent-3", "--grep", "connection|heartbeat", "--color", "always")
+		require.NoError(t, err)
+
+		lines := splitLines(out)
+		require.Len(t, lines, 2)
+		assert.Contains(t, lines[0], "\x1b[90mDEBUG\x1b[0m heartbeat sent")
+		assert.Contains(t, lines[1], "\x1b[31mERROR\x1b[0m lost connection")
+	})
+
+	t.Run("should print entries as objects", func(t *testing.T) {
+		out, _, err := runAgentCmd(t, fakecontroller.New(fakecontroller.WithDemoData()), "name", "logs", "agent-2")
+		require.NoError(t, err)
+		assert.Equal(t, "agent started\nmigration started\n", out)
+
+		out, _, err = runAgentCmd(t, fakecontroller.New(fakecontroller.WithDemoData()), "jsonpath={.level} {.message}{\"\\n\"}", "logs", "agent-2")
+		require.NoError(t, err)
+		assert.Equal(t, "info agent started\ninfo migration started\n", out)
+	})
+
+	t.Run("should reject invalid flags", func(t *testing.T) {
+		for _, args := range [][]string{{"--grep", "("}, {"--color", "rainbow"}, {"--since", "-1m"}} {
+			_, _, err := runAgentCmd(t, fakecontroller.New(), "table", append([]string{"logs", "agent-1"}, args...)...)
+			assert.Equal(t, errdefs.KindUsage, errdefs.KindOf(err), args)
+		}
+	})
+}
+
+func TestAgentLogLevel(t *testing.T) {
+	t.Run("should change the level temporarily", func(t *testing.T) {
+		fake := fakecontroller.New(fakecontroller.WithDemoData())
+		out, _, err := runAgentCmd(t, fake, "table", "log-level", "agent-1", "debug", "--for", "15m")
+		require.NoError(t, err)
+
+		lines := splitLines(out)
+		require.Len(t, lines, 4)
+		assert.Regexp(t, `^Level:\s+debug$`, lines[1])
+		assert.Regexp(t, `^Default:\s+info$`, lines[2])
+		assert.Regexp(t, `^Reverts:\s+\S+ \(in 1[45]m\d*s?\)$`, lines[3])
+
+		out, _, err = runAgentCmd(t, fake, "name", "log-level", "agent-1")
+		require.NoError(t, err)
+		assert.Equal(t, "debug\n", out)
+
+		out, _, err = runAgentCmd(t, fake, "jsonpath={.agent} {.level} {.revert_at}", "log-level", "agent-1")
+		require.NoError(t, err)
+		assert.Regexp(t, `^agent-1 debug \S+$`, out)
+	})
+
+	t.Run("should change the default level", func(t *testing.T) {
+		fake := fakecontroller.New(fakecontroller.WithDemoData())
+		out, _, err := runAgentCmd(t, fake, "table", "log-level", "agent-3", "info")
+		require.NoError(t, err)
+		assert.Contains(t, out, "Reverts:   never")
+
+		out, _, err = runAgentCmd(t, fake, "jsonpath={.default}", "log-level", "agent-3")
+		require.NoError(t, err)
+		assert.Equal(t, "info", out)
+	})
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "should reject unknown levels", args: []string{"log-level", "agent-1", "trace"}},
+		{name: "should reject a duration without level", args: []string{"log-level", "agent-1", "--for", "5m"}},
+		{name: "should reject negative durations", args: []string{"log-level", "agent-1", "debug", "--for", "-5m"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := runAgentCmd(t, fakecontroller.New(fakecontroller.WithDemoData()), "table", tt.args...)
+			assert.Equal(t, errdefs.KindUsage, errdefs.KindOf(err))
+		})
+	}
+}
+
+func TestAgentUpgrade(t *testing.T) {
+	timing := []string{"--poll", "5ms", "--timeout", "500ms", "--drain-timeout", "500ms"}
+
+	t.Run("should upgrade the selected agents", func(t *testing.T) {
+		statusFile := filepath.Join(t.TempDir(), "upgrade.json")
+		fake := fakecontroller.New(fakecontroller.WithDemoData())
+		out, _, err := runAgentCmd(t, fake, "table", append([]string{"upgrade", "--to", "v1.0.1",
+			"--selector", "role=storage", "--status-file", statusFile}, timing...)...)
+		require.NoError(t, err)
+
+		lines := splitLines(out)
+		require.Len(t, lines, 6)
+		assert.Equal(t, "Upgrading 1 of 1 agents to v1.0.1, 1 at a time", lines[0])
+		assert.Regexp(t, ` agent-3 +draining agent-3$`, lines[1])
+		assert.Regexp(t, ` agent-3 +upgrading agent-3 from v0.9.2 to v1.0.1$`, lines[2])
+		assert.Regexp(t, ` agent-3 +upgraded agent-3 to v1.0.1$`, lines[4])
+		assert.Equal(t, "Upgraded 1 agents to v1.0.1, 0 already up to date", lines[5])
+
+		agent, _ := fake.Agent("agent-3")
+		assert.Equal(t, "v1.0.1", agent.Version)
+		assert.True(t, agent.Schedulable)
+
+		status, err := upgrade.LoadStatus(statusFile)
+		require.NoError(t, err)
+		assert.Equal(t, upgrade.RolloutCompleted, status.Phase)
+	})
+
+	t.Run("should refuse versions outside the supported skew", func(t *testing.T) {
+		statusFile := filepath.Join(t.TempDir(), "upgrade.json")
+		_, _, err := runAgentCmd(t, fakecontroller.New(fakecontroller.WithDemoData()), "table",
+			"upgrade", "--to", "v1.4.0", "--status-file", statusFile)
+		assert.Equal(t, errdefs.KindUsage, errdefs.KindOf(err))
+		assert.ErrorContains(t, err, "supports agents v0.9 - v1.0, not v1.4.0")
+		assert.NoFileExists(t, statusFile)
+	})
+
+	t.Run("should resume an aborted rollout", func(t *testing.T) {
+		statusFile := filepath.Join(t.TempDir(), "upgrade.json")
+		agents := []client.Agent{
+			{ID: "agent-a", Status: client.AgentReady, Version: "v0.9.0", Schedulable: true},
+			{ID: "agent-b", Status: client.AgentReady, Version: "v0.9.0", Schedulable: true},
+		}
+		args := append([]string{"upgrade", "--status-file", statusFile}, timing...)
+
+		failing := fakecontroller.New(fakecontroller.WithAgents(agents...), fakecontroller.WithFailingUpgrades("agent-a"))
+		_, errOut, err := runAgentCmd(t, failing, "table", append(args, "--to", "v1.0.0")...)
+		assert.ErrorIs(t, err, upgrade.ErrAborted)
+		assert.Contains(t, errOut, "--resume --status-file "+statusFile)
+
+		_, _, err = runAgentCmd(t, failing, "table", append(args, "--to", "v1.0.0")...)
+		assert.Equal(t, errdefs.KindConflict, errdefs.KindOf(err))
+
+		fixed := fakecontroller.New(fakecontroller.WithAgents(agents...))
+		out, _, err := runAgentCmd(t, fixed, "json", append(args, "--resume")...)
+		require.NoError(t, err)
+		assert.Contains(t, out, `"agent": "agent-b"`)
+		for _, agent := range agents {
+			upgraded, _ := fixed.Agent(agent.ID)
+			assert.Equal(t, "v1.0.0", upgraded.Version)
+		}
+	})
+
+	t.Run("should resume a rollout with failed agents", func(t *testing.T) {
+		statusFile := filepath.Join(t.TempDir(), "upgrade.json")
+		agents := []client.Agent{
+			{ID: "agent-a", Status: client.AgentReady, Version: "v0.9.0", Schedulable: true},
+			{ID: "agent-b", Status: client.AgentReady, Version: "v0.9.0", Schedulable: true},
+		}
+		args := append([]string{"upgrade", "--status-file", statusFile, "--max-failures", "0"}, timing...)
+
+		failing := fakecontroller.New(fakecontroller.WithAgents(agents...), fakecontroller.WithFailingUpgrades("agent-a"))
+		_, errOut, err := runAgentCmd(t, failing, "table", append(args, "--to", "v1.0.0")...)
+		assert.EqualError(t, err, "1 agents failed to upgrade")
+		assert.Contains(t, errOut, "--resume --status-file "+statusFile)
+
+		status, err := upgrade.LoadStatus(statusFile)
+		require.NoError(t, err)
+		assert.Equal(t, upgrade.RolloutFailed, status.Phase)
+
+		fixed := fakecontroller.New(fakecontroller.WithAgents(agents...))
+		_, _, err = runAgentCmd(t, fixed, "table", append(args, "--resume")...)
+		require.NoError(t, err)
+		upgraded, _ := fixed.Agent("agent-a")
+		assert.Equal(t, "v1.0.0", upgraded.Version)
+		assert.True(t, upgraded.Schedulable)
+	})
+
+	t.Run("should reject resume with a version", func(t *testing.T) {
+		_, _, err := runAgentCmd(t, fakecontroller.New(), "table", "upgrade", "--resume", "--to", "v1.0.0")
+		assert.Equal(t, errdefs.KindUsage, errdefs.KindOf(err))
+	})
+}
+
+func TestAgentToken(t *testing.T) {
+	fake := fakecontroller.New()
+
+	out, _, err := runAgentCmd(t, fake, "name", "token", "create", "--ttl", "1h", "--labels", "site=dc1", "--max-uses", "5")
+	require.NoError(t, err)
+	secret := strings.TrimSpace(out)
+	id, _, ok := strings.Cut(secret, ".")
+	require.True(t, ok, secret)
+
+	out, _, err = runAgentCmd(t, fake, "table", "token", "list")
+	require.NoError(t, err)
+	lines := splitLines(out)
+	require.Len(t, lines, 2)
+	assert.Regexp(t, `^ID +STATUS +USES +EXPIRES +LABELS$`, lines[0])
+	assert.Regexp(t, `^`+id+` +active +0/5 +59m +site=dc1$`, lines[1])
+	assert.NotContains(t, out, secret)
+
+	out, _, err = runAgentCmd(t, fake, "table", "token", "revoke", secret)
+	require.NoError(t, err)
+	lines = splitLines(out)
+	require.Len(t, lines, 2)
+	assert.Regexp(t, `^`+id+` +revoked$`, lines[1])
+
+	out, _, err = runAgentCmd(t, fake, "table", "token", "list")
+	require.NoError(t, err)
+	assert.Contains(t, out, " revoked ")
+
+	t.Run("should reject invalid flags", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"token", "create", "--ttl", "0s"},
+			{"token", "create", "--max-uses", "-1"},
+			{"token", "create", "--labels", "=dc1"},
+			{"token", "create", "--labels", "site=dc 1"},
+			{"token", "create", "--labels", "-site=dc1"},
+		} {
+			_, _, err := runAgentCmd(t, fake, "table", args...)
+			assert.Equal(t, errdefs.KindUsage, errdefs.KindOf(err), args)
+		}
+	})
+
+	t.Run("should fail for unknown token", func(t *testing.T) {
+		_, _, err := runAgentCmd(t, fake, "table", "token", "revoke", "nope00")
+		assert.Equal(t, errdefs.KindNotFound, errdefs.KindOf(err))
+	})
+
+	t.Run("should print revoked tokens as objects", func(t *testing.T) {
+		out, _, err := runAgentCmd(t, fake, "json", "token", "revoke", id)
+		require.NoError(t, err)
+		assert.JSONEq(t, `[{"id": "`+id+`", "status": "revoked"}]`, out)
+	})
+
+	t.Run("should print tokens with snake_case keys", func(t *testing.T) {
+		out, _, err := runAgentCmd(t, fake, "json", "token", "create", "--max-uses", "2")
+		require.NoError(t, err)
+		var created map[string]any
+		require.NoError(t, json.Unmarshal([]byte(out), &created))
+		assert.Subset(t, slices.Collect(maps.Keys(created)), []string{"id", "token", "status", "uses", "max_uses", "created_at", "expires_at"})
+		assert.Equal(t, "active", created["status"])
+
+		out, _, err = runAgentCmd(t, fake, "jsonpath={range [*]}{.id}={.status} {end}", "token", "list")
+		require.NoError(t, err)
+		assert.Contains(t, out, id+"=revoked ")
+		assert.Contains(t, out, created["id"].(string)+"=active ")
+	})
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		bytes    uint64
+		expected string
+	}{
+		{name: "should print none for zero", bytes: 0, expected: "<none>"},
+		{name: "should print bytes", bytes: 512, expected: "512"},
+		{name: "should print whole units", bytes: 64 << 30, expected: "64Gi"},
+		{name: "should round fractions", bytes: 1536 << 20, expected: "1.5Gi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatBytes(tt.bytes))
+		})
+	}
+}
+
+func splitLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	return lines
+}
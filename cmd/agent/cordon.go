@@ -0,0 +1,168 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+	"github.com/morpher-vm/morpherctl/internal/printer"
+	"github.com/morpher-vm/morpherctl/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+func newCordonCmd(f *cmdutil.Factory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "cordon <id>...",
+		Short: "Stop scheduling migrations on agents",
+		Long: `Mark agents unschedulable so that no new migrations are scheduled from or to
+them. Migrations that are already active continue; use agent drain to wait
+for them.`,
+		Example: `  morpherctl agent cordon agent-1
+  morpherctl agent cordon agent-1 agent-2`,
+		Args:              cmdutil.UsageArgs(cobra.MinimumNArgs(1)),
+		ValidArgsFunction: completeAgentIDs(f),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setSchedulable(cmd.Context(), f, args, false)
+		},
+	}
+}
+
+func newUncordonCmd(f *cmdutil.Factory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "uncordon <id>...",
+		Short: "Resume scheduling migrations on agents",
+		Long:  `Mark cordoned or drained agents schedulable again.`,
+		Example: `  morpherctl agent uncordon agent-1
+  morpherctl agent uncordon agent-1 agent-2`,
+		Args:              cmdutil.UsageArgs(cobra.MinimumNArgs(1)),
+		ValidArgsFunction: completeAgentIDs(f),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setSchedulable(cmd.Context(), f, args, true)
+		},
+	}
+}
+
+func setSchedulable(ctx context.Context, f *cmdutil.Factory, ids []string, schedulable bool) error {
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
+
+	// Create controller client.
+	c, err := f.ControllerClient()
+	if err != nil {
+		return fmt.Errorf("failed to create controller client: %w", err)
+	}
+
+	// Agents changed before a failure are still printed.
+	var agents agentList
+	for _, id := range ids {
+		var agent *client.Agent
+		agent, err = setAgentSchedulable(ctx, c, id, schedulable)
+		if err != nil {
+			break
+		}
+		agents = append(agents, newAgentOutput(*agent))
+	}
+	if len(agents) > 0 {
+		if printErr := p.Print(f.IOStreams.Out, agents); printErr != nil {
+			return printErr
+		}
+	}
+	return err
+}
+
+// setAgentSchedulable cordons or uncordons an agent and returns it.
+func setAgentSchedulable(ctx context.Context, c *client.Client, id string, schedulable bool) (*client.Agent, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.GetTimeout())
+	defer cancel()
+
+	var err error
+	if schedulable {
+		err = c.UncordonAgent(ctx, id)
+	} else {
+		err = c.CordonAgent(ctx, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.GetAgent(ctx, id)
+}
+
+// drainOptions holds the flags of the drain command.
+type drainOptions struct {
+	timeout time.Duration
+	force   bool
+	poll    time.Duration
+}
+
+func newDrainCmd(f *cmdutil.Factory) *cobra.Command {
+	var opts drainOptions
+
+	cmd := &cobra.Command{
+		Use:   "drain <id>",
+		Short: "Cordon an agent and wait for its migrations",
+		Long: `Cordon an agent and wait until its active migrations have finished, for
+example before maintenance of its host.
+
+The command fails if migrations are still active after --timeout. With
+--force they are handed off to other agents instead; migrations no other
+agent can take over are cancelled, and the migrations that were changed are
+printed. The agent stays cordoned until agent uncordon.`,
+		Example: `  morpherctl agent drain agent-1
+  morpherctl agent drain agent-1 --timeout 30m --force`,
+		Args:              cmdutil.UsageArgs(cobra.ExactArgs(1)),
+		ValidArgsFunction: completeAgentIDs(f),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return drainAgent(cmd.Context(), f, args[0], opts)
+		},
+	}
+
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 10*time.Minute, "maximum time to wait for migrations to finish, 0 for no limit")
+	cmd.Flags().BoolVar(&opts.force, "force", false, "hand off migrations still active after the timeout to other agents")
+	cmd.Flags().DurationVar(&opts.poll, "poll", 2*time.Second, "interval between checks for active migrations")
+
+	return cmd
+}
+
+func drainAgent(ctx context.Context, f *cmdutil.Factory, id string, opts drainOptions) error {
+	if opts.timeout < 0 || opts.poll <= 0 {
+		return errdefs.Usage(errors.New("--timeout must not be negative and --poll must be positive"))
+	}
+
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
+
+	// Create controller client.
+	c, err := f.ControllerClient()
+	if err != nil {
+		return fmt.Errorf("failed to create controller client: %w", err)
+	}
+
+	fmt.Fprintf(f.InfoOut(), "Draining agent %s\n", id)
+	handedOff, err := c.DrainAgent(ctx, id, client.DrainOptions{
+		Poll:    opts.poll,
+		Timeout: opts.timeout,
+		Force:   opts.force,
+	})
+	if len(handedOff) > 0 || (err == nil && printer.IsStructured(f.OutputFormat)) {
+		if printErr := p.Print(f.IOStreams.Out, newMigrationList(handedOff)); printErr != nil {
+			return printErr
+		}
+	}
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return errdefs.New(errdefs.KindTimeout, fmt.Errorf("%w: use --force to hand them off", err))
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(f.InfoOut(), "Agent %s drained\n", id)
+	return nil
+}
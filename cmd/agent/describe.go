@@ -0,0 +1,413 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+	"github.com/morpher-vm/morpherctl/internal/printer"
+	"github.com/morpher-vm/morpherctl/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+// describeOptions holds the flags of the describe command.
+type describeOptions struct {
+	heartbeats int
+	events     int
+}
+
+func newDescribeCmd(f *cmdutil.Factory) *cobra.Command {
+	var opts describeOptions
+
+	cmd := &cobra.Command{
+		Use:   "describe <id>",
+		Short: "Show details of an agent",
+		Long: `Show the details of an agent: its host facts, the hypervisors it can
+migrate from and to, the configuration it runs with, its most recent
+heartbeats and recent events.
+
+Set --heartbeats or --events to 0 to leave out the section.`,
+		Example: `  morpherctl agent describe agent-3
+  morpherctl agent describe agent-3 --heartbeats 30 --events 50
+  morpherctl agent describe agent-3 -o json`,
+		Args:              cmdutil.UsageArgs(cobra.ExactArgs(1)),
+		ValidArgsFunction: completeAgentIDs(f),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return describeAgent(cmd.Context(), f, args[0], opts)
+		},
+	}
+
+	cmd.Flags().IntVar(&opts.heartbeats, "heartbeats", 10, "number of recent heartbeats to show")
+	cmd.Flags().IntVar(&opts.events, "events", 20, "number of recent events to show")
+
+	return cmd
+}
+
+func describeAgent(ctx context.Context, f *cmdutil.Factory, id string, opts describeOptions) error {
+	if opts.heartbeats < 0 || opts.events < 0 {
+		return errdefs.Usage(fmt.Errorf("--heartbeats and --events must not be negative"))
+	}
+
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
+
+	// Create controller client.
+	c, err := f.ControllerClient()
+	if err != nil {
+		return fmt.Errorf("failed to create controller client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.GetTimeout())
+	defer cancel()
+
+	details, err := c.GetAgentDetails(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	var heartbeats []client.Heartbeat
+	if opts.heartbeats > 0 {
+		if heartbeats, err = c.ListAgentHeartbeats(ctx, id, opts.heartbeats); err != nil {
+			return err
+		}
+	}
+	var events []client.Event
+	if opts.events > 0 {
+		if events, err = c.ListAgentEvents(ctx, id, opts.events); err != nil {
+			return err
+		}
+	}
+
+	output := newAgentDescription(details, heartbeats, events)
+	output.heartbeatLimit = opts.heartbeats
+	output.eventLimit = opts.events
+	return p.Print(f.IOStreams.Out, output)
+}
+
+// osOutput is the printable form of the operating system of a host.
+type osOutput struct {
+	Name            string `json:"name"`
+	PlatformName    string `json:"platform_name"`
+	PlatformVersion string `json:"platform_version"`
+	KernelVersion   string `json:"kernel_version"`
+}
+
+// diskOutput is the printable form of a disk.
+type diskOutput struct {
+	Name       string `json:"name"`
+	Model      string `json:"model,omitempty"`
+	SizeBytes  uint64 `json:"size_bytes"`
+	MountPoint string `json:"mount_point,omitempty"`
+}
+
+// nicOutput is the printable form of a network interface.
+type nicOutput struct {
+	Name      string   `json:"name"`
+	MAC       string   `json:"mac"`
+	SpeedMbps int      `json:"speed_mbps,omitempty"`
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+// hostOutput is the printable form of the host facts of an agent.
+type hostOutput struct {
+	OS           osOutput     `json:"os"`
+	Architecture string       `json:"architecture"`
+	CPUModel     string       `json:"cpu_model"`
+	CPUCores     int          `json:"cpu_cores"`
+	MemoryBytes  uint64       `json:"memory_bytes"`
+	Disks        []diskOutput `json:"disks"`
+	NICs         []nicOutput  `json:"nics"`
+}
+
+// capabilitiesOutput is the printable form of the capabilities of an agent.
+type capabilitiesOutput struct {
+	SourceHypervisors       []string `json:"source_hypervisors"`
+	TargetHypervisors       []string `json:"target_hypervisors"`
+	MaxConcurrentMigrations int      `json:"max_concurrent_migrations"`
+}
+
+// configOutput is the printable form of the configuration of an agent.
+type configOutput struct {
+	LogLevel    string `json:"log_level"`
+	InstallPath string `json:"install_path"`
+}
+
+// heartbeatOutput is the printable form of a heartbeat.
+type heartbeatOutput struct {
+	Time    time.Time       `json:"time"`
+	Latency client.Duration `json:"latency"`
+	Status  string          `json:"status"`
+}
+
+// eventOutput is the printable form of an event.
+type eventOutput struct {
+	Type     string    `json:"type"`
+	Reason   string    `json:"reason"`
+	Message  string    `json:"message"`
+	Count    int       `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// agentDescription is the printable form of agent describe.
+type agentDescription struct {
+	ID               string             `json:"id"`
+	Hostname         string             `json:"hostname"`
+	Address          string             `json:"address"`
+	Status           string             `json:"status"`
+	Version          string             `json:"version"`
+	Labels           map[string]string  `json:"labels,omitempty"`
+	Annotations      map[string]string  `json:"annotations,omitempty"`
+	Schedulable      bool               `json:"schedulable"`
+	LastHeartbeat    time.Time          `json:"last_heartbeat"`
+	RegisteredAt     time.Time          `json:"registered_at"`
+	ActiveMigrations int                `json:"active_migrations"`
+	Host             hostOutput         `json:"host"`
+	Capabilities     capabilitiesOutput `json:"capabilities"`
+	Config           configOutput       `json:"config"`
+	Heartbeats       []heartbeatOutput  `json:"heartbeats,omitempty"`
+	Events           []eventOutput      `json:"events,omitempty"`
+
+	heartbeatLimit int
+	eventLimit     int
+}
+
+// newAgentDescription returns the printable form of the details, heartbeats
+// and events of an agent.
+func newAgentDescription(details *client.AgentDetails, heartbeats []client.Heartbeat, events []client.Event) agentDescription {
+	d := agentDescription{
+		ID:               details.ID,
+		Hostname:         details.Hostname,
+		Address:          details.Address,
+		Status:           details.Status,
+		Version:          details.Version,
+		Labels:           details.Labels,
+		Annotations:      details.Annotations,
+		Schedulable:      details.Schedulable,
+		LastHeartbeat:    details.LastHeartbeat,
+		RegisteredAt:     details.RegisteredAt,
+		ActiveMigrations: details.ActiveMigrations,
+		Host: hostOutput{
+			OS:           osOutput(details.Host.OS),
+			Architecture: details.Host.Architecture,
+			CPUModel:     details.Host.CPUModel,
+			CPUCores:     details.Host.CPUCores,
+			MemoryBytes:  details.Host.MemoryBytes,
+			Disks:        make([]diskOutput, 0, len(details.Host.Disks)),
+			NICs:         make([]nicOutput, 0, len(details.Host.NICs)),
+		},
+		Capabilities: capabilitiesOutput(details.Capabilities),
+		Config:       configOutput(details.Config),
+	}
+	for _, disk := range details.Host.Disks {
+		d.Host.Disks = append(d.Host.Disks, diskOutput(disk))
+	}
+	for _, nic := range details.Host.NICs {
+		d.Host.NICs = append(d.Host.NICs, nicOutput(nic))
+	}
+	for _, heartbeat := range heartbeats {
+		d.Heartbeats = append(d.Heartbeats, heartbeatOutput(heartbeat))
+	}
+	for _, event := range events {
+		d.Events = append(d.Events, eventOutput(event))
+	}
+	return d
+}
+
+// TableHeader returns no header since the description is printed as sections.
+func (d agentDescription) TableHeader(_ bool) []string {
+	return nil
+}
+
+// TableRows returns the description as indented key-value pairs and tables,
+// in the layout of kubectl describe.
+func (d agentDescription) TableRows(_ bool) [][]string {
+	rows := [][]string{
+		{"ID:", d.ID},
+		{"Hostname:", d.Hostname},
+		{"Address:", d.Address},
+		{"Status:", d.Status},
+		{"Version:", d.Version},
+		{"Schedulable:", strconv.FormatBool(d.Schedulable)},
+	}
+	rows = append(rows, mapRows("Labels:", d.Labels)...)
+	rows = append(rows, mapRows("Annotations:", d.Annotations)...)
+	rows = append(rows,
+		[]string{"Registered:", formatTime(d.RegisteredAt)},
+		[]string{"Last Heartbeat:", formatTime(d.LastHeartbeat)},
+		[]string{"Active Migrations:", strconv.Itoa(d.ActiveMigrations)},
+	)
+
+	host := d.Host
+	rows = append(rows,
+		[]string{"Host:"},
+		[]string{"  OS:", formatOS(client.OSInfo(host.OS))},
+		[]string{"  Architecture:", printer.ValueOrNone(host.Architecture)},
+		[]string{"  CPU:", formatCPU(host.CPUModel, host.CPUCores)},
+		[]string{"  Memory:", formatBytes(host.MemoryBytes)},
+		[]string{"  Disks:"},
+	)
+	if len(host.Disks) == 0 {
+		rows = append(rows, []string{"    " + printer.None})
+	} else {
+		rows = append(rows, []string{"    NAME", "SIZE", "MOUNT", "MODEL"})
+		for _, disk := range host.Disks {
+			rows = append(rows, []string{"    " + disk.Name, formatBytes(disk.SizeBytes), printer.ValueOrNone(disk.MountPoint), printer.ValueOrNone(disk.Model)})
+		}
+	}
+	rows = append(rows, []string{"  NICs:"})
+	if len(host.NICs) == 0 {
+		rows = append(rows, []string{"    " + printer.None})
+	} else {
+		rows = append(rows, []string{"    NAME", "MAC", "SPEED", "ADDRESSES"})
+		for _, nic := range host.NICs {
+			rows = append(rows, []string{"    " + nic.Name, nic.MAC, formatSpeed(nic.SpeedMbps), printer.ValueOrNone(strings.Join(nic.Addresses, ","))})
+		}
+	}
+
+	capabilities := d.Capabilities
+	rows = append(rows,
+		[]string{"Capabilities:"},
+		[]string{"  Source Hypervisors:", printer.ValueOrNone(strings.Join(capabilities.SourceHypervisors, ", "))},
+		[]string{"  Target Hypervisors:", printer.ValueOrNone(strings.Join(capabilities.TargetHypervisors, ", "))},
+		[]string{"  Max Concurrent Migrations:", strconv.Itoa(capabilities.MaxConcurrentMigrations)},
+		[]string{"Configuration:"},
+		[]string{"  Log Level:", printer.ValueOrNone(d.Config.LogLevel)},
+		[]string{"  Install Path:", printer.ValueOrNone(d.Config.InstallPath)},
+	)
+
+	if d.heartbeatLimit > 0 {
+		rows = append(rows, []string{fmt.Sprintf("Heartbeats (last %d):", d.heartbeatLimit)})
+		if len(d.Heartbeats) == 0 {
+			rows = append(rows, []string{"  " + printer.None})
+		} else {
+			rows = append(rows, []string{"  TIME", "AGE", "LATENCY", "STATUS"})
+			for _, heartbeat := range d.Heartbeats {
+				rows = append(rows, []string{
+					"  " + heartbeat.Time.Local().Format(time.RFC3339),
+					printer.HumanAge(heartbeat.Time),
+					time.Duration(heartbeat.Latency).String(),
+					heartbeat.Status,
+				})
+			}
+		}
+	}
+
+	if d.eventLimit > 0 {
+		rows = append(rows, []string{"Events:"})
+		if len(d.Events) == 0 {
+			rows = append(rows, []string{"  " + printer.None})
+		} else {
+			rows = append(rows, []string{"  TYPE", "REASON", "AGE", "COUNT", "MESSAGE"})
+			for _, event := range d.Events {
+				rows = append(rows, []string{
+					"  " + event.Type,
+					event.Reason,
+					printer.HumanAge(event.LastSeen),
+					strconv.Itoa(event.Count),
+					event.Message,
+				})
+			}
+		}
+	}
+
+	return rows
+}
+
+// Names returns the agent ID.
+func (d agentDescription) Names() []string {
+	return []string{d.ID}
+}
+
+// mapRows renders a map as sorted key=value pairs, one per row, with the
+// title on the first row.
+func mapRows(title string, m map[string]string) [][]string {
+	if len(m) == 0 {
+		return [][]string{{title, printer.None}}
+	}
+
+	rows := make([][]string, 0, len(m))
+	for i, pair := range strings.Split(printer.FormatLabels(m), ",") {
+		if i == 0 {
+			rows = append(rows, []string{title, pair})
+		} else {
+			rows = append(rows, []string{"", pair})
+		}
+	}
+	return rows
+}
+
+// formatTime renders a time together with its age, for example
+// "2026-01-02T03:04:05Z (5d ago)".
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return printer.None
+	}
+	return fmt.Sprintf("%s (%s ago)", t.Local().Format(time.RFC3339), printer.HumanAge(t))
+}
+
+// formatOS renders the operating system, for example "linux (Ubuntu 22.04, kernel 5.15.0)".
+func formatOS(info client.OSInfo) string {
+	if info.Name == "" {
+		return printer.None
+	}
+
+	var details []string
+	if platform := strings.TrimSpace(info.PlatformName + " " + info.PlatformVersion); platform != "" {
+		details = append(details, platform)
+	}
+	if info.KernelVersion != "" {
+		details = append(details, "kernel "+info.KernelVersion)
+	}
+	if len(details) == 0 {
+		return info.Name
+	}
+	return fmt.Sprintf("%s (%s)", info.Name, strings.Join(details, ", "))
+}
+
+// formatCPU renders the CPU model and core count.
+func formatCPU(model string, cores int) string {
+	switch {
+	case model == "" && cores == 0:
+		return printer.None
+	case model == "":
+		return fmt.Sprintf("%d cores", cores)
+	default:
+		return fmt.Sprintf("%s (%d cores)", model, cores)
+	}
+}
+
+// formatBytes renders a size in binary units, for example "480Gi".
+func formatBytes(n uint64) string {
+	if n == 0 {
+		return printer.None
+	}
+
+	units := []string{"", "Ki", "Mi", "Gi", "Ti", "Pi"}
+	size := float64(n)
+	i := 0
+	for size >= 1024 && i < len(units)-1 {
+		size /= 1024
+		i++
+	}
+	return strconv.FormatFloat(math.Round(size*10)/10, 'f', -1, 64) + units[i]
+}
+
+// formatSpeed renders a link speed, for example "25Gb/s".
+func formatSpeed(mbps int) string {
+	switch {
+	case mbps <= 0:
+		return "-"
+	case mbps%1000 == 0:
+		return fmt.Sprintf("%dGb/s", mbps/1000)
+	default:
+		return fmt.Sprintf("%dMb/s", mbps)
+	}
+}
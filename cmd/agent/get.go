@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+	"github.com/morpher-vm/morpherctl/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+func newGetCmd(f *cmdutil.Factory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <id>",
+		Short: "Show an agent",
+		Long:  `Show a single agent registered with the controller.`,
+		Example: `  morpherctl agent get agent-1
+  morpherctl agent get agent-1 -o yaml`,
+		Args:              cmdutil.UsageArgs(cobra.ExactArgs(1)),
+		ValidArgsFunction: completeAgentIDs(f),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return getAgent(cmd.Context(), f, args[0])
+		},
+	}
+}
+
+func getAgent(ctx context.Context, f *cmdutil.Factory, id string) error {
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
+
+	// Create controller client.
+	c, err := f.ControllerClient()
+	if err != nil {
+		return fmt.Errorf("failed to create controller client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.GetTimeout())
+	defer cancel()
+
+	agent, err := c.GetAgent(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return p.Print(f.IOStreams.Out, newAgentOutput(*agent))
+}
+
+// completeAgentIDs completes the IDs of the agents known to the controller.
+func completeAgentIDs(f *cmdutil.Factory) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		c, err := f.ControllerClient()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		ctx, cancel := context.WithTimeout(cmd.Context(), c.GetTimeout())
+		defer cancel()
+
+		agents, err := c.ListAgents(ctx, client.ListAgentsOptions{})
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return newAgentList(agents).Names(), cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,381 @@
+package agent
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+	"github.com/morpher-vm/morpherctl/internal/install"
+	"github.com/morpher-vm/morpherctl/internal/printer"
+	"github.com/morpher-vm/morpherctl/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+// installOptions holds the flags of the install command.
+type installOptions struct {
+	local         bool
+	binary        string
+	sha256        string
+	installPath   string
+	controllerURL string
+	joinToken     string
+	joinTokenTTL  time.Duration
+	logLevel      string
+	root          string
+	dryRun        bool
+	noStart       bool
+	ssh           sshOptions
+}
+
+func newInstallCmd(f *cmdutil.Factory) *cobra.Command {
+	var opts installOptions
+
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install the agent on hosts",
+		Long: `Install the morpher agent as a systemd service, on this machine with --local
+or on remote hosts over SSH with --ssh.
+
+The agent binary is copied to bin/ below the install path and verified
+against its SHA-256 checksum, taken from --sha256 or a "<binary>.sha256" file
+next to the binary. An agent configuration with the controller URL, join token
+and log level is written to etc/agent.yaml, and a systemd unit is generated.
+Without --join-token, a join token valid for --join-token-ttl and for as many
+registrations as there are hosts is created on the controller once the
+binary is verified; it is revoked again if no host could be installed. With
+--root no join token is created and the staged configuration has none. The
+install path and log level default to agent.install_path and agent.log_level
+of the configuration, the controller URL to the controller morpherctl
+connects to: the first of controller.urls, or else controller.url.
+
+--ssh takes a comma separated list of [user@]host[:port]; hosts without a user
+inherit the user of the host before them. Files are uploaded over SSH and
+commands run through sudo unless the user is root. Host keys are checked
+against ~/.ssh/known_hosts or --known-hosts. Keys are taken from --ssh-key and
+a running ssh-agent, or else the default keys in ~/.ssh. Up to --parallel
+hosts are installed at a time and the result is reported per host.
+
+With --root all files are written below the given directory instead of /, and
+the service is not started. --dry-run prints every file that would be written
+instead.`,
+		Example: `  morpherctl agent install --local --binary ./morpher-agent --join-token abc123
+  morpherctl agent install --local --binary ./morpher-agent --dry-run
+  morpherctl agent install --local --binary ./morpher-agent --root /tmp/staging
+  morpherctl agent install --ssh admin@hv1,hv2,hv3:2222 --binary ./morpher-agent --parallel 10`,
+		Args: cmdutil.UsageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return installAgent(cmd.Context(), f, opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.local, "local", false, "install on this machine")
+	cmd.Flags().StringVar(&opts.binary, "binary", "", "path of the agent binary to install")
+	cmd.Flags().StringVar(&opts.sha256, "sha256", "", "expected SHA-256 checksum of the binary")
+	cmd.Flags().StringVar(&opts.installPath, "install-path", "", "directory to install into (default agent.install_path)")
+	cmd.Flags().StringVar(&opts.controllerURL, "controller-url", "", "controller the agent registers with (default the configured controller)")
+	cmd.Flags().StringVar(&opts.joinToken, "join-token", "", "token the agent registers with (default a newly created token)")
+	cmd.Flags().DurationVar(&opts.joinTokenTTL, "join-token-ttl", time.Hour, "how long a created join token is valid")
+	cmd.Flags().StringVar(&opts.logLevel, "log-level", "", "agent log level: "+strings.Join(install.LogLevels, ", ")+" (default agent.log_level)")
+	cmd.Flags().StringVar(&opts.root, "root", "", "write all files below this directory and do not start the service")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "print the files that would be written without writing them")
+	cmd.Flags().BoolVar(&opts.noStart, "no-start", false, "do not enable and start the service")
+	opts.ssh.addFlags(cmd)
+	_ = cmd.MarkFlagFilename("binary")
+	_ = cmd.MarkFlagDirname("root")
+	_ = cmd.RegisterFlagCompletionFunc("log-level", cobra.FixedCompletions(install.LogLevels, cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+func installAgent(ctx context.Context, f *cmdutil.Factory, opts installOptions) error {
+	if err := opts.ssh.validate(opts.local); err != nil {
+		return err
+	}
+	if opts.binary == "" {
+		return errdefs.Usage(errors.New("--binary is required"))
+	}
+	if opts.joinTokenTTL <= 0 {
+		return errdefs.Usage(errors.New("--join-token-ttl must be positive"))
+	}
+
+	hosts := 1
+	var sshHosts []install.SSHHost
+	if opts.ssh.hosts != "" {
+		var err error
+		if sshHosts, err = opts.ssh.parseHosts(); err != nil {
+			return err
+		}
+		hosts = len(sshHosts)
+	}
+
+	configMgr := f.Config()
+	if opts.installPath == "" {
+		opts.installPath, _ = configMgr.GetString("agent.install_path")
+	}
+	if opts.logLevel == "" {
+		opts.logLevel, _ = configMgr.GetString("agent.log_level")
+	}
+	if opts.logLevel != "" && !slices.Contains(install.LogLevels, opts.logLevel) {
+		return errdefs.Usage(fmt.Errorf("invalid log level %q: must be one of %s", opts.logLevel, strings.Join(install.LogLevels, ", ")))
+	}
+	if opts.controllerURL == "" {
+		// Agents register with the controller the join token is created on.
+		opts.controllerURL = cmdutil.ControllerURLs(configMgr)[0]
+	}
+
+	plan, err := install.NewPlan(install.Options{
+		InstallPath:   opts.installPath,
+		Binary:        opts.binary,
+		SHA256:        opts.sha256,
+		ControllerURL: opts.controllerURL,
+		JoinToken:     opts.joinToken,
+		LogLevel:      opts.logLevel,
+		Start:         !opts.noStart && opts.root == "",
+	})
+	if err != nil {
+		return err
+	}
+
+	// The join token is only created once the plan is valid, and revoked
+	// again if no host was installed with it.
+	installed := false
+	switch {
+	case opts.joinToken != "":
+	case opts.dryRun:
+		fmt.Fprintln(f.InfoOut(), "# A join token is created when installing without --dry-run.")
+	case opts.root != "":
+		// Staged files may never be deployed, so no usable token is minted
+		// for them.
+		fmt.Fprintf(f.InfoOut(), "No join token was created since --root is set: add controller.join_token to %s before starting the agent\n",
+			install.ConfigPath(cmp.Or(opts.installPath, install.DefaultInstallPath)))
+	default:
+		token, err := createInstallToken(ctx, f, opts.joinTokenTTL, hosts)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if !installed {
+				revokeInstallToken(ctx, f, token)
+			}
+		}()
+		if err := plan.SetJoinToken(token.Token); err != nil {
+			return err
+		}
+	}
+
+	if opts.ssh.hosts != "" {
+		installed, err = installAgentSSH(ctx, f, plan, sshHosts, opts)
+		return err
+	}
+
+	if opts.dryRun {
+		return plan.Print(f.IOStreams.Out, filepath.ToSlash(opts.root))
+	}
+
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
+
+	if err := install.Apply(ctx, plan, install.LocalTarget{Root: opts.root}); err != nil {
+		return err
+	}
+	installed = true
+
+	if err := p.Print(f.IOStreams.Out, newInstalledFiles(plan.Files)); err != nil {
+		return err
+	}
+	printStartNote(f, plan, opts)
+	return nil
+}
+
+// installAgentSSH installs the agent on the hosts and reports whether it was
+// installed on any of them.
+func installAgentSSH(ctx context.Context, f *cmdutil.Factory, plan *install.Plan, hosts []install.SSHHost, opts installOptions) (bool, error) {
+	if opts.dryRun {
+		names := make([]string, 0, len(hosts))
+		for _, host := range hosts {
+			names = append(names, host.String())
+		}
+		fmt.Fprintf(f.IOStreams.Out, "# Hosts: %s\n\n", strings.Join(names, ", "))
+		return false, plan.Print(f.IOStreams.Out, path.Clean("/"+opts.root))
+	}
+
+	p, err := f.NewPrinter()
+	if err != nil {
+		return false, err
+	}
+
+	sshConfig, closeSSHConfig, err := opts.ssh.clientConfig()
+	if err != nil {
+		return false, err
+	}
+	defer closeSSHConfig()
+
+	errs := install.ApplySSH(ctx, plan, hosts, sshConfig, opts.root, opts.ssh.parallel)
+
+	results := make(hostResults, 0, len(hosts))
+	failed := 0
+	for i, host := range hosts {
+		result := hostResult{Host: host.String(), Status: hostInstalled}
+		if errs[i] != nil {
+			result.Status = hostFailed
+			result.Error = errs[i].Error()
+			failed++
+		}
+		results = append(results, result)
+	}
+
+	installed := failed < len(hosts)
+	if err := p.Print(f.IOStreams.Out, results); err != nil {
+		return installed, err
+	}
+	if failed > 0 {
+		return installed, fmt.Errorf("agent installation failed on %d of %d hosts", failed, len(hosts))
+	}
+	printStartNote(f, plan, opts)
+	return installed, nil
+}
+
+// createInstallToken creates a join token for the given number of hosts.
+func createInstallToken(ctx context.Context, f *cmdutil.Factory, ttl time.Duration, hosts int) (*client.JoinToken, error) {
+	// Create controller client.
+	c, err := f.ControllerClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create controller client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.GetTimeout())
+	defer cancel()
+
+	token, err := c.CreateJoinToken(ctx, client.CreateJoinTokenRequest{
+		TTL:         client.Duration(ttl),
+		Description: "created by morpherctl agent install",
+		MaxUses:     hosts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(f.InfoOut(), "Created join token %s valid for %s and %d registrations\n", token.ID, ttl, hosts)
+	return token, nil
+}
+
+// revokeInstallToken revokes a join token that no host was installed with.
+// Failing to revoke it is only reported since the installation already failed.
+func revokeInstallToken(ctx context.Context, f *cmdutil.Factory, token *client.JoinToken) {
+	// Revoke the token even if the installation was interrupted.
+	ctx = context.WithoutCancel(ctx)
+
+	c, err := f.ControllerClient()
+	if err == nil {
+		ctx, cancel := context.WithTimeout(ctx, c.GetTimeout())
+		err = c.RevokeJoinToken(ctx, token.ID)
+		cancel()
+	}
+	if err != nil {
+		fmt.Fprintf(f.IOStreams.ErrOut, "Failed to revoke join token %s: %v\n", token.ID, err)
+		return
+	}
+	fmt.Fprintf(f.InfoOut(), "Revoked join token %s since no agent was installed\n", token.ID)
+}
+
+// printStartNote tells whether the service was started.
+func printStartNote(f *cmdutil.Factory, plan *install.Plan, opts installOptions) {
+	switch {
+	case len(plan.Commands) > 0:
+		fmt.Fprintf(f.InfoOut(), "Started %s.service\n", install.ServiceName)
+	case opts.root != "" && !opts.noStart:
+		fmt.Fprintf(f.InfoOut(), "Not starting %s.service since --root is set\n", install.ServiceName)
+	}
+}
+
+// Outcomes of an installation on a host.
+const (
+	hostInstalled = "installed"
+	hostFailed    = "failed"
+)
+
+// hostResult is the outcome of an installation on a host.
+type hostResult struct {
+	Host   string `json:"host"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// hostResults is the printable form of the outcome of an installation on hosts.
+type hostResults []hostResult
+
+// TableHeader returns the column names of the host table.
+func (l hostResults) TableHeader(_ bool) []string {
+	return []string{"HOST", "STATUS", "ERROR"}
+}
+
+// TableRows returns a row for every host.
+func (l hostResults) TableRows(_ bool) [][]string {
+	rows := make([][]string, 0, len(l))
+	for _, result := range l {
+		rows = append(rows, []string{result.Host, result.Status, printer.ValueOrNone(result.Error)})
+	}
+	return rows
+}
+
+// Names returns the hosts.
+func (l hostResults) Names() []string {
+	names := make([]string, 0, len(l))
+	for _, result := range l {
+		names = append(names, result.Host)
+	}
+	return names
+}
+
+// installedFile is a file written by an installation.
+type installedFile struct {
+	Path string `json:"path"`
+	Mode string `json:"mode"`
+	Size int    `json:"size"`
+}
+
+// installedFiles is the printable form of the files written by an installation.
+type installedFiles []installedFile
+
+// newInstalledFiles describes the files of an installation plan.
+func newInstalledFiles(files []install.File) installedFiles {
+	l := make(installedFiles, 0, len(files))
+	for _, file := range files {
+		l = append(l, installedFile{Path: file.Path, Mode: fmt.Sprintf("%04o", file.Mode), Size: len(file.Content)})
+	}
+	return l
+}
+
+// TableHeader returns the column names of the file table.
+func (l installedFiles) TableHeader(_ bool) []string {
+	return []string{"PATH", "MODE", "SIZE"}
+}
+
+// TableRows returns a row for every file.
+func (l installedFiles) TableRows(_ bool) [][]string {
+	rows := make([][]string, 0, len(l))
+	for _, file := range l {
+		rows = append(rows, []string{file.Path, file.Mode, formatBytes(uint64(file.Size))})
+	}
+	return rows
+}
+
+// Names returns the file paths.
+func (l installedFiles) Names() []string {
+	names := make([]string, 0, len(l))
+	for _, file := range l {
+		names = append(names, file.Path)
+	}
+	return names
+}
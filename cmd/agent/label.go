@@ -0,0 +1,165 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+	"github.com/morpher-vm/morpherctl/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+// metadataOptions holds the flags of the label and annotate commands.
+type metadataOptions struct {
+	overwrite bool
+}
+
+// metadataKind describes whether labels or annotations are changed.
+type metadataKind struct {
+	// name is used in messages, such as "label".
+	name string
+	// validateValue checks values; annotation values are free-form.
+	validateValue func(string) error
+	update        func(c *client.Client, ctx context.Context, id string, update client.MetadataUpdate) (*client.Agent, error)
+}
+
+var (
+	labelKind = metadataKind{
+		name:          "label",
+		validateValue: client.ValidateLabelValue,
+		update:        (*client.Client).UpdateAgentLabels,
+	}
+	annotationKind = metadataKind{
+		name:          "annotation",
+		validateValue: func(string) error { return nil },
+		update:        (*client.Client).UpdateAgentAnnotations,
+	}
+)
+
+func newLabelCmd(f *cmdutil.Factory) *cobra.Command {
+	var opts metadataOptions
+
+	cmd := &cobra.Command{
+		Use:   "label <id> <key>=<value>... <key>-...",
+		Short: "Add, change or remove labels of an agent",
+		Long: `Add labels to an agent with key=value and remove them with key-.
+
+Keys are names of up to 63 alphanumeric characters, '-', '_' and '.', which
+may be preceded by a DNS prefix such as "example.com/". Values follow the same
+rules as names and may be empty. Changing the value of an existing label
+requires --overwrite.
+
+Labels are matched by the --selector flag of other commands.`,
+		Example: `  morpherctl agent label agent-1 site=dc1 tier=gold
+  morpherctl agent label agent-1 tier=silver --overwrite
+  morpherctl agent label agent-1 tier-`,
+		Args:              cmdutil.UsageArgs(cobra.MinimumNArgs(2)),
+		ValidArgsFunction: completeAgentIDs(f),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updateMetadata(cmd.Context(), f, labelKind, args[0], args[1:], opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.overwrite, "overwrite", false, "allow changing the values of existing labels")
+
+	return cmd
+}
+
+func newAnnotateCmd(f *cmdutil.Factory) *cobra.Command {
+	var opts metadataOptions
+
+	cmd := &cobra.Command{
+		Use:   "annotate <id> <key>=<value>... <key>-...",
+		Short: "Add, change or remove annotations of an agent",
+		Long: `Add annotations to an agent with key=value and remove them with key-.
+
+Annotations hold free-form information, such as the owner of a host, and
+cannot be selected on. Keys follow the rules of label keys and values may be
+any text. Changing the value of an existing annotation requires --overwrite.`,
+		Example: `  morpherctl agent annotate agent-1 owner="storage team"
+  morpherctl agent annotate agent-1 owner-`,
+		Args:              cmdutil.UsageArgs(cobra.MinimumNArgs(2)),
+		ValidArgsFunction: completeAgentIDs(f),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updateMetadata(cmd.Context(), f, annotationKind, args[0], args[1:], opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.overwrite, "overwrite", false, "allow changing the values of existing annotations")
+
+	return cmd
+}
+
+func updateMetadata(ctx context.Context, f *cmdutil.Factory, kind metadataKind, id string, args []string, opts metadataOptions) error {
+	update, err := parseMetadataArgs(kind, args)
+	if err != nil {
+		return errdefs.Usage(err)
+	}
+	update.Overwrite = opts.overwrite
+
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
+
+	// Create controller client.
+	c, err := f.ControllerClient()
+	if err != nil {
+		return fmt.Errorf("failed to create controller client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.GetTimeout())
+	defer cancel()
+
+	agent, err := kind.update(c, ctx, id, update)
+	if err != nil {
+		if errdefs.KindOf(err) == errdefs.KindConflict {
+			return fmt.Errorf("%w: use --overwrite to change it", err)
+		}
+		return err
+	}
+
+	return p.Print(f.IOStreams.Out, newAgentOutput(*agent))
+}
+
+// parseMetadataArgs parses key=value arguments to set and key- arguments to
+// remove.
+func parseMetadataArgs(kind metadataKind, args []string) (client.MetadataUpdate, error) {
+	update := client.MetadataUpdate{Set: map[string]string{}}
+	for _, arg := range args {
+		key, value, isSet := strings.Cut(arg, "=")
+		if !isSet {
+			var isRemove bool
+			key, isRemove = strings.CutSuffix(arg, "-")
+			if !isRemove {
+				return client.MetadataUpdate{}, fmt.Errorf("invalid %s %q: expected key=value or key-", kind.name, arg)
+			}
+		}
+
+		err := client.ValidateLabelKey(key)
+		if err == nil && isSet {
+			err = kind.validateValue(value)
+		}
+		if err != nil {
+			return client.MetadataUpdate{}, fmt.Errorf("invalid %s %q: %w", kind.name, arg, err)
+		}
+
+		_, setBefore := update.Set[key]
+		if setBefore || slices.Contains(update.Remove, key) {
+			return client.MetadataUpdate{}, fmt.Errorf("%s %q is given more than once", kind.name, key)
+		}
+		if isSet {
+			update.Set[key] = value
+		} else {
+			update.Remove = append(update.Remove, key)
+		}
+	}
+	if len(update.Set) == 0 {
+		update.Set = nil
+	}
+	return update, nil
+}
@@ -0,0 +1,134 @@
+package agent
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+	"github.com/morpher-vm/morpherctl/internal/printer"
+	"github.com/morpher-vm/morpherctl/pkg/client"
+	"github.com/morpher-vm/morpherctl/pkg/semver"
+
+	"github.com/spf13/cobra"
+)
+
+// agentSortFields compares agents by the fields accepted by --sort-by.
+var agentSortFields = map[string]func(a, b client.Agent) int{
+	"id":         func(a, b client.Agent) int { return strings.Compare(a.ID, b.ID) },
+	"hostname":   func(a, b client.Agent) int { return strings.Compare(a.Hostname, b.Hostname) },
+	"version":    func(a, b client.Agent) int { return compareVersions(a.Version, b.Version) },
+	"status":     func(a, b client.Agent) int { return strings.Compare(a.Status, b.Status) },
+	"heartbeat":  func(a, b client.Agent) int { return b.LastHeartbeat.Compare(a.LastHeartbeat) },
+	"migrations": func(a, b client.Agent) int { return cmp.Compare(b.ActiveMigrations, a.ActiveMigrations) },
+}
+
+// compareVersions orders versions semantically, so that v1.10.0 sorts after
+// v1.9.0. Versions that do not parse sort last, in string order.
+func compareVersions(a, b string) int {
+	va, errA := semver.Parse(a)
+	vb, errB := semver.Parse(b)
+	switch {
+	case errA == nil && errB == nil:
+		return va.Compare(vb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// listOptions holds the flags of the list command.
+type listOptions struct {
+	status   string
+	selector string
+	sortBy   string
+}
+
+func newListCmd(f *cmdutil.Factory) *cobra.Command {
+	var opts listOptions
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List agents",
+		Long: `List the agents registered with the controller.
+
+Agents can be filtered by status and by a label selector, a comma separated
+list of requirements that must all match: key=value, key!=value,
+key in (a,b), key notin (a,b), key for agents with the label and !key for
+agents without it. Sorting by heartbeat shows the most recent heartbeat
+first and sorting by migrations shows the busiest agents first. The status of
+cordoned agents is followed by "cordoned", or by "draining" while agent drain
+waits for their migrations.`,
+		Example: `  morpherctl agent list
+  morpherctl agent list --status ready --selector zone=a
+  morpherctl agent list --selector 'site in (dc1,dc2),!maintenance'
+  morpherctl agent list --sort-by heartbeat -o wide`,
+		Args: cmdutil.UsageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return listAgents(cmd.Context(), f, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.status, "status", "", "only list agents with this status (ready, not_ready, unknown)")
+	cmd.Flags().StringVarP(&opts.selector, "selector", "l", "", "only list agents whose labels match the selector")
+	cmd.Flags().StringVar(&opts.sortBy, "sort-by", "id", "sort by "+strings.Join(slices.Sorted(maps.Keys(agentSortFields)), ", "))
+	_ = cmd.RegisterFlagCompletionFunc("status", cobra.FixedCompletions(
+		[]string{client.AgentReady, client.AgentNotReady, client.AgentUnknown}, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("sort-by", cobra.FixedCompletions(
+		slices.Sorted(maps.Keys(agentSortFields)), cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+func listAgents(ctx context.Context, f *cmdutil.Factory, opts listOptions) error {
+	if _, err := client.ParseSelector(opts.selector); err != nil {
+		return errdefs.Usage(err)
+	}
+	compare, ok := agentSortFields[opts.sortBy]
+	if !ok {
+		return errdefs.Usage(fmt.Errorf("invalid --sort-by %q: must be one of %s",
+			opts.sortBy, strings.Join(slices.Sorted(maps.Keys(agentSortFields)), ", ")))
+	}
+
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
+
+	// Create controller client.
+	c, err := f.ControllerClient()
+	if err != nil {
+		return fmt.Errorf("failed to create controller client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.GetTimeout())
+	defer cancel()
+
+	agents, err := c.ListAgents(ctx, client.ListAgentsOptions{
+		Status:   opts.status,
+		Selector: opts.selector,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Ties keep the order of the ID so that output is stable.
+	slices.SortStableFunc(agents, func(a, b client.Agent) int {
+		return cmp.Or(compare(a, b), strings.Compare(a.ID, b.ID))
+	})
+
+	if len(agents) == 0 && !printer.IsStructured(f.OutputFormat) {
+		fmt.Fprintln(f.IOStreams.ErrOut, "No agents found.")
+		return nil
+	}
+
+	return p.Print(f.IOStreams.Out, newAgentList(agents))
+}
@@ -0,0 +1,138 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+	"github.com/morpher-vm/morpherctl/internal/install"
+	"github.com/morpher-vm/morpherctl/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+// logLevelOptions holds the flags of the log-level command.
+type logLevelOptions struct {
+	duration time.Duration
+}
+
+func newLogLevelCmd(f *cmdutil.Factory) *cobra.Command {
+	var opts logLevelOptions
+
+	cmd := &cobra.Command{
+		Use:   "log-level <id> [level]",
+		Short: "Show or change the log level of a running agent",
+		Long: `Show the log level of a running agent or change it without restarting the
+agent. The change is sent through the controller.
+
+With --for the change is temporary: the controller reverts the agent to its
+default level once the duration has passed, even if morpherctl is no longer
+running. Without --for the level becomes the agent's default until it is
+restarted; agent.log_level of the configuration only applies to new
+installations.`,
+		Example: `  morpherctl agent log-level agent-1
+  morpherctl agent log-level agent-1 debug --for 15m
+  morpherctl agent log-level agent-1 info`,
+		Args: cmdutil.UsageArgs(cobra.RangeArgs(1, 2)),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 1 {
+				return install.LogLevels, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completeAgentIDs(f)(cmd, args, toComplete)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			level := ""
+			if len(args) == 2 {
+				level = args[1]
+			}
+			return agentLogLevel(cmd.Context(), f, args[0], level, opts)
+		},
+	}
+
+	cmd.Flags().DurationVar(&opts.duration, "for", 0, "revert to the default level after the duration")
+
+	return cmd
+}
+
+func agentLogLevel(ctx context.Context, f *cmdutil.Factory, id, level string, opts logLevelOptions) error {
+	switch {
+	case level == "" && opts.duration != 0:
+		return errdefs.Usage(errors.New("--for requires a level"))
+	case level != "" && !slices.Contains(install.LogLevels, level):
+		return errdefs.Usage(fmt.Errorf("invalid log level %q: must be one of %s", level, strings.Join(install.LogLevels, ", ")))
+	case opts.duration < 0:
+		return errdefs.Usage(fmt.Errorf("invalid --for %s: must not be negative", opts.duration))
+	}
+
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
+
+	// Create controller client.
+	c, err := f.ControllerClient()
+	if err != nil {
+		return fmt.Errorf("failed to create controller client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.GetTimeout())
+	defer cancel()
+
+	var current *client.AgentLogLevel
+	if level == "" {
+		current, err = c.GetAgentLogLevel(ctx, id)
+	} else {
+		current, err = c.SetAgentLogLevel(ctx, id, client.SetLogLevelRequest{
+			Level:    level,
+			Duration: client.Duration(opts.duration),
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	return p.Print(f.IOStreams.Out, logLevelOutput{
+		Agent:    id,
+		Level:    current.Level,
+		Default:  current.Default,
+		RevertAt: current.RevertAt,
+	})
+}
+
+// logLevelOutput is the printable form of the log level of an agent.
+type logLevelOutput struct {
+	Agent    string     `json:"agent"`
+	Level    string     `json:"level"`
+	Default  string     `json:"default"`
+	RevertAt *time.Time `json:"revert_at,omitempty"`
+}
+
+// TableHeader returns no header since the level is printed as key-value pairs.
+func (o logLevelOutput) TableHeader(_ bool) []string {
+	return nil
+}
+
+// TableRows returns the level as key-value pairs.
+func (o logLevelOutput) TableRows(_ bool) [][]string {
+	reverts := "never"
+	if o.RevertAt != nil {
+		reverts = fmt.Sprintf("%s (in %s)", o.RevertAt.Local().Format(time.RFC3339),
+			time.Until(*o.RevertAt).Round(time.Second))
+	}
+	return [][]string{
+		{"Agent:", o.Agent},
+		{"Level:", o.Level},
+		{"Default:", o.Default},
+		{"Reverts:", reverts},
+	}
+}
+
+// Names returns the current level.
+func (o logLevelOutput) Names() []string {
+	return []string{o.Level}
+}
@@ -0,0 +1,143 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/internal/agentlog"
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+	"github.com/morpher-vm/morpherctl/internal/printer"
+	"github.com/morpher-vm/morpherctl/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+// Values of the --color flag.
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+// logsOptions holds the flags of the logs command.
+type logsOptions struct {
+	follow bool
+	since  time.Duration
+	grep   string
+	color  string
+}
+
+func newLogsCmd(f *cmdutil.Factory) *cobra.Command {
+	var opts logsOptions
+
+	cmd := &cobra.Command{
+		Use:   "logs <id>",
+		Short: "Print the logs of an agent",
+		Long: `Print the logs of an agent, streamed through the controller.
+
+With --follow new entries are printed as they are logged until interrupted.
+A stream that breaks, for example because the controller restarts, is
+reopened where it stopped. --grep only prints entries whose line matches a
+regular expression.
+
+Levels are colored when writing to a terminal, unless NO_COLOR is set;
+--color overrides the detection. With --output json or yaml every entry is
+printed as an object.`,
+		Example: `  morpherctl agent logs agent-1 --since 10m
+  morpherctl agent logs agent-1 --follow --grep 'mig-2|error'`,
+		Args:              cmdutil.UsageArgs(cobra.ExactArgs(1)),
+		ValidArgsFunction: completeAgentIDs(f),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return agentLogs(cmd.Context(), f, args[0], opts)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&opts.follow, "follow", "f", false, "keep printing new entries")
+	cmd.Flags().DurationVar(&opts.since, "since", 0, "only print entries newer than the duration, such as 10m")
+	cmd.Flags().StringVar(&opts.grep, "grep", "", "only print entries matching the regular expression")
+	cmd.Flags().StringVar(&opts.color, "color", colorAuto, "color levels: auto, always or never")
+	_ = cmd.RegisterFlagCompletionFunc("color", cobra.FixedCompletions(
+		[]string{colorAuto, colorAlways, colorNever}, cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+func agentLogs(ctx context.Context, f *cmdutil.Factory, id string, opts logsOptions) error {
+	if opts.since < 0 {
+		return errdefs.Usage(fmt.Errorf("invalid --since %s: must not be negative", opts.since))
+	}
+	var grep *regexp.Regexp
+	if opts.grep != "" {
+		var err error
+		if grep, err = regexp.Compile(opts.grep); err != nil {
+			return errdefs.Usage(fmt.Errorf("invalid --grep: %w", err))
+		}
+	}
+	var color bool
+	switch opts.color {
+	case colorAuto:
+		color = cmdutil.IsTerminal(f.IOStreams.Out) && os.Getenv("NO_COLOR") == ""
+	case colorAlways:
+		color = true
+	case colorNever:
+	default:
+		return errdefs.Usage(fmt.Errorf("invalid --color %q: must be auto, always or never", opts.color))
+	}
+
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
+
+	// Create controller client.
+	c, err := f.ControllerClient()
+	if err != nil {
+		return fmt.Errorf("failed to create controller client: %w", err)
+	}
+
+	follower := &agentlog.Follower{
+		Client: c,
+		Agent:  id,
+		Follow: opts.follow,
+		Grep:   grep,
+		OnReconnect: func(err error, delay time.Duration) {
+			fmt.Fprintf(f.IOStreams.ErrOut, "Log stream interrupted: %v; reconnecting in %s\n", err, delay)
+		},
+	}
+	if opts.since > 0 {
+		follower.Since = time.Now().Add(-opts.since)
+	}
+
+	formatter := agentlog.Formatter{Color: color}
+	structured := printer.IsStructured(f.OutputFormat)
+	err = follower.Run(ctx, func(entry client.LogEntry) error {
+		if structured {
+			return p.Print(f.IOStreams.Out, logEntryOutput(entry))
+		}
+		_, err := fmt.Fprintln(f.IOStreams.Out, formatter.Format(entry))
+		return err
+	})
+	// Interrupting a followed stream is the normal way to end it.
+	if opts.follow && errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// logEntryOutput is the printable form of a log entry.
+type logEntryOutput struct {
+	Time    time.Time         `json:"time"`
+	Level   string            `json:"level"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+// Names returns the message of the entry.
+func (o logEntryOutput) Names() []string {
+	return []string{o.Message}
+}
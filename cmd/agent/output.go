@@ -0,0 +1,188 @@
+package agent
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/internal/printer"
+	"github.com/morpher-vm/morpherctl/pkg/client"
+)
+
+// agentOutput is the printable form of a single agent.
+type agentOutput struct {
+	ID               string            `json:"id"`
+	Hostname         string            `json:"hostname"`
+	Address          string            `json:"address"`
+	Status           string            `json:"status"`
+	Version          string            `json:"version"`
+	Labels           map[string]string `json:"labels,omitempty"`
+	Annotations      map[string]string `json:"annotations,omitempty"`
+	Schedulable      bool              `json:"schedulable"`
+	LastHeartbeat    time.Time         `json:"last_heartbeat"`
+	RegisteredAt     time.Time         `json:"registered_at"`
+	ActiveMigrations int               `json:"active_migrations"`
+}
+
+// newAgentOutput returns the printable form of an agent.
+func newAgentOutput(agent client.Agent) agentOutput {
+	return agentOutput{
+		ID:               agent.ID,
+		Hostname:         agent.Hostname,
+		Address:          agent.Address,
+		Status:           agent.Status,
+		Version:          agent.Version,
+		Labels:           agent.Labels,
+		Annotations:      agent.Annotations,
+		Schedulable:      agent.Schedulable,
+		LastHeartbeat:    agent.LastHeartbeat,
+		RegisteredAt:     agent.RegisteredAt,
+		ActiveMigrations: agent.ActiveMigrations,
+	}
+}
+
+// TableHeader returns the column names of the agent table.
+func (o agentOutput) TableHeader(wide bool) []string {
+	return agentList{o}.TableHeader(wide)
+}
+
+// TableRows returns the agent as a single row.
+func (o agentOutput) TableRows(wide bool) [][]string {
+	return agentList{o}.TableRows(wide)
+}
+
+// Names returns the agent ID.
+func (o agentOutput) Names() []string {
+	return []string{o.ID}
+}
+
+// agentList is the printable form of a list of agents.
+type agentList []agentOutput
+
+// newAgentList returns the printable form of agents.
+func newAgentList(agents []client.Agent) agentList {
+	l := make(agentList, 0, len(agents))
+	for _, agent := range agents {
+		l = append(l, newAgentOutput(agent))
+	}
+	return l
+}
+
+// TableHeader returns the column names of the agent table.
+func (l agentList) TableHeader(wide bool) []string {
+	header := []string{"ID", "HOSTNAME", "VERSION", "STATUS", "LAST HEARTBEAT", "MIGRATIONS", "LABELS"}
+	if wide {
+		header = append(header, "ADDRESS", "SCHEDULABLE")
+	}
+	return header
+}
+
+// TableRows returns a row for every agent.
+func (l agentList) TableRows(wide bool) [][]string {
+	rows := make([][]string, 0, len(l))
+	for _, agent := range l {
+		row := []string{
+			agent.ID,
+			agent.Hostname,
+			agent.Version,
+			agentStatus(agent),
+			printer.HumanAge(agent.LastHeartbeat),
+			strconv.Itoa(agent.ActiveMigrations),
+			printer.FormatLabels(agent.Labels),
+		}
+		if wide {
+			row = append(row, agent.Address, strconv.FormatBool(agent.Schedulable))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// Names returns the agent IDs.
+func (l agentList) Names() []string {
+	names := make([]string, 0, len(l))
+	for _, agent := range l {
+		names = append(names, agent.ID)
+	}
+	return names
+}
+
+// agentStatus renders the status of an agent, followed by "draining" while
+// agent drain runs for it or "cordoned" for other unschedulable agents.
+func agentStatus(agent agentOutput) string {
+	_, draining := agent.Annotations[client.DrainingAnnotation]
+	switch {
+	case agent.Schedulable:
+		return agent.Status
+	case draining:
+		return agent.Status + ",draining"
+	default:
+		return agent.Status + ",cordoned"
+	}
+}
+
+// migrationOutput is the printable form of a single migration.
+type migrationOutput struct {
+	ID          string            `json:"id"`
+	VMName      string            `json:"vm_name"`
+	SourceAgent string            `json:"source_agent"`
+	TargetAgent string            `json:"target_agent"`
+	Phase       string            `json:"phase"`
+	Progress    int               `json:"progress"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Message     string            `json:"message,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+}
+
+// migrationList is the printable form of a list of migrations.
+type migrationList []migrationOutput
+
+// newMigrationList returns the printable form of migrations.
+func newMigrationList(migrations []client.Migration) migrationList {
+	l := make(migrationList, 0, len(migrations))
+	for _, migration := range migrations {
+		l = append(l, migrationOutput{
+			ID:          migration.ID,
+			VMName:      migration.VMName,
+			SourceAgent: migration.SourceAgent,
+			TargetAgent: migration.TargetAgent,
+			Phase:       migration.Phase,
+			Progress:    migration.Progress,
+			Labels:      migration.Labels,
+			Message:     migration.Message,
+			CreatedAt:   migration.CreatedAt,
+			CompletedAt: migration.CompletedAt,
+		})
+	}
+	return l
+}
+
+// TableHeader returns the column names of the migration table.
+func (l migrationList) TableHeader(_ bool) []string {
+	return []string{"ID", "VM", "SOURCE", "TARGET", "PHASE", "MESSAGE"}
+}
+
+// TableRows returns a row for every migration.
+func (l migrationList) TableRows(_ bool) [][]string {
+	rows := make([][]string, 0, len(l))
+	for _, migration := range l {
+		rows = append(rows, []string{
+			migration.ID,
+			migration.VMName,
+			migration.SourceAgent,
+			printer.ValueOrNone(migration.TargetAgent),
+			migration.Phase,
+			migration.Message,
+		})
+	}
+	return rows
+}
+
+// Names returns the migration IDs.
+func (l migrationList) Names() []string {
+	names := make([]string, 0, len(l))
+	for _, migration := range l {
+		names = append(names, migration.ID)
+	}
+	return names
+}
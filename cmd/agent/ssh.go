@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+	"github.com/morpher-vm/morpherctl/internal/install"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+)
+
+// sshTimeout limits connecting to a host.
+const sshTimeout = 15 * time.Second
+
+// sshOptions holds the flags of commands that work on hosts over SSH.
+type sshOptions struct {
+	hosts                 string
+	keys                  []string
+	knownHosts            string
+	insecureIgnoreHostKey bool
+	parallel              int
+}
+
+// addFlags registers the SSH flags on the command.
+func (o *sshOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.hosts, "ssh", "", "work on these comma separated [user@]host[:port] over SSH")
+	cmd.Flags().StringSliceVar(&o.keys, "ssh-key", nil, "private key to authenticate with over SSH (repeatable)")
+	cmd.Flags().StringVar(&o.knownHosts, "known-hosts", "", "known_hosts file to check host keys against (default ~/.ssh/known_hosts)")
+	cmd.Flags().BoolVar(&o.insecureIgnoreHostKey, "insecure-ignore-host-key", false, "do not check SSH host keys")
+	cmd.Flags().IntVar(&o.parallel, "parallel", 5, "number of hosts to work on at a time")
+	_ = cmd.MarkFlagFilename("ssh-key")
+	_ = cmd.MarkFlagFilename("known-hosts")
+}
+
+// validate checks that exactly one of local and SSH is selected and that the
+// SSH flags are consistent.
+func (o *sshOptions) validate(local bool) error {
+	if local == (o.hosts != "") {
+		return errdefs.Usage(errors.New("exactly one of --local and --ssh is required"))
+	}
+	if o.knownHosts != "" && o.insecureIgnoreHostKey {
+		return errdefs.Usage(errors.New("--known-hosts and --insecure-ignore-host-key cannot be used together"))
+	}
+	if o.parallel < 1 {
+		return errdefs.Usage(errors.New("--parallel must be at least 1"))
+	}
+	return nil
+}
+
+// parseHosts returns the hosts of --ssh.
+func (o *sshOptions) parseHosts() ([]install.SSHHost, error) {
+	hosts, err := install.ParseSSHHosts(o.hosts)
+	if err != nil {
+		return nil, errdefs.Usage(fmt.Errorf("invalid --ssh: %w", err))
+	}
+	return hosts, nil
+}
+
+// clientConfig returns the SSH client configuration of the flags and a
+// function releasing it once all hosts are done.
+func (o *sshOptions) clientConfig() (*ssh.ClientConfig, func() error, error) {
+	cfg, closeConfig, err := install.SSHConfig{
+		IdentityFiles:         o.keys,
+		KnownHostsFile:        o.knownHosts,
+		InsecureIgnoreHostKey: o.insecureIgnoreHostKey,
+		Timeout:               sshTimeout,
+	}.ClientConfig()
+	if err != nil {
+		return nil, nil, errdefs.Config(err)
+	}
+	return cfg, closeConfig, nil
+}
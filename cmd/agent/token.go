@@ -0,0 +1,394 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+	"github.com/morpher-vm/morpherctl/internal/printer"
+	"github.com/morpher-vm/morpherctl/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+func newTokenCmd(f *cmdutil.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Manage join tokens",
+		Long: `Manage the bootstrap tokens agents register with the controller.
+
+Tokens expire after their TTL and can be limited to a number of
+registrations. Agents that register with a token get the labels of the token.`,
+	}
+
+	cmd.AddCommand(
+		newTokenCreateCmd(f),
+		newTokenListCmd(f),
+		newTokenRevokeCmd(f),
+	)
+
+	return cmd
+}
+
+// tokenCreateOptions holds the flags of the token create command.
+type tokenCreateOptions struct {
+	ttl         time.Duration
+	labels      map[string]string
+	maxUses     int
+	description string
+}
+
+func newTokenCreateCmd(f *cmdutil.Factory) *cobra.Command {
+	var opts tokenCreateOptions
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a join token",
+		Long: `Create a join token. The token is only shown once; with -o name only the
+token is printed.`,
+		Example: `  morpherctl agent token create --ttl 1h --labels site=dc1 --max-uses 5
+  TOKEN=$(morpherctl agent token create -o name)`,
+		Args: cmdutil.UsageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return createToken(cmd.Context(), f, opts)
+		},
+	}
+
+	cmd.Flags().DurationVar(&opts.ttl, "ttl", 24*time.Hour, "how long the token is valid")
+	cmd.Flags().StringToStringVar(&opts.labels, "labels", nil, "labels of agents registering with the token, as key=value pairs")
+	cmd.Flags().IntVar(&opts.maxUses, "max-uses", 0, "maximum number of registrations, 0 for unlimited")
+	cmd.Flags().StringVar(&opts.description, "description", "", "description of the token")
+
+	return cmd
+}
+
+func createToken(ctx context.Context, f *cmdutil.Factory, opts tokenCreateOptions) error {
+	if opts.ttl <= 0 {
+		return errdefs.Usage(errors.New("--ttl must be positive"))
+	}
+	if opts.maxUses < 0 {
+		return errdefs.Usage(errors.New("--max-uses must not be negative"))
+	}
+	for _, key := range slices.Sorted(maps.Keys(opts.labels)) {
+		err := client.ValidateLabelKey(key)
+		if err == nil {
+			err = client.ValidateLabelValue(opts.labels[key])
+		}
+		if err != nil {
+			return errdefs.Usage(fmt.Errorf("invalid --labels %q: %w", key+"="+opts.labels[key], err))
+		}
+	}
+
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
+
+	// Create controller client.
+	c, err := f.ControllerClient()
+	if err != nil {
+		return fmt.Errorf("failed to create controller client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.GetTimeout())
+	defer cancel()
+
+	token, err := c.CreateJoinToken(ctx, client.CreateJoinTokenRequest{
+		TTL:         client.Duration(opts.ttl),
+		Description: opts.description,
+		Labels:      opts.labels,
+		MaxUses:     opts.maxUses,
+	})
+	if err != nil {
+		return err
+	}
+
+	return p.Print(f.IOStreams.Out, createdToken(newTokenOutput(*token, time.Now())))
+}
+
+func newTokenListCmd(f *cmdutil.Factory) *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List join tokens",
+		Long:    `List the join tokens known to the controller. Secrets are never shown.`,
+		Example: `  morpherctl agent token list
+  morpherctl agent token list -o wide`,
+		Args: cmdutil.UsageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return listTokens(cmd.Context(), f)
+		},
+	}
+}
+
+func listTokens(ctx context.Context, f *cmdutil.Factory) error {
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
+
+	// Create controller client.
+	c, err := f.ControllerClient()
+	if err != nil {
+		return fmt.Errorf("failed to create controller client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.GetTimeout())
+	defer cancel()
+
+	tokens, err := c.ListJoinTokens(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(tokens) == 0 && !printer.IsStructured(f.OutputFormat) {
+		fmt.Fprintln(f.IOStreams.ErrOut, "No join tokens found.")
+		return nil
+	}
+
+	return p.Print(f.IOStreams.Out, newTokenList(tokens))
+}
+
+func newTokenRevokeCmd(f *cmdutil.Factory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke <id>...",
+		Short: "Revoke join tokens",
+		Long: `Revoke join tokens so that no more agents can register with them. Agents
+that already registered are not affected. Tokens are given by ID or as the
+full token.`,
+		Example: `  morpherctl agent token revoke abc123
+  morpherctl agent token revoke "$TOKEN"`,
+		Args:              cmdutil.UsageArgs(cobra.MinimumNArgs(1)),
+		ValidArgsFunction: completeTokenIDs(f),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return revokeTokens(cmd.Context(), f, args)
+		},
+	}
+}
+
+func revokeTokens(ctx context.Context, f *cmdutil.Factory, ids []string) error {
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
+
+	// Create controller client.
+	c, err := f.ControllerClient()
+	if err != nil {
+		return fmt.Errorf("failed to create controller client: %w", err)
+	}
+
+	// Tokens revoked before a failure are still printed.
+	var revoked revokedTokens
+	for _, id := range ids {
+		// Tokens are "<id>.<secret>"; never send the secret.
+		id, _, _ = strings.Cut(id, ".")
+
+		ctx, cancel := context.WithTimeout(ctx, c.GetTimeout())
+		err = c.RevokeJoinToken(ctx, id)
+		cancel()
+		if err != nil {
+			break
+		}
+		revoked = append(revoked, revokedToken{ID: id, Status: client.TokenRevoked})
+	}
+
+	if len(revoked) > 0 {
+		if err := p.Print(f.IOStreams.Out, revoked); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// completeTokenIDs completes the IDs of the active join tokens.
+func completeTokenIDs(f *cmdutil.Factory) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		c, err := f.ControllerClient()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), c.GetTimeout())
+		defer cancel()
+
+		tokens, err := c.ListJoinTokens(ctx)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		now := time.Now()
+		ids := make([]string, 0, len(tokens))
+		for _, token := range tokens {
+			if token.Status(now) == client.TokenActive {
+				ids = append(ids, token.ID+"\t"+token.Description)
+			}
+		}
+		return ids, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// tokenOutput is the printable form of a join token.
+type tokenOutput struct {
+	ID          string            `json:"id"`
+	Token       string            `json:"token,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Status      string            `json:"status"`
+	Uses        int               `json:"uses"`
+	MaxUses     int               `json:"max_uses"`
+	CreatedAt   time.Time         `json:"created_at"`
+	ExpiresAt   time.Time         `json:"expires_at,omitzero"`
+}
+
+// newTokenOutput returns the printable form of a join token and its status
+// at the given time.
+func newTokenOutput(token client.JoinToken, now time.Time) tokenOutput {
+	return tokenOutput{
+		ID:          token.ID,
+		Token:       token.Token,
+		Description: token.Description,
+		Labels:      token.Labels,
+		Status:      token.Status(now),
+		Uses:        token.Uses,
+		MaxUses:     token.MaxUses,
+		CreatedAt:   token.CreatedAt,
+		ExpiresAt:   token.ExpiresAt,
+	}
+}
+
+// tokenList is the printable form of a list of join tokens.
+type tokenList []tokenOutput
+
+// newTokenList returns the printable form of join tokens.
+func newTokenList(tokens []client.JoinToken) tokenList {
+	now := time.Now()
+	l := make(tokenList, 0, len(tokens))
+	for _, token := range tokens {
+		l = append(l, newTokenOutput(token, now))
+	}
+	return l
+}
+
+// TableHeader returns the column names of the token table.
+func (l tokenList) TableHeader(wide bool) []string {
+	header := []string{"ID", "STATUS", "USES", "EXPIRES", "LABELS"}
+	if wide {
+		header = append(header, "AGE", "DESCRIPTION")
+	}
+	return header
+}
+
+// TableRows returns a row for every token.
+func (l tokenList) TableRows(wide bool) [][]string {
+	now := time.Now()
+	rows := make([][]string, 0, len(l))
+	for _, token := range l {
+		row := []string{
+			token.ID,
+			token.Status,
+			formatUses(token),
+			formatExpiry(token.ExpiresAt, now),
+			printer.FormatLabels(token.Labels),
+		}
+		if wide {
+			row = append(row, printer.HumanAge(token.CreatedAt), printer.ValueOrNone(token.Description))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// Names returns the token IDs.
+func (l tokenList) Names() []string {
+	names := make([]string, 0, len(l))
+	for _, token := range l {
+		names = append(names, token.ID)
+	}
+	return names
+}
+
+// revokedToken is the outcome of revoking a join token.
+type revokedToken struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// revokedTokens is the printable form of the revoked join tokens.
+type revokedTokens []revokedToken
+
+// TableHeader returns the column names of the revoked tokens.
+func (l revokedTokens) TableHeader(_ bool) []string {
+	return []string{"ID", "STATUS"}
+}
+
+// TableRows returns a row for every revoked token.
+func (l revokedTokens) TableRows(_ bool) [][]string {
+	rows := make([][]string, 0, len(l))
+	for _, token := range l {
+		rows = append(rows, []string{token.ID, token.Status})
+	}
+	return rows
+}
+
+// Names returns the IDs of the revoked tokens.
+func (l revokedTokens) Names() []string {
+	names := make([]string, 0, len(l))
+	for _, token := range l {
+		names = append(names, token.ID)
+	}
+	return names
+}
+
+// createdToken is the printable form of a newly created join token.
+type createdToken tokenOutput
+
+// TableHeader returns no header since the token is printed as key-value pairs.
+func (o createdToken) TableHeader(_ bool) []string {
+	return nil
+}
+
+// TableRows returns the token as key-value pairs.
+func (o createdToken) TableRows(_ bool) [][]string {
+	return [][]string{
+		{"Token:", o.Token},
+		{"ID:", o.ID},
+		{"Expires:", o.ExpiresAt.Local().Format(time.RFC3339)},
+		{"Uses:", formatUses(tokenOutput(o))},
+		{"Labels:", printer.FormatLabels(o.Labels)},
+	}
+}
+
+// Names returns the token itself so that it can be captured by scripts.
+func (o createdToken) Names() []string {
+	return []string{o.Token}
+}
+
+// formatUses renders the uses of a token and its limit, if any.
+func formatUses(token tokenOutput) string {
+	if token.MaxUses == 0 {
+		return strconv.Itoa(token.Uses)
+	}
+	return fmt.Sprintf("%d/%d", token.Uses, token.MaxUses)
+}
+
+// formatExpiry renders the time left until t, or "expired".
+func formatExpiry(t, now time.Time) string {
+	switch {
+	case t.IsZero():
+		return "never"
+	case !now.Before(t):
+		return "expired"
+	default:
+		// HumanAge renders the time since a point as far in the past as t
+		// is in the future.
+		return printer.HumanAge(time.Now().Add(-t.Sub(now)))
+	}
+}
@@ -0,0 +1,352 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+	"github.com/morpher-vm/morpherctl/internal/install"
+	"github.com/morpher-vm/morpherctl/internal/printer"
+	"github.com/morpher-vm/morpherctl/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+// uninstallOptions holds the flags of the uninstall command.
+type uninstallOptions struct {
+	local       bool
+	agentID     string
+	force       bool
+	installPath string
+	root        string
+	ssh         sshOptions
+}
+
+func newUninstallCmd(f *cmdutil.Factory) *cobra.Command {
+	var opts uninstallOptions
+
+	cmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Uninstall the agent from hosts",
+		Long: `Uninstall the morpher agent, from this machine with --local or from remote
+hosts over SSH with --ssh.
+
+The service is stopped and disabled, the agent is deregistered from the
+controller, and the files written by agent install are removed: the binary,
+the configuration and the systemd unit. Directories of the install path are
+only removed once they are empty, so other files in them are kept. The agent
+of a host is found by its hostname unless --agent is given. Agents with active
+migrations are not uninstalled unless --force is given, which also continues
+when the service cannot be stopped and reports the failure as a warning.
+
+The report lists every path that was removed and every path that was left
+behind; the command fails if anything was left behind. The SSH flags are the
+same as for agent install. With --root, paths are below the given directory
+and the service is not stopped.`,
+		Example: `  morpherctl agent uninstall --local
+  morpherctl agent uninstall --ssh admin@hv1,hv2
+  morpherctl agent uninstall --local --agent agent-3 --force`,
+		Args: cmdutil.UsageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return uninstallAgent(cmd.Context(), f, opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.local, "local", false, "uninstall from this machine")
+	cmd.Flags().StringVar(&opts.agentID, "agent", "", "ID of the agent to deregister (default found by hostname)")
+	cmd.Flags().BoolVar(&opts.force, "force", false, "uninstall even if the agent has active migrations or the service cannot be stopped")
+	cmd.Flags().StringVar(&opts.installPath, "install-path", "", "directory the agent is installed in (default agent.install_path)")
+	cmd.Flags().StringVar(&opts.root, "root", "", "remove the files below this directory and do not stop the service")
+	opts.ssh.addFlags(cmd)
+	_ = cmd.MarkFlagDirname("root")
+	_ = cmd.RegisterFlagCompletionFunc("agent", completeAgentIDs(f))
+
+	return cmd
+}
+
+func uninstallAgent(ctx context.Context, f *cmdutil.Factory, opts uninstallOptions) error {
+	if err := opts.ssh.validate(opts.local); err != nil {
+		return err
+	}
+
+	var hosts []install.SSHHost
+	if opts.ssh.hosts != "" {
+		var err error
+		if hosts, err = opts.ssh.parseHosts(); err != nil {
+			return err
+		}
+		if opts.agentID != "" && len(hosts) > 1 {
+			return errdefs.Usage(errors.New("--agent cannot be used with more than one host"))
+		}
+	}
+
+	if opts.installPath == "" {
+		opts.installPath, _ = f.Config().GetString("agent.install_path")
+	}
+	if opts.installPath == "" {
+		opts.installPath = install.DefaultInstallPath
+	}
+
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
+
+	// Create controller client.
+	c, err := f.ControllerClient()
+	if err != nil {
+		return fmt.Errorf("failed to create controller client: %w", err)
+	}
+
+	listCtx, cancel := context.WithTimeout(ctx, c.GetTimeout())
+	agents, err := c.ListAgents(listCtx, client.ListAgentsOptions{})
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	u := uninstaller{client: c, agents: agents, opts: opts}
+
+	var reports uninstallReports
+	var errs []error
+	if opts.local {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("failed to get hostname: %w", err)
+		}
+		report, err := u.uninstall(ctx, hostname, install.LocalTarget{Root: opts.root})
+		reports, errs = append(reports, report), append(errs, err)
+	} else {
+		sshConfig, closeSSHConfig, err := opts.ssh.clientConfig()
+		if err != nil {
+			return err
+		}
+		defer closeSSHConfig()
+		reports = make(uninstallReports, len(hosts))
+		errs = install.ForEachSSH(ctx, hosts, sshConfig, opts.root, opts.ssh.parallel,
+			func(ctx context.Context, i int, target *install.SSHTarget) error {
+				hostname, _, _ := net.SplitHostPort(hosts[i].Addr)
+				report, err := u.uninstall(ctx, hostname, target)
+				report.Host = hosts[i].String()
+				reports[i] = report
+				return err
+			})
+		for i, err := range errs {
+			if reports[i].Host == "" {
+				reports[i].Host = hosts[i].String()
+			}
+			if err != nil {
+				reports[i].Error = err.Error()
+			}
+		}
+	}
+
+	if err := p.Print(f.IOStreams.Out, reports); err != nil {
+		return err
+	}
+	return uninstallError(reports, errs)
+}
+
+// uninstallError returns the error of a single host as is, so that its kind
+// determines the exit code, or else a summary of the failed hosts.
+func uninstallError(reports uninstallReports, errs []error) error {
+	failed, leftBehind := 0, 0
+	var last error
+	for i, report := range reports {
+		if errs[i] != nil {
+			failed++
+			last = errs[i]
+		}
+		leftBehind += len(report.LeftBehind)
+	}
+
+	switch {
+	case failed == 1 && len(reports) == 1:
+		return last
+	case failed > 0:
+		return fmt.Errorf("agent uninstallation failed on %d of %d hosts", failed, len(reports))
+	case leftBehind > 0:
+		return fmt.Errorf("%d paths were left behind", leftBehind)
+	default:
+		return nil
+	}
+}
+
+// uninstaller uninstalls the agent from hosts.
+type uninstaller struct {
+	client *client.Client
+	agents []client.Agent
+	opts   uninstallOptions
+}
+
+// uninstall stops the service, deregisters the agent and removes the files
+// of the agent on a host.
+func (u uninstaller) uninstall(ctx context.Context, hostname string, target install.Target) (uninstallReport, error) {
+	report := uninstallReport{Host: hostname}
+
+	agent, err := u.findAgent(hostname)
+	if err != nil {
+		return report, err
+	}
+	if agent != nil {
+		report.Agent = agent.ID
+		if agent.ActiveMigrations > 0 && !u.opts.force {
+			return report, errdefs.New(errdefs.KindConflict,
+				fmt.Errorf("agent %s has %d active migrations: use --force to uninstall anyway", agent.ID, agent.ActiveMigrations))
+		}
+	}
+
+	// Stop the agent first so that it does not register again.
+	if u.opts.root == "" {
+		err := install.StopService(ctx, target)
+		switch {
+		case err == nil:
+			report.ServiceStopped = true
+		case !u.opts.force:
+			return report, fmt.Errorf("failed to stop the agent: %w", err)
+		default:
+			report.Warnings = append(report.Warnings, fmt.Sprintf("failed to stop the agent: %v", err))
+		}
+	}
+
+	if agent != nil {
+		ctx, cancel := context.WithTimeout(ctx, u.client.GetTimeout())
+		err := u.client.DeregisterAgent(ctx, agent.ID, u.opts.force)
+		cancel()
+		if err != nil {
+			return report, err
+		}
+		report.Deregistered = true
+	}
+
+	result, err := install.RemoveFiles(ctx, target, u.opts.installPath, u.opts.root == "")
+	report.Removed = result.Removed
+	report.LeftBehind = result.LeftBehind
+	return report, err
+}
+
+// findAgent returns the agent selected with --agent or the agent registered
+// for the hostname, or nil if no agent is registered for the hostname.
+func (u uninstaller) findAgent(hostname string) (*client.Agent, error) {
+	var matches []client.Agent
+	for _, agent := range u.agents {
+		switch {
+		case u.opts.agentID != "":
+			if agent.ID == u.opts.agentID {
+				return &agent, nil
+			}
+		case matchesHostname(agent, hostname):
+			matches = append(matches, agent)
+		}
+	}
+
+	switch {
+	case u.opts.agentID != "":
+		return nil, errdefs.New(errdefs.KindNotFound, fmt.Errorf("agent %q not found", u.opts.agentID))
+	case len(matches) > 1:
+		return nil, errdefs.Usage(fmt.Errorf("several agents are registered for %s: select one with --agent", hostname))
+	case len(matches) == 1:
+		return &matches[0], nil
+	default:
+		return nil, nil
+	}
+}
+
+// matchesHostname reports whether the agent runs on the host, comparing its
+// hostname, short hostname and address.
+func matchesHostname(agent client.Agent, hostname string) bool {
+	short, _, _ := strings.Cut(agent.Hostname, ".")
+	address, _, err := net.SplitHostPort(agent.Address)
+	if err != nil {
+		address = agent.Address
+	}
+	return strings.EqualFold(agent.Hostname, hostname) || strings.EqualFold(short, hostname) || address == hostname
+}
+
+// uninstallReport is the outcome of an uninstallation on a host.
+type uninstallReport struct {
+	Host           string   `json:"host"`
+	Agent          string   `json:"agent,omitempty"`
+	ServiceStopped bool     `json:"service_stopped"`
+	Deregistered   bool     `json:"deregistered"`
+	Removed        []string `json:"removed"`
+	LeftBehind     []string `json:"left_behind"`
+	Warnings       []string `json:"warnings,omitempty"`
+	Error          string   `json:"error,omitempty"`
+}
+
+// uninstallReports is the printable form of the outcome of an uninstallation.
+type uninstallReports []uninstallReport
+
+// TableHeader returns no header since reports are printed as key-value pairs.
+func (l uninstallReports) TableHeader(_ bool) []string {
+	return nil
+}
+
+// TableRows returns the reports as key-value pairs separated by empty lines.
+func (l uninstallReports) TableRows(_ bool) [][]string {
+	var rows [][]string
+	for i, report := range l {
+		if i > 0 {
+			rows = append(rows, []string{""})
+		}
+
+		agent := printer.None
+		switch {
+		case report.Deregistered:
+			agent = report.Agent + " (deregistered)"
+		case report.Agent != "":
+			agent = report.Agent + " (still registered)"
+		}
+		service := "not stopped"
+		if report.ServiceStopped {
+			service = "stopped and disabled"
+		}
+
+		rows = append(rows,
+			[]string{"Host:", report.Host},
+			[]string{"Agent:", agent},
+			[]string{"Service:", service},
+		)
+		rows = append(rows, listRows("Removed:", report.Removed)...)
+		rows = append(rows, listRows("Left Behind:", report.LeftBehind)...)
+		if len(report.Warnings) > 0 {
+			rows = append(rows, listRows("Warnings:", report.Warnings)...)
+		}
+		if report.Error != "" {
+			rows = append(rows, []string{"Error:", report.Error})
+		}
+	}
+	return rows
+}
+
+// Names returns the hosts.
+func (l uninstallReports) Names() []string {
+	names := make([]string, 0, len(l))
+	for _, report := range l {
+		names = append(names, report.Host)
+	}
+	return names
+}
+
+// listRows renders values one per row, with the title on the first row.
+func listRows(title string, values []string) [][]string {
+	if len(values) == 0 {
+		return [][]string{{title, printer.None}}
+	}
+
+	rows := make([][]string, 0, len(values))
+	for i, value := range values {
+		if i == 0 {
+			rows = append(rows, []string{title, value})
+		} else {
+			rows = append(rows, []string{"", value})
+		}
+	}
+	return rows
+}
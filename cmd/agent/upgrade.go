@@ -0,0 +1,206 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+	"github.com/morpher-vm/morpherctl/internal/upgrade"
+	"github.com/morpher-vm/morpherctl/internal/version"
+	"github.com/morpher-vm/morpherctl/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+// upgradeStatusFile is the name of the upgrade status file in the
+// configuration directory.
+const upgradeStatusFile = "agent-upgrade.json"
+
+// upgradeOptions holds the flags of the upgrade command.
+type upgradeOptions struct {
+	to             string
+	selector       string
+	maxUnavailable int
+	maxFailures    int
+	drainTimeout   time.Duration
+	timeout        time.Duration
+	poll           time.Duration
+	resume         bool
+	statusFile     string
+}
+
+func newUpgradeCmd(f *cmdutil.Factory) *cobra.Command {
+	var opts upgradeOptions
+
+	cmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Upgrade agents to a new version",
+		Long: `Upgrade agents to a new version, a few at a time.
+
+Each agent is drained, upgraded by the controller, which replaces the agent
+binary and restarts it, and uncordoned once it reports the new version in a
+healthy heartbeat. Up to --max-unavailable agents are upgraded at a time. Once
+--max-failures upgrades have failed no further upgrades are started and the
+rollout is aborted; failed agents are left cordoned.
+
+Versions outside the agent versions supported by the controller are refused.
+The progress is kept in a status file, by default agent-upgrade.json in the
+configuration directory, and an interrupted, failed or aborted rollout
+continues with --resume, which retries failed agents.`,
+		Example: `  morpherctl agent upgrade --to v1.4.0 --selector site=dc1 --max-unavailable 2
+  morpherctl agent upgrade --resume`,
+		Args: cmdutil.UsageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return upgradeAgents(cmd.Context(), f, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.to, "to", "", "version to upgrade to")
+	cmd.Flags().StringVarP(&opts.selector, "selector", "l", "", "only upgrade agents whose labels match the selector")
+	cmd.Flags().IntVar(&opts.maxUnavailable, "max-unavailable", 1, "number of agents to upgrade at a time")
+	cmd.Flags().IntVar(&opts.maxFailures, "max-failures", 1, "number of failed upgrades that abort the rollout, 0 to never abort")
+	cmd.Flags().DurationVar(&opts.drainTimeout, "drain-timeout", 10*time.Minute, "maximum time to wait for the migrations of an agent to finish")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 5*time.Minute, "maximum time to wait for an upgraded agent to become healthy")
+	cmd.Flags().DurationVar(&opts.poll, "poll", 2*time.Second, "interval between checks of an agent")
+	cmd.Flags().BoolVar(&opts.resume, "resume", false, "continue the rollout of the status file")
+	cmd.Flags().StringVar(&opts.statusFile, "status-file", "", "file to keep the progress in (default agent-upgrade.json in the configuration directory)")
+	_ = cmd.MarkFlagFilename("status-file", "json")
+
+	return cmd
+}
+
+func (o upgradeOptions) validate() error {
+	switch {
+	case o.resume && (o.to != "" || o.selector != ""):
+		return errdefs.Usage(errors.New("--resume cannot be used with --to or --selector"))
+	case !o.resume && o.to == "":
+		return errdefs.Usage(errors.New("--to is required"))
+	case o.maxUnavailable < 1:
+		return errdefs.Usage(errors.New("--max-unavailable must be at least 1"))
+	case o.maxFailures < 0:
+		return errdefs.Usage(errors.New("--max-failures must not be negative"))
+	case o.drainTimeout <= 0 || o.timeout <= 0 || o.poll <= 0:
+		return errdefs.Usage(errors.New("--drain-timeout, --timeout and --poll must be positive"))
+	}
+	if _, err := client.ParseSelector(o.selector); err != nil {
+		return errdefs.Usage(err)
+	}
+	return nil
+}
+
+func upgradeAgents(ctx context.Context, f *cmdutil.Factory, opts upgradeOptions) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	if opts.statusFile == "" {
+		opts.statusFile = filepath.Join(f.Config().GetConfigDir(), upgradeStatusFile)
+	}
+
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
+
+	// Create controller client.
+	c, err := f.ControllerClient()
+	if err != nil {
+		return fmt.Errorf("failed to create controller client: %w", err)
+	}
+
+	status, err := loadUpgradeStatus(opts)
+	if err != nil {
+		return err
+	}
+	target := opts.to
+	if status != nil {
+		target = status.Version
+	}
+	if err := checkAgentSkew(ctx, c, target); err != nil {
+		return err
+	}
+
+	if status == nil {
+		listCtx, cancel := context.WithTimeout(ctx, c.GetTimeout())
+		agents, err := c.ListAgents(listCtx, client.ListAgentsOptions{Selector: opts.selector})
+		cancel()
+		if err != nil {
+			return err
+		}
+		if len(agents) == 0 {
+			return errdefs.New(errdefs.KindNotFound, errors.New("no agents match the selector"))
+		}
+		status = upgrade.NewStatus(opts.to, opts.selector, agents, opts.maxUnavailable, opts.maxFailures)
+	}
+
+	pending := status.Count(upgrade.AgentPending)
+	fmt.Fprintf(f.InfoOut(), "Upgrading %d of %d agents to %s, %d at a time\n",
+		pending, len(status.Agents), status.Version, status.MaxUnavailable)
+
+	rollout := &upgrade.Rollout{
+		Client:        c,
+		Status:        status,
+		StatusFile:    opts.statusFile,
+		DrainTimeout:  opts.drainTimeout,
+		HealthTimeout: opts.timeout,
+		Poll:          opts.poll,
+		Printer:       p,
+		Out:           f.IOStreams.Out,
+	}
+	if err := rollout.Run(ctx); err != nil {
+		fmt.Fprintf(f.IOStreams.ErrOut, "Continue the rollout with: morpherctl agent upgrade --resume --status-file %s\n", opts.statusFile)
+		return err
+	}
+
+	fmt.Fprintf(f.InfoOut(), "Upgraded %d agents to %s, %d already up to date\n",
+		status.Count(upgrade.AgentUpgraded), status.Version, status.Count(upgrade.AgentSkipped))
+	return nil
+}
+
+// loadUpgradeStatus returns the status to resume, or nil for a new rollout.
+// A new rollout is refused while another one is unfinished.
+func loadUpgradeStatus(opts upgradeOptions) (*upgrade.Status, error) {
+	status, err := upgrade.LoadStatus(opts.statusFile)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && opts.resume:
+		return nil, errdefs.New(errdefs.KindNotFound, fmt.Errorf("no upgrade to resume: %s does not exist", opts.statusFile))
+	case errors.Is(err, fs.ErrNotExist):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	case opts.resume && status.Finished():
+		return nil, errdefs.New(errdefs.KindConflict, fmt.Errorf("the upgrade to %s has already completed", status.Version))
+	case opts.resume:
+		status.Resume()
+		return status, nil
+	case !status.Finished():
+		return nil, errdefs.New(errdefs.KindConflict, fmt.Errorf(
+			"the upgrade to %s is %s: continue it with --resume or remove %s", status.Version, status.Phase, opts.statusFile))
+	default:
+		return nil, nil
+	}
+}
+
+// checkAgentSkew refuses versions the controller does not support.
+func checkAgentSkew(ctx context.Context, c *client.Client, target string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.GetTimeout())
+	defer cancel()
+
+	response, err := c.GetInfo(ctx)
+	if err != nil {
+		return err
+	}
+	if err := response.Err(); err != nil {
+		return fmt.Errorf("failed to get controller information: %w", err)
+	}
+
+	info := response.Result
+	if err := version.CheckAgentVersion(target, info.Version, info.MinAgentVersion, info.MaxAgentVersion); err != nil {
+		return errdefs.Usage(fmt.Errorf("refusing to upgrade to %s: %w", target, err))
+	}
+	return nil
+}
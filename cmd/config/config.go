@@ -1,23 +1,21 @@
 package config
 
 import (
-	"github.com/spf13/cobra"
-)
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
 
-var (
-	configFile string
+	"github.com/spf13/cobra"
 )
 
-var ConfigCmd = &cobra.Command{
-	Use:   "config",
-	Short: "Manage morpherctl configuration",
-	Long:  `Manage morpherctl configuration including initialization, setting, getting, and displaying config values.`,
-}
+// NewConfigCmd creates the config command group.
+func NewConfigCmd(f *cmdutil.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage morpherctl configuration",
+		Long:  `Manage morpherctl configuration including initialization, setting, getting, and displaying config values.`,
+	}
 
-func init() {
 	// Add subcommands.
-	ConfigCmd.AddCommand(initCmd, setCmd, getCmd, showCmd)
+	cmd.AddCommand(newInitCmd(f), newSetCmd(f), newGetCmd(f), newShowCmd(f))
 
-	// Set configuration file path.
-	ConfigCmd.PersistentFlags().StringVar(&configFile, "config", "", "config file (default is $HOME/.morpherctl/config.yaml)")
+	return cmd
 }
@@ -3,31 +3,34 @@ package config
 import (
 	"fmt"
 
-	"morpherctl/internal/config"
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
 
 	"github.com/spf13/cobra"
 )
 
-var getCmd = &cobra.Command{
-	Use:   "get [key]",
-	Short: "Get configuration value",
-	Long:  `Get a configuration value by key.`,
-	Args:  cobra.ExactArgs(1),
-	RunE: func(_ *cobra.Command, args []string) error {
-		return getConfig(args[0])
-	},
+func newGetCmd(f *cmdutil.Factory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get [key]",
+		Short: "Get configuration value",
+		Long:  `Get a configuration value by key.`,
+		Args:  cmdutil.UsageArgs(cobra.ExactArgs(1)),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return getConfig(f, args[0])
+		},
+	}
 }
 
-func getConfig(key string) error {
-	// Initialize configuration manager.
-	configMgr := config.NewManager(configFile)
+func getConfig(f *cmdutil.Factory, key string) error {
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
 
 	// Get configuration value.
-	value, err := configMgr.Get(key)
+	value, err := f.Config().Get(key)
 	if err != nil {
 		return fmt.Errorf("failed to get configuration value: %w", err)
 	}
 
-	fmt.Printf("%s = %v\n", key, value)
-	return nil
+	return p.Print(f.IOStreams.Out, configValue{Key: key, Value: value})
 }
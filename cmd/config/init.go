@@ -3,29 +3,36 @@ package config
 import (
 	"fmt"
 
-	"morpherctl/internal/config"
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
 
 	"github.com/spf13/cobra"
 )
 
-var initCmd = &cobra.Command{
-	Use:   "init",
-	Short: "Initialize configuration file",
-	Long:  `Initialize a new configuration file with default values.`,
-	RunE: func(_ *cobra.Command, _ []string) error {
-		return initConfig()
-	},
+func newInitCmd(f *cmdutil.Factory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "init",
+		Short: "Initialize configuration file",
+		Long:  `Initialize a new configuration file with default values.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return initConfig(f)
+		},
+	}
 }
 
-func initConfig() error {
-	// Initialize configuration manager.
-	configMgr := config.NewManager(configFile)
+func initConfig(f *cmdutil.Factory) error {
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
 
 	// Initialize configuration.
+	configMgr := f.Config()
 	if err := configMgr.Init(); err != nil {
 		return fmt.Errorf("failed to initialize configuration: %w", err)
 	}
 
-	fmt.Printf("Configuration file initialized: %s\n", configMgr.GetConfigFile())
-	return nil
+	return p.Print(f.IOStreams.Out, configResult{
+		Message: "Configuration file initialized:",
+		File:    configMgr.GetConfigFile(),
+	})
 }
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// configValue is the printable form of a single configuration value.
+type configValue struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+}
+
+// TableHeader returns the column names of the value table.
+func (v configValue) TableHeader(_ bool) []string {
+	return []string{"KEY", "VALUE"}
+}
+
+// TableRows returns the value as a single row.
+func (v configValue) TableRows(_ bool) [][]string {
+	return [][]string{{v.Key, fmt.Sprint(v.Value)}}
+}
+
+// Names returns the configuration key.
+func (v configValue) Names() []string {
+	return []string{v.Key}
+}
+
+// configSettings is the printable form of the whole configuration.
+type configSettings map[string]any
+
+// TableHeader returns the column names of the settings table.
+func (s configSettings) TableHeader(_ bool) []string {
+	return []string{"KEY", "VALUE"}
+}
+
+// TableRows returns a row for every setting, keyed by its dotted path.
+func (s configSettings) TableRows(_ bool) [][]string {
+	flat := s.flatten()
+	rows := make([][]string, 0, len(flat))
+	for _, key := range slices.Sorted(maps.Keys(flat)) {
+		rows = append(rows, []string{key, fmt.Sprint(flat[key])})
+	}
+	return rows
+}
+
+// Names returns the dotted path of every setting.
+func (s configSettings) Names() []string {
+	return slices.Sorted(maps.Keys(s.flatten()))
+}
+
+// flatten converts nested settings into dotted keys such as "controller.url".
+func (s configSettings) flatten() map[string]any {
+	flat := make(map[string]any)
+	var walk func(prefix string, settings map[string]any)
+	walk = func(prefix string, settings map[string]any) {
+		for key, value := range settings {
+			if nested, ok := value.(map[string]any); ok {
+				walk(prefix+key+".", nested)
+				continue
+			}
+			flat[prefix+key] = value
+		}
+	}
+	walk("", s)
+	return flat
+}
+
+// configResult is the printable result of a command that changed the configuration.
+type configResult struct {
+	Message string `json:"-"`
+	File    string `json:"file"`
+	Key     string `json:"key,omitempty"`
+	Value   string `json:"value,omitempty"`
+}
+
+// TableHeader returns no header since the result is a single message.
+func (r configResult) TableHeader(_ bool) []string {
+	return nil
+}
+
+// TableRows returns the result message.
+func (r configResult) TableRows(_ bool) [][]string {
+	if r.Key != "" {
+		return [][]string{{r.Message, r.Key + " = " + r.Value}}
+	}
+	return [][]string{{r.Message, r.File}}
+}
+
+// Names returns the changed key, or the configuration file if no key was changed.
+func (r configResult) Names() []string {
+	if r.Key != "" {
+		return []string{r.Key}
+	}
+	return []string{r.File}
+}
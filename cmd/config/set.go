@@ -3,30 +3,39 @@ package config
 import (
 	"fmt"
 
-	"morpherctl/internal/config"
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
 
 	"github.com/spf13/cobra"
 )
 
-var setCmd = &cobra.Command{
-	Use:   "set [key] [value]",
-	Short: "Set configuration value",
-	Long:  `Set a configuration key-value pair.`,
-	Args:  cobra.ExactArgs(2),
-	RunE: func(_ *cobra.Command, args []string) error {
-		return setConfig(args[0], args[1])
-	},
+func newSetCmd(f *cmdutil.Factory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set [key] [value]",
+		Short: "Set configuration value",
+		Long:  `Set a configuration key-value pair.`,
+		Args:  cmdutil.UsageArgs(cobra.ExactArgs(2)),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return setConfig(f, args[0], args[1])
+		},
+	}
 }
 
-func setConfig(key, value string) error {
-	// Initialize configuration manager.
-	configMgr := config.NewManager(configFile)
+func setConfig(f *cmdutil.Factory, key, value string) error {
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
 
 	// Set configuration value.
+	configMgr := f.Config()
 	if err := configMgr.Set(key, value); err != nil {
 		return fmt.Errorf("failed to set configuration value: %w", err)
 	}
 
-	fmt.Printf("Configuration updated: %s = %s\n", key, value)
-	return nil
+	return p.Print(f.IOStreams.Out, configResult{
+		Message: "Configuration updated:",
+		File:    configMgr.GetConfigFile(),
+		Key:     key,
+		Value:   value,
+	})
 }
@@ -3,33 +3,33 @@ package config
 import (
 	"fmt"
 
-	"morpherctl/internal/config"
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
 
 	"github.com/spf13/cobra"
 )
 
-var showCmd = &cobra.Command{
-	Use:   "show",
-	Short: "Show all configuration",
-	Long:  `Display all current configuration values.`,
-	RunE: func(_ *cobra.Command, _ []string) error {
-		return showConfig()
-	},
+func newShowCmd(f *cmdutil.Factory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show",
+		Short: "Show all configuration",
+		Long:  `Display all current configuration values.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return showConfig(f)
+		},
+	}
 }
 
-func showConfig() error {
-	// Initialize configuration manager.
-	configMgr := config.NewManager(configFile)
+func showConfig(f *cmdutil.Factory) error {
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
 
 	// Get all configuration values.
-	allConfig, err := configMgr.GetAll()
+	allConfig, err := f.Config().GetAll()
 	if err != nil {
 		return fmt.Errorf("failed to get all configuration values: %w", err)
 	}
 
-	fmt.Println("Current configuration:")
-	for key, value := range allConfig {
-		fmt.Printf("  %s = %v\n", key, value)
-	}
-	return nil
+	return p.Print(f.IOStreams.Out, configSettings(allConfig))
 }
@@ -1,15 +1,26 @@
 package controller
 
 import (
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+
 	"github.com/spf13/cobra"
 )
 
-var ControllerCmd = &cobra.Command{
-	Use:   "controller",
-	Short: "Manage morpher controller",
-	Long:  `Manage morpher controller including ping, status, and info operations.`,
-}
+// NewControllerCmd creates the controller command group.
+func NewControllerCmd(f *cmdutil.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "controller",
+		Short: "Manage morpher controller",
+		Long:  `Manage morpher controller including ping, status, and info operations.`,
+	}
+
+	cmd.AddCommand(
+		newPingCmd(f),
+		newStatusCmd(f),
+		newInfoCmd(f),
+		newWaitCmd(f),
+		newWatchCmd(f),
+	)
 
-func init() {
-	ControllerCmd.AddCommand(pingCmd, infoCmd)
+	return cmd
 }
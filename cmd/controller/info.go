@@ -3,58 +3,134 @@ package controller
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
-	"morpherctl/internal/controller"
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+	"github.com/morpher-vm/morpherctl/internal/printer"
+	"github.com/morpher-vm/morpherctl/pkg/client"
 
 	"github.com/spf13/cobra"
 )
 
-var infoCmd = &cobra.Command{
-	Use:   "info",
-	Short: "Get controller information",
-	Long:  `Get detailed information about the morpher controller.`,
-	RunE: func(_ *cobra.Command, _ []string) error {
-		return getControllerInfo()
-	},
+func newInfoCmd(f *cmdutil.Factory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "info",
+		Short: "Get controller information",
+		Long:  `Get detailed information about the morpher controller.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return getControllerInfo(cmd.Context(), f)
+		},
+	}
+}
+
+// osOutput is the printable form of the controller operating system.
+type osOutput struct {
+	Name            string `json:"name"`
+	PlatformName    string `json:"platform_name"`
+	PlatformVersion string `json:"platform_version"`
+	KernelVersion   string `json:"kernel_version"`
+}
+
+// infoOutput is the printable form of the controller information.
+type infoOutput struct {
+	Version         string          `json:"version"`
+	GitCommit       string          `json:"git_commit"`
+	APIVersions     []string        `json:"api_versions"`
+	Features        []string        `json:"features"`
+	ListenAddresses []string        `json:"listen_addresses"`
+	ConnectedAgents int             `json:"connected_agents"`
+	OS              osOutput        `json:"os"`
+	GoVersion       string          `json:"go_version"`
+	UpTime          client.Duration `json:"uptime"`
+	MinAgentVersion string          `json:"min_agent_version,omitempty"`
+	MaxAgentVersion string          `json:"max_agent_version,omitempty"`
+}
+
+// newInfoOutput returns the printable form of info.
+func newInfoOutput(info *client.InfoResult) infoOutput {
+	return infoOutput{
+		Version:         info.Version,
+		GitCommit:       info.GitCommit,
+		APIVersions:     info.APIVersions,
+		Features:        info.Features,
+		ListenAddresses: info.ListenAddresses,
+		ConnectedAgents: info.ConnectedAgents,
+		OS:              osOutput(info.OS),
+		GoVersion:       info.GoVersion,
+		UpTime:          info.UpTime,
+		MinAgentVersion: info.MinAgentVersion,
+		MaxAgentVersion: info.MaxAgentVersion,
+	}
 }
 
-func getControllerInfo() error {
+// TableHeader returns no header since info is printed as key-value pairs.
+func (o infoOutput) TableHeader(_ bool) []string {
+	return nil
+}
+
+// TableRows returns the controller information as key-value pairs.
+func (o infoOutput) TableRows(_ bool) [][]string {
+	osName := strings.TrimSpace(strings.Join([]string{o.OS.Name, o.OS.PlatformName, o.OS.PlatformVersion}, " "))
+	if o.OS.KernelVersion != "" {
+		osName += " (kernel " + o.OS.KernelVersion + ")"
+	}
+
+	return [][]string{
+		{"Version:", printer.ValueOrNone(o.Version)},
+		{"Git Commit:", printer.ValueOrNone(o.GitCommit)},
+		{"API Versions:", listOrNone(o.APIVersions)},
+		{"Features:", listOrNone(o.Features)},
+		{"Listen Addresses:", listOrNone(o.ListenAddresses)},
+		{"Connected Agents:", strconv.Itoa(o.ConnectedAgents)},
+		{"Agent Versions:", agentVersions(o.MinAgentVersion, o.MaxAgentVersion)},
+		{"OS:", printer.ValueOrNone(osName)},
+		{"Go Version:", printer.ValueOrNone(o.GoVersion)},
+		{"Uptime:", o.UpTime.String()},
+	}
+}
+
+// agentVersions renders the range of supported agent versions.
+func agentVersions(minVersion, maxVersion string) string {
+	if minVersion == "" && maxVersion == "" {
+		return printer.None
+	}
+	return printer.ValueOrNone(minVersion) + " - " + printer.ValueOrNone(maxVersion)
+}
+
+// Names returns the controller version.
+func (o infoOutput) Names() []string {
+	return []string{o.Version}
+}
+
+func getControllerInfo(ctx context.Context, f *cmdutil.Factory) error {
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
+
 	// Create controller client.
-	client, timeout, err := controller.CreateControllerClient()
+	c, err := f.ControllerClient()
 	if err != nil {
 		return fmt.Errorf("failed to create controller client: %w", err)
 	}
 
-	fmt.Printf("Getting controller information: %s\n", client.GetBaseURL())
-
 	// Get controller info.
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(ctx, c.GetTimeout())
 	defer cancel()
 
-	response, err := client.GetInfo(ctx)
+	response, err := c.GetInfo(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get controller info: %w", err)
+		return err
 	}
 
-	// Display response.
-	if response.Success {
-		fmt.Println("Successfully retrieved controller information")
-
-		// Display detailed information if available.
-		if response.Result != nil {
-			fmt.Println("\nController Details:")
-			fmt.Printf("  OS: %s %s (%s)\n",
-				response.Result.OS.Name,
-				response.Result.OS.PlatformVersion,
-				response.Result.OS.KernelVersion)
-			fmt.Printf("  Go Version: %s\n", response.Result.GoVersion)
-			fmt.Printf("  Uptime: %s\n", response.Result.UpTime)
-		} else {
-			fmt.Println("  No detailed information available")
-		}
-	} else {
-		fmt.Printf("Failed to get controller information: %d\n", response.StatusCode)
+	if err := response.Err(); err != nil {
+		return fmt.Errorf("failed to get controller information: %w", err)
 	}
 
-	return nil
+	return p.Print(f.IOStreams.Out, newInfoOutput(response.Result))
+}
+
+func listOrNone(values []string) string {
+	return printer.ValueOrNone(strings.Join(values, ", "))
 }
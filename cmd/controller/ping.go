@@ -3,48 +3,258 @@ package controller
 import (
 	"context"
 	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
 
-	"morpherctl/internal/controller"
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+	"github.com/morpher-vm/morpherctl/pkg/client"
 
 	"github.com/spf13/cobra"
 )
 
-var pingCmd = &cobra.Command{
-	Use:   "ping",
-	Short: "Ping the controller",
-	Long:  `Send a ping request to the morpher controller to check connectivity.`,
-	RunE: func(_ *cobra.Command, _ []string) error {
-		return pingController()
-	},
+// pingOptions holds the flags of the ping command.
+type pingOptions struct {
+	count    int
+	interval time.Duration
+	all      bool
 }
 
-func pingController() error {
+func newPingCmd(f *cmdutil.Factory) *cobra.Command {
+	var opts pingOptions
+
+	cmd := &cobra.Command{
+		Use:   "ping",
+		Short: "Ping the controller",
+		Long: `Send ping requests to the morpher controller to check connectivity.
+
+A line is printed for every probe, followed by a summary of packet loss and
+the round-trip latency measured on the client. Use --count 0 to ping
+continuously until interrupted with Ctrl-C.
+
+With several controller endpoints configured in controller.urls, requests
+fail over between them. Use --all to ping every endpoint separately and
+report one summary line per endpoint.
+
+The command fails if no probe received a successful response; with --all it
+fails if any endpoint did not answer. The exit code reflects the cause of the
+last failure.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return pingController(cmd.Context(), f, opts)
+		},
+	}
+
+	cmd.Flags().IntVarP(&opts.count, "count", "c", 1, "number of ping requests to send (0 pings until interrupted)")
+	cmd.Flags().DurationVarP(&opts.interval, "interval", "i", time.Second, "time to wait between ping requests")
+	cmd.Flags().BoolVar(&opts.all, "all", false, "ping every configured controller endpoint separately")
+
+	return cmd
+}
+
+// pingOutput is the printable result of a series of ping probes.
+type pingOutput struct {
+	Controller string `json:"controller"`
+	client.PingSummary
+}
+
+// TableHeader returns the column names of the ping summary.
+func (o pingOutput) TableHeader(wide bool) []string {
+	header := []string{"SENT", "RECEIVED", "LOSS", "MIN", "AVG", "MAX", "P50", "P99", "STDDEV"}
+	if wide {
+		header = append([]string{"CONTROLLER"}, header...)
+	}
+	return header
+}
+
+// TableRows returns the ping summary as a single row.
+func (o pingOutput) TableRows(wide bool) [][]string {
+	row := []string{strconv.Itoa(o.Sent), strconv.Itoa(o.Received), fmt.Sprintf("%.1f%%", o.Loss)}
+	for _, latency := range []client.Duration{o.Min, o.Avg, o.Max, o.P50, o.P99, o.StdDev} {
+		// Latency is meaningless when no probe was answered.
+		if o.Received == 0 {
+			row = append(row, "-")
+			continue
+		}
+		row = append(row, formatLatency(latency))
+	}
+	if wide {
+		row = append([]string{o.Controller}, row...)
+	}
+	return [][]string{row}
+}
+
+// Names returns the pinged controller.
+func (o pingOutput) Names() []string {
+	return []string{o.Controller}
+}
+
+// pingOutputs are the ping summaries of several controller endpoints.
+type pingOutputs []pingOutput
+
+// TableHeader returns the column names of the per-endpoint summaries.
+func (o pingOutputs) TableHeader(_ bool) []string {
+	return pingOutput{}.TableHeader(true)
+}
+
+// TableRows returns one row per endpoint.
+func (o pingOutputs) TableRows(_ bool) [][]string {
+	rows := make([][]string, 0, len(o))
+	for _, output := range o {
+		rows = append(rows, output.TableRows(true)...)
+	}
+	return rows
+}
+
+// Names returns the pinged endpoints.
+func (o pingOutputs) Names() []string {
+	names := make([]string, 0, len(o))
+	for _, output := range o {
+		names = append(names, output.Controller)
+	}
+	return names
+}
+
+func pingController(ctx context.Context, f *cmdutil.Factory, opts pingOptions) error {
+	if opts.count < 0 {
+		return errdefs.Usage(fmt.Errorf("invalid count %d: must be zero or greater", opts.count))
+	}
+	if opts.interval <= 0 {
+		return errdefs.Usage(fmt.Errorf("invalid interval %s: must be greater than zero", opts.interval))
+	}
+
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
+
 	// Create controller client.
-	client, timeout, err := controller.CreateControllerClient()
+	c, err := f.ControllerClient()
 	if err != nil {
 		return fmt.Errorf("failed to create controller client: %w", err)
 	}
 
-	fmt.Printf("Sending ping request to controller: %s\n", client.GetBaseURL())
+	// Probe lines are progress; keep stdout clean for structured output.
+	info := f.InfoOut()
 
-	// Send ping request.
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+	if !opts.all {
+		fmt.Fprintf(info, "Sending ping requests to controller: %s\n", c.GetBaseURL())
+		output, lastErr := pingEndpoint(ctx, c, opts, info, "")
+		fmt.Fprintln(info)
 
-	response, err := client.Ping(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to connect to controller: %w", err)
+		if err := p.Print(f.IOStreams.Out, output); err != nil {
+			return err
+		}
+		if output.Sent > 0 && output.Received == 0 {
+			return fmt.Errorf("no successful response from controller: %w", lastErr)
+		}
+		return nil
 	}
 
-	// Display response.
-	if response.Success {
-		fmt.Println("Controller responded successfully")
-		if response.ResponseTime != "" {
-			fmt.Printf("Response time: %v\n", response.ResponseTime)
+	var outputs pingOutputs
+	var failed []string
+	var lastErr error
+	for _, endpoint := range c.Endpoints() {
+		if ctx.Err() != nil {
+			break
+		}
+
+		fmt.Fprintf(info, "Sending ping requests to controller: %s\n", endpoint)
+		output, err := pingEndpoint(ctx, c.ForEndpoint(endpoint), opts, info, endpoint+" ")
+		outputs = append(outputs, output)
+		if output.Sent > 0 && output.Received == 0 {
+			failed = append(failed, endpoint)
+			lastErr = err
 		}
-	} else {
-		fmt.Printf("Controller responded but with unexpected status code: %d\n", response.StatusCode)
 	}
+	fmt.Fprintln(info)
 
+	if err := p.Print(f.IOStreams.Out, outputs); err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("no successful response from %s: %w", strings.Join(failed, ", "), lastErr)
+	}
 	return nil
 }
+
+// pingEndpoint probes the client's controller and returns the summary and the
+// last failure. Probe lines are written to info, starting with prefix.
+func pingEndpoint(ctx context.Context, c *client.Client, opts pingOptions, info io.Writer, prefix string) (pingOutput, error) {
+	var stats client.PingStats
+	var lastErr error
+	for seq := 1; opts.count == 0 || seq <= opts.count; seq++ {
+		if seq > 1 && !sleepContext(ctx, opts.interval) {
+			break
+		}
+
+		// Stop probing on Ctrl-C and still print the summary.
+		latency, response, err := probe(ctx, c)
+		if ctx.Err() != nil {
+			break
+		}
+
+		switch {
+		case err != nil:
+			stats.AddFailure()
+			lastErr = err
+			fmt.Fprintf(info, "%sseq=%d error: %v\n", prefix, seq, err)
+		case !response.Success:
+			stats.AddFailure()
+			lastErr = response.Err()
+			fmt.Fprintf(info, "%sseq=%d status=%d time=%s (%v)\n",
+				prefix, seq, response.StatusCode, formatLatency(client.Duration(latency)), lastErr)
+		default:
+			stats.AddSuccess(latency)
+			fmt.Fprintln(info, prefix+formatProbe(seq, response, latency))
+		}
+	}
+
+	return pingOutput{
+		Controller:  c.GetBaseURL(),
+		PingSummary: stats.Summary(),
+	}, lastErr
+}
+
+// probe sends a single ping request and measures its round-trip latency.
+func probe(ctx context.Context, c *client.Client) (time.Duration, *client.PingResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.GetTimeout())
+	defer cancel()
+
+	start := time.Now()
+	response, err := c.Ping(ctx)
+	latency := time.Since(start)
+	if err != nil {
+		return latency, nil, fmt.Errorf("failed to connect to controller: %w", err)
+	}
+
+	return latency, response, nil
+}
+
+// formatProbe renders the line printed for a successful probe.
+func formatProbe(seq int, response *client.PingResponse, latency time.Duration) string {
+	line := fmt.Sprintf("seq=%d status=%d time=%s", seq, response.StatusCode, formatLatency(client.Duration(latency)))
+	if response.ResponseTime != "" {
+		line += " server_time=" + response.ResponseTime
+	}
+	return line
+}
+
+// sleepContext waits for the given duration and reports whether the context is still active.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// formatLatency renders a latency in milliseconds with microsecond precision.
+func formatLatency(d client.Duration) string {
+	return fmt.Sprintf("%.3fms", float64(d)/float64(time.Millisecond))
+}
@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+	"github.com/morpher-vm/morpherctl/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+func newStatusCmd(f *cmdutil.Factory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show controller health",
+		Long: `Show the health of the morpher controller and each of its components.
+
+The exit code reflects the overall health so the command can be used in
+monitoring scripts:
+  0   healthy
+  10  degraded
+  11  unhealthy or unknown
+
+Failures to reach the controller use the common exit codes, for example 4 for
+network errors and 5 for authentication errors.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return getControllerStatus(cmd.Context(), f)
+		},
+	}
+}
+
+// componentOutput is the printable form of the health of a component.
+type componentOutput struct {
+	Name      string    `json:"name"`
+	State     string    `json:"state"`
+	LastCheck time.Time `json:"last_check"`
+	Message   string    `json:"message"`
+}
+
+// statusOutput is the printable form of the controller health.
+type statusOutput struct {
+	Controller string            `json:"controller"`
+	Overall    string            `json:"overall"`
+	Components []componentOutput `json:"components"`
+}
+
+// TableHeader returns the column names of the component table.
+func (o statusOutput) TableHeader(wide bool) []string {
+	if wide {
+		return []string{"COMPONENT", "STATE", "LAST CHECK", "AGE", "MESSAGE"}
+	}
+	return []string{"COMPONENT", "STATE", "AGE", "MESSAGE"}
+}
+
+// TableRows returns a row for every component.
+func (o statusOutput) TableRows(wide bool) [][]string {
+	rows := make([][]string, 0, len(o.Components))
+	for _, component := range o.Components {
+		if wide {
+			rows = append(rows, []string{component.Name, component.State,
+				formatCheckTime(component.LastCheck), formatCheckAge(component.LastCheck), component.Message})
+			continue
+		}
+		rows = append(rows, []string{component.Name, component.State,
+			formatCheckAge(component.LastCheck), component.Message})
+	}
+	return rows
+}
+
+// Names returns the component names.
+func (o statusOutput) Names() []string {
+	names := make([]string, 0, len(o.Components))
+	for _, component := range o.Components {
+		names = append(names, component.Name)
+	}
+	return names
+}
+
+func getControllerStatus(ctx context.Context, f *cmdutil.Factory) error {
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
+
+	// Create controller client.
+	c, err := f.ControllerClient()
+	if err != nil {
+		return fmt.Errorf("failed to create controller client: %w", err)
+	}
+
+	// Get controller status.
+	ctx, cancel := context.WithTimeout(ctx, c.GetTimeout())
+	defer cancel()
+
+	response, err := c.GetStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := response.Err(); err != nil {
+		return fmt.Errorf("failed to get controller status: %w", err)
+	}
+
+	overall := response.Result.Overall()
+	fmt.Fprintf(f.InfoOut(), "Controller %s is %s\n\n", c.GetBaseURL(), overall)
+
+	output := statusOutput{
+		Controller: c.GetBaseURL(),
+		Overall:    overall,
+		Components: make([]componentOutput, 0, len(response.Result.Components)),
+	}
+	for _, component := range response.Result.Components {
+		output.Components = append(output.Components, componentOutput(component))
+	}
+	if err := p.Print(f.IOStreams.Out, output); err != nil {
+		return err
+	}
+
+	switch overall {
+	case client.HealthHealthy:
+		return nil
+	case client.HealthDegraded:
+		return cmdutil.NewExitError(cmdutil.ExitDegraded, errors.New("controller is degraded"))
+	default:
+		return cmdutil.NewExitError(cmdutil.ExitUnhealthy, fmt.Errorf("controller is %s", overall))
+	}
+}
+
+// formatCheckTime renders the time of a component check.
+func formatCheckTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Local().Format(time.RFC3339)
+}
+
+// formatCheckAge renders how long ago a component was checked.
+func formatCheckAge(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return time.Since(t).Truncate(time.Second).String()
+}
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+	"github.com/morpher-vm/morpherctl/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+// waitOptions holds the flags of the wait command.
+type waitOptions struct {
+	condition string
+	timeout   time.Duration
+	poll      time.Duration
+}
+
+func newWaitCmd(f *cmdutil.Factory) *cobra.Command {
+	var opts waitOptions
+
+	cmd := &cobra.Command{
+		Use:   "wait",
+		Short: "Wait for the controller to reach a condition",
+		Long: `Block until the morpher controller reaches the given condition.
+
+Supported conditions:
+  healthy       the controller answers ping requests
+  ready         the controller and all of its components report healthy
+  version>=X    the controller runs version X or newer
+
+Progress is reported on stderr. The command exits with 0 once the condition
+is met and with 9 if the timeout expires first.`,
+		Example: `  morpherctl controller wait --for=healthy --timeout 5m
+  morpherctl controller wait --for=version>=v1.4.0 --poll 5s`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return waitForController(cmd.Context(), f, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.condition, "for", client.WaitHealthy, "condition to wait for: healthy, ready or version>=X")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 5*time.Minute, "maximum time to wait")
+	cmd.Flags().DurationVar(&opts.poll, "poll", 2*time.Second, "interval between checks")
+
+	return cmd
+}
+
+// waitOutput is the printable result of a successful wait.
+type waitOutput struct {
+	Controller string          `json:"controller"`
+	Condition  string          `json:"condition"`
+	Attempts   int             `json:"attempts"`
+	Elapsed    client.Duration `json:"elapsed"`
+}
+
+// TableHeader returns the column names of the wait result.
+func (o waitOutput) TableHeader(wide bool) []string {
+	if wide {
+		return []string{"CONTROLLER", "CONDITION", "ATTEMPTS", "ELAPSED"}
+	}
+	return []string{"CONDITION", "ATTEMPTS", "ELAPSED"}
+}
+
+// TableRows returns the wait result as a single row.
+func (o waitOutput) TableRows(wide bool) [][]string {
+	row := []string{o.Condition, strconv.Itoa(o.Attempts), o.Elapsed.String()}
+	if wide {
+		row = append([]string{o.Controller}, row...)
+	}
+	return [][]string{row}
+}
+
+// Names returns the condition that was met.
+func (o waitOutput) Names() []string {
+	return []string{o.Condition}
+}
+
+func waitForController(ctx context.Context, f *cmdutil.Factory, opts waitOptions) error {
+	cond, err := client.ParseWaitCondition(opts.condition)
+	if err != nil {
+		return errdefs.Usage(err)
+	}
+	if opts.poll <= 0 {
+		return errdefs.Usage(fmt.Errorf("invalid poll interval %s: must be greater than zero", opts.poll))
+	}
+
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
+
+	// Create controller client.
+	c, err := f.ControllerClient()
+	if err != nil {
+		return fmt.Errorf("failed to create controller client: %w", err)
+	}
+
+	errOut := f.IOStreams.ErrOut
+	fmt.Fprintf(errOut, "Waiting for controller %s to be %s (timeout %s)\n", c.GetBaseURL(), cond, opts.timeout)
+
+	ctx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
+
+	start := time.Now()
+	attempts := 1
+	err = c.WaitFor(ctx, cond, opts.poll, func(attempt int, state string, err error) {
+		attempts = attempt + 1
+		elapsed := time.Since(start).Truncate(time.Second)
+		if err != nil {
+			fmt.Fprintf(errOut, "[%s] attempt %d: %v\n", elapsed, attempt, err)
+			return
+		}
+		fmt.Fprintf(errOut, "[%s] attempt %d: %s\n", elapsed, attempt, state)
+	})
+	if errors.Is(err, client.ErrWaitTimeout) {
+		return errdefs.New(errdefs.KindTimeout, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	return p.Print(f.IOStreams.Out, waitOutput{
+		Controller: c.GetBaseURL(),
+		Condition:  cond.String(),
+		Attempts:   attempts,
+		Elapsed:    client.Duration(time.Since(start).Truncate(time.Millisecond)),
+	})
+}
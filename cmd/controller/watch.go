@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+	"github.com/morpher-vm/morpherctl/internal/watch"
+
+	"github.com/spf13/cobra"
+)
+
+// watchOptions holds the flags of the watch command.
+type watchOptions struct {
+	interval      time.Duration
+	hook          string
+	webhook       string
+	notifyTimeout time.Duration
+}
+
+func newWatchCmd(f *cmdutil.Factory) *cobra.Command {
+	var opts watchOptions
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Continuously monitor the controller",
+		Long: `Poll the morpher controller ping, status and info endpoints and print a line
+whenever its state changes: going up or down, a health change, a version
+change, or an uptime reset that indicates a restart.
+
+Every transition can run a hook command and post a JSON payload to a webhook.
+The hook receives the payload on stdin and the MORPHER_EVENT, MORPHER_MESSAGE
+and MORPHER_CONTROLLER environment variables. Polling waits for the
+notifications, which are stopped after --notify-timeout. Press Ctrl-C to stop
+watching.`,
+		Example: `  morpherctl controller watch --interval 5s
+  morpherctl controller watch --hook 'notify-send "$MORPHER_MESSAGE"'
+  morpherctl controller watch --webhook https://hooks.example.com/morpher`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return watchController(cmd.Context(), f, opts)
+		},
+	}
+
+	cmd.Flags().DurationVar(&opts.interval, "interval", 10*time.Second, "interval between polls")
+	cmd.Flags().StringVar(&opts.hook, "hook", "", "shell command to run on every state transition")
+	cmd.Flags().StringVar(&opts.webhook, "webhook", "", "URL to POST a JSON payload to on every state transition")
+	cmd.Flags().DurationVar(&opts.notifyTimeout, "notify-timeout", watch.DefaultNotifyTimeout, "maximum time for the hook and the webhook of a transition")
+
+	return cmd
+}
+
+func watchController(ctx context.Context, f *cmdutil.Factory, opts watchOptions) error {
+	if opts.interval <= 0 {
+		return errdefs.Usage(fmt.Errorf("invalid interval %s: must be greater than zero", opts.interval))
+	}
+	if opts.notifyTimeout <= 0 {
+		return errdefs.Usage(fmt.Errorf("invalid notify timeout %s: must be greater than zero", opts.notifyTimeout))
+	}
+	if opts.webhook != "" {
+		if err := watch.ValidateWebhookURL(opts.webhook); err != nil {
+			return errdefs.Usage(err)
+		}
+	}
+
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
+
+	// Create controller client.
+	c, err := f.ControllerClient()
+	if err != nil {
+		return fmt.Errorf("failed to create controller client: %w", err)
+	}
+
+	watcher := &watch.Watcher{
+		Client:        c,
+		Interval:      opts.interval,
+		Printer:       p,
+		Out:           f.IOStreams.Out,
+		ErrOut:        f.IOStreams.ErrOut,
+		NotifyTimeout: opts.notifyTimeout,
+	}
+	if opts.hook != "" {
+		watcher.Notifiers = append(watcher.Notifiers, &watch.HookNotifier{
+			Command: opts.hook,
+			Out:     f.IOStreams.ErrOut,
+		})
+	}
+	if opts.webhook != "" {
+		watcher.Notifiers = append(watcher.Notifiers, &watch.WebhookNotifier{URL: opts.webhook})
+	}
+
+	fmt.Fprintf(f.IOStreams.ErrOut, "Watching controller %s every %s\n", c.GetBaseURL(), opts.interval)
+
+	return watcher.Run(ctx)
+}
@@ -0,0 +1,22 @@
+package dev
+
+import (
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+
+	"github.com/spf13/cobra"
+)
+
+// NewDevCmd creates the dev command group.
+func NewDevCmd(f *cmdutil.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dev",
+		Short: "Tools for development and demos",
+		Long:  `Tools for developing against and demoing morpherctl without real infrastructure.`,
+	}
+
+	cmd.AddCommand(
+		newFakeControllerCmd(f),
+	)
+
+	return cmd
+}
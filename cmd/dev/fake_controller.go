@@ -0,0 +1,125 @@
+package dev
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+	"github.com/morpher-vm/morpherctl/pkg/client"
+	"github.com/morpher-vm/morpherctl/pkg/fakecontroller"
+
+	"github.com/spf13/cobra"
+)
+
+// fakeControllerOptions holds the flags of the fake-controller command.
+type fakeControllerOptions struct {
+	listen      string
+	token       string
+	empty       bool
+	seed        int64
+	latency     time.Duration
+	jitter      time.Duration
+	errorRate   float64
+	errorStatus int
+	dropRate    float64
+	faultPaths  []string
+}
+
+func newFakeControllerCmd(f *cmdutil.Factory) *cobra.Command {
+	var opts fakeControllerOptions
+
+	cmd := &cobra.Command{
+		Use:   "fake-controller",
+		Short: "Run an in-memory fake controller",
+		Long: `Run an in-memory fake morpher controller that serves the ping, info, health,
+agent and migration endpoints from demo data.
+
+Faults can be injected with the flags below or changed while the server runs
+through the control endpoint:
+
+  curl -X PUT localhost:9000/_fake/faults -d '{"error_rate": 0.2, "latency": "300ms"}'
+
+Press Ctrl-C to stop the server.`,
+		Example: `  morpherctl dev fake-controller --listen :9000
+  morpherctl dev fake-controller --token secret --latency 200ms --jitter 100ms
+  morpherctl dev fake-controller --error-rate 0.1 --drop-rate 0.05 --fault-path /agents`,
+		Args: cmdutil.UsageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runFakeController(cmd.Context(), f, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.listen, "listen", ":9000", "address to listen on")
+	cmd.Flags().StringVar(&opts.token, "token", "", "bearer token required on every request")
+	cmd.Flags().BoolVar(&opts.empty, "empty", false, "start without demo agents and migrations")
+	cmd.Flags().Int64Var(&opts.seed, "seed", 0, "seed for random fault injection (0 picks a random seed)")
+	cmd.Flags().DurationVar(&opts.latency, "latency", 0, "delay added to every response")
+	cmd.Flags().DurationVar(&opts.jitter, "jitter", 0, "maximum random delay added to every response")
+	cmd.Flags().Float64Var(&opts.errorRate, "error-rate", 0, "fraction of requests answered with --error-status (0 to 1)")
+	cmd.Flags().IntVar(&opts.errorStatus, "error-status", http.StatusInternalServerError, "status code of injected errors")
+	cmd.Flags().Float64Var(&opts.dropRate, "drop-rate", 0, "fraction of connections closed without a response (0 to 1)")
+	cmd.Flags().StringSliceVar(&opts.faultPaths, "fault-path", nil, "only inject faults into paths with this prefix (repeatable)")
+
+	return cmd
+}
+
+func runFakeController(ctx context.Context, f *cmdutil.Factory, opts fakeControllerOptions) error {
+	faults := fakecontroller.Faults{
+		Latency:     client.Duration(opts.latency),
+		Jitter:      client.Duration(opts.jitter),
+		ErrorRate:   opts.errorRate,
+		ErrorStatus: opts.errorStatus,
+		DropRate:    opts.dropRate,
+		Paths:       opts.faultPaths,
+	}
+	if err := faults.Validate(); err != nil {
+		return errdefs.Usage(err)
+	}
+
+	fakeOpts := []fakecontroller.Option{
+		fakecontroller.WithToken(opts.token),
+		fakecontroller.WithFaults(faults),
+	}
+	if !opts.empty {
+		fakeOpts = append(fakeOpts, fakecontroller.WithDemoData())
+	}
+	if opts.seed != 0 {
+		fakeOpts = append(fakeOpts, fakecontroller.WithSeed(opts.seed))
+	}
+
+	listener, err := net.Listen("tcp", opts.listen)
+	if err != nil {
+		return errdefs.New(errdefs.KindNetwork, fmt.Errorf("failed to listen on %s: %w", opts.listen, err))
+	}
+
+	server := &http.Server{
+		Handler:           fakecontroller.New(fakeOpts...),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(listener)
+	}()
+
+	fmt.Fprintf(f.IOStreams.ErrOut, "Fake controller listening on http://%s\n", listener.Addr())
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("fake controller stopped: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to stop fake controller: %w", err)
+	}
+
+	return nil
+}
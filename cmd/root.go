@@ -1,36 +1,101 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 
-	"morpherctl/cmd/completion"
-	"morpherctl/cmd/config"
-	"morpherctl/cmd/controller"
-	"morpherctl/cmd/version"
+	"github.com/morpher-vm/morpherctl/cmd/agent"
+	"github.com/morpher-vm/morpherctl/cmd/completion"
+	"github.com/morpher-vm/morpherctl/cmd/config"
+	"github.com/morpher-vm/morpherctl/cmd/controller"
+	"github.com/morpher-vm/morpherctl/cmd/dev"
+	"github.com/morpher-vm/morpherctl/cmd/version"
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
 )
 
-var rootCmd = &cobra.Command{
-	Use:           "morpherctl",
-	Short:         "CLI tool for managing morpher agents that perform VM migrations",
-	Long:          `A command-line tool for managing morpher agents that perform VM migrations.`,
-	SilenceErrors: true,
-	SilenceUsage:  true,
+// NewRootCmd creates the morpherctl command tree using the given factory.
+func NewRootCmd(f *cmdutil.Factory) *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "morpherctl",
+		Short: "CLI tool for managing morpher agents that perform VM migrations",
+		Long: `A command-line tool for managing morpher agents that perform VM migrations.
+
+Exit codes:
+  0    success
+  1    unclassified error
+  2    invalid usage (unknown command, bad flag or argument)
+  3    invalid or unreadable configuration
+  4    network error reaching the controller
+  5    authentication or authorization failure
+  6    resource not found
+  7    conflict with the current state
+  8    controller server error
+  9    timeout
+  10   controller degraded (controller status)
+  11   controller unhealthy (controller status)
+  130  interrupted`,
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	rootCmd.SetIn(f.IOStreams.In)
+	rootCmd.SetOut(f.IOStreams.Out)
+	rootCmd.SetErr(f.IOStreams.ErrOut)
+
+	cmdutil.AddGlobalFlags(rootCmd, f)
+	rootCmd.SetFlagErrorFunc(cmdutil.FlagErrorFunc)
+
+	rootCmd.AddCommand(version.NewVersionCmd(f))
+	rootCmd.AddCommand(config.NewConfigCmd(f))
+	rootCmd.AddCommand(controller.NewControllerCmd(f))
+	rootCmd.AddCommand(agent.NewAgentCmd(f))
+	rootCmd.AddCommand(dev.NewDevCmd(f))
+	rootCmd.AddCommand(completion.NewCompletionCmd(f))
+
+	return rootCmd
 }
 
-func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		rootCmd.PrintErrf("Error: %v\n", err)
-		os.Exit(1)
+// Run executes morpherctl in-process with the given arguments and returns the
+// exit code. Errors are reported on the factory's error stream.
+func Run(ctx context.Context, f *cmdutil.Factory, args []string) int {
+	rootCmd := NewRootCmd(f)
+	rootCmd.SetArgs(args)
+
+	err := rootCmd.ExecuteContext(ctx)
+	if err == nil {
+		return cmdutil.ExitOK
+	}
+
+	// Cobra reports unknown subcommands with a plain error.
+	if strings.HasPrefix(err.Error(), "unknown command") {
+		err = errdefs.Usage(err)
+	}
+
+	// Interruptions were already reported by the signal handler.
+	if !errors.Is(err, context.Canceled) {
+		fmt.Fprintf(f.IOStreams.ErrOut, "Error: %v\n", err)
+		if info := f.RequestInfo(); info != "" {
+			fmt.Fprintf(f.IOStreams.ErrOut, "(%s)\n", info)
+		}
 	}
+	return cmdutil.ExitCode(err)
 }
 
-func init() {
-	rootCmd.CompletionOptions.DisableDefaultCmd = true
+// Execute runs morpherctl with the process arguments and exits.
+func Execute() {
+	streams := cmdutil.SystemIOStreams()
+
+	// Cancel in-flight operations on Ctrl-C instead of killing the process.
+	ctx, cancel := cmdutil.SignalContext(context.Background(), streams.ErrOut)
+	code := Run(ctx, cmdutil.NewFactory(streams), os.Args[1:])
+	cancel()
 
-	rootCmd.AddCommand(version.VersionCmd)
-	rootCmd.AddCommand(config.ConfigCmd)
-	rootCmd.AddCommand(controller.ControllerCmd)
-	rootCmd.AddCommand(completion.CompletionCmd)
+	os.Exit(code)
 }
@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+	"github.com/morpher-vm/morpherctl/internal/version"
+	"github.com/morpher-vm/morpherctl/pkg/client"
+	"github.com/morpher-vm/morpherctl/pkg/fakecontroller"
+)
+
+func TestRun(t *testing.T) {
+	server := httptest.NewServer(fakecontroller.New(
+		fakecontroller.WithToken("secret"),
+		fakecontroller.WithInfo(client.InfoResult{Version: "v1.3.0", GoVersion: "go1.24.5", UpTime: client.Duration(time.Hour)}),
+	))
+	defer server.Close()
+
+	newFactory := func(token string) (*cmdutil.Factory, func() string, func() string) {
+		streams, _, out, errOut := cmdutil.NewTestIOStreams()
+		f := cmdutil.NewFactory(streams)
+		f.ConfigFile = filepath.Join(t.TempDir(), "config.yaml")
+		f.ControllerClient = func() (*client.Client, error) {
+			return client.NewClient(server.URL, time.Second, token), nil
+		}
+		return f, out.String, errOut.String
+	}
+
+	tests := []struct {
+		name         string
+		args         []string
+		token        string
+		expectedCode int
+		expectedOut  string
+		expectedErr  string
+	}{
+		{
+			name:         "should print controller info with jsonpath",
+			args:         []string{"controller", "info", "-o", "jsonpath={.version} {.uptime}"},
+			token:        "secret",
+			expectedCode: cmdutil.ExitOK,
+			expectedOut:  "v1.3.0 1h0m0s",
+		},
+		{
+			name:         "should ping the injected controller",
+			args:         []string{"controller", "ping", "-o", "jsonpath={.received}"},
+			token:        "secret",
+			expectedCode: cmdutil.ExitOK,
+			expectedOut:  "1",
+		},
+		{
+			name:         "should report controller status",
+			args:         []string{"controller", "status", "-o", "jsonpath={.overall}"},
+			token:        "secret",
+			expectedCode: cmdutil.ExitOK,
+			expectedOut:  "healthy",
+		},
+		{
+			name:         "should report authentication failures",
+			args:         []string{"controller", "status"},
+			token:        "wrong",
+			expectedCode: cmdutil.ExitAuth,
+			expectedErr:  "Error: failed to get controller status: controller returned 401 Unauthorized: invalid or missing token\n",
+		},
+		{
+			name:         "should report unknown commands as usage errors",
+			args:         []string{"bogus"},
+			expectedCode: cmdutil.ExitUsage,
+			expectedErr:  "Error: unknown command \"bogus\" for \"morpherctl\"\n",
+		},
+		{
+			name:         "should report config errors",
+			args:         []string{"config", "show"},
+			expectedCode: cmdutil.ExitConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, out, errOut := newFactory(tt.token)
+
+			code := Run(context.Background(), f, tt.args)
+
+			assert.Equal(t, tt.expectedCode, code, "stderr: %s", errOut())
+			assert.Equal(t, tt.expectedOut, out())
+			if tt.expectedErr != "" {
+				assert.Equal(t, tt.expectedErr, errOut())
+			}
+		})
+	}
+}
+
+func TestRun_UnreachableController(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	tests := []struct {
+		name        string
+		args        []string
+		expectedErr string
+	}{
+		{name: "should name the status request once", args: []string{"controller", "status"}, expectedErr: "failed to get controller status: "},
+		{name: "should name the info request once", args: []string{"controller", "info"}, expectedErr: "failed to get controller info: "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			streams, _, _, errOut := cmdutil.NewTestIOStreams()
+			f := cmdutil.NewFactory(streams)
+			f.ConfigFile = filepath.Join(t.TempDir(), "config.yaml")
+			f.ControllerClient = func() (*client.Client, error) {
+				return client.NewClient(server.URL, time.Second, ""), nil
+			}
+
+			assert.NotEqual(t, cmdutil.ExitOK, Run(context.Background(), f, tt.args))
+			assert.True(t, strings.HasPrefix(errOut.String(), "Error: "+tt.expectedErr), errOut.String())
+			assert.Equal(t, 1, strings.Count(errOut.String(), "failed to get controller"), errOut.String())
+		})
+	}
+}
+
+func TestRun_Config(t *testing.T) {
+	streams, _, out, _ := cmdutil.NewTestIOStreams()
+	f := cmdutil.NewFactory(streams)
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+
+	require.Equal(t, cmdutil.ExitOK, Run(context.Background(), f, []string{"config", "init", "--config", configFile}))
+	require.Equal(t, cmdutil.ExitOK, Run(context.Background(), f, []string{"config", "set", "controller.url", "http://controller:9000", "--config", configFile}))
+
+	out.Reset()
+	require.Equal(t, cmdutil.ExitOK, Run(context.Background(), f, []string{"config", "get", "controller.url", "--config", configFile, "-o", "go-template={{.value}}"}))
+	assert.Equal(t, "http://controller:9000", out.String())
+}
+
+func TestRun_RecordReplay(t *testing.T) {
+	server := httptest.NewServer(fakecontroller.New(fakecontroller.WithToken("secret")))
+	defer server.Close()
+
+	streams, _, out, errOut := cmdutil.NewTestIOStreams()
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.yaml")
+	session := filepath.Join(dir, "session.json")
+	run := func(args ...string) int {
+		out.Reset()
+		errOut.Reset()
+		return Run(context.Background(), cmdutil.NewFactory(streams), append(args, "--config", configFile))
+	}
+
+	require.Equal(t, cmdutil.ExitOK, run("config", "init"))
+	require.Equal(t, cmdutil.ExitOK, run("config", "set", "controller.url", server.URL))
+	require.Equal(t, cmdutil.ExitOK, run("config", "set", "auth.token", "secret"))
+
+	require.Equal(t, cmdutil.ExitOK, run("controller", "info", "-o", "jsonpath={.version}", "--record", session), errOut.String())
+	assert.Equal(t, "v1.0.0", out.String())
+	server.Close()
+
+	require.Equal(t, cmdutil.ExitOK, run("controller", "info", "-o", "jsonpath={.version}", "--replay", session), errOut.String())
+	assert.Equal(t, "v1.0.0", out.String())
+
+	assert.Equal(t, cmdutil.ExitUsage, run("controller", "status", "--replay", session))
+	assert.Contains(t, errOut.String(), "no recorded response for GET /health")
+	assert.Contains(t, errOut.String(), "- GET /info\n+ GET /health")
+
+	assert.Equal(t, cmdutil.ExitUsage, run("controller", "info", "--record", session, "--replay", session))
+}
+
+func TestRun_PingAll(t *testing.T) {
+	healthy := httptest.NewServer(fakecontroller.New())
+	defer healthy.Close()
+	dropping := httptest.NewServer(fakecontroller.New(fakecontroller.WithFaults(fakecontroller.Faults{DropRate: 1})))
+	defer dropping.Close()
+
+	streams, _, out, errOut := cmdutil.NewTestIOStreams()
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	run := func(args ...string) int {
+		out.Reset()
+		errOut.Reset()
+		return Run(context.Background(), cmdutil.NewFactory(streams), append(args, "--config", configFile))
+	}
+
+	require.Equal(t, cmdutil.ExitOK, run("config", "init"))
+	require.Equal(t, cmdutil.ExitOK, run("config", "set", "controller.urls", dropping.URL+","+healthy.URL))
+
+	// Requests fail over to the healthy endpoint.
+	require.Equal(t, cmdutil.ExitOK, run("controller", "ping", "-o", "jsonpath={.received}"), errOut.String())
+	assert.Equal(t, "1", out.String())
+
+	// Every endpoint is reported separately.
+	code := run("controller", "ping", "--all", "-o", "jsonpath={range [*]}{.controller}={.received} {end}")
+	assert.Equal(t, cmdutil.ExitNetwork, code)
+	assert.Equal(t, dropping.URL+"=0 "+healthy.URL+"=1 ", out.String())
+	assert.Contains(t, errOut.String(), "Error: no successful response from "+dropping.URL)
+}
+
+func TestRun_VersionSkew(t *testing.T) {
+	server := httptest.NewServer(fakecontroller.New(fakecontroller.WithInfo(client.InfoResult{Version: "v1.0.2", GitCommit: "abc123"})))
+	defer server.Close()
+
+	defer func(v string) { version.Version = v }(version.Version)
+	version.Version = "v1.3.0"
+
+	streams, _, out, errOut := cmdutil.NewTestIOStreams()
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	run := func(args ...string) int {
+		out.Reset()
+		errOut.Reset()
+		return Run(context.Background(), cmdutil.NewFactory(streams), append(args, "--config", configFile))
+	}
+
+	require.Equal(t, cmdutil.ExitOK, run("config", "init"))
+	require.Equal(t, cmdutil.ExitOK, run("config", "set", "controller.url", server.URL))
+
+	t.Run("should warn once per invocation", func(t *testing.T) {
+		require.Equal(t, cmdutil.ExitOK, run("controller", "ping", "-c", "3", "-i", "1ms"))
+		assert.Equal(t, 1, strings.Count(errOut.String(), "Warning: morpherctl v1.3.0 supports controllers v1.2 - v1.4, but the controller runs v1.0.2"))
+	})
+
+	t.Run("should not contact the controller without --server", func(t *testing.T) {
+		require.Equal(t, cmdutil.ExitOK, run("version", "-o", "json"))
+		assert.NotContains(t, out.String(), "server")
+		assert.Empty(t, errOut.String())
+	})
+
+	t.Run("should print server version and compatibility", func(t *testing.T) {
+		require.Equal(t, cmdutil.ExitOK, run("version", "--server", "-o",
+			"jsonpath={.version} {.server.version} {.server.git_commit} {.compatibility.status}"))
+		assert.Equal(t, "v1.3.0 v1.0.2 abc123 unsupported", out.String())
+	})
+
+	t.Run("should print client version when controller is unreachable", func(t *testing.T) {
+		server.Close()
+		assert.Equal(t, cmdutil.ExitNetwork, run("version", "--server", "-o", "jsonpath={.version}"))
+		assert.Equal(t, "v1.3.0", out.String())
+	})
+}
+
+func TestRun_TraceContext(t *testing.T) {
+	var headers http.Header
+	fake := fakecontroller.New(fakecontroller.WithToken("secret"))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		fake.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	streams, _, _, errOut := cmdutil.NewTestIOStreams()
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	newFactory := func() *cmdutil.Factory {
+		errOut.Reset()
+		return cmdutil.NewFactory(streams)
+	}
+
+	require.Equal(t, cmdutil.ExitOK, Run(context.Background(), newFactory(), []string{"config", "init", "--config", configFile}))
+	require.Equal(t, cmdutil.ExitOK, Run(context.Background(), newFactory(), []string{"config", "set", "controller.url", server.URL, "--config", configFile}))
+
+	t.Run("should send identification headers and print request ids on errors", func(t *testing.T) {
+		traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+		f := newFactory()
+
+		code := Run(context.Background(), f, []string{"controller", "status", "--trace-id", traceID, "--config", configFile})
+
+		assert.Equal(t, cmdutil.ExitAuth, code)
+		assert.Equal(t, f.RequestID, headers.Get("X-Request-Id"))
+		assert.Regexp(t, "^00-"+traceID+"-[0-9a-f]{16}-01$", headers.Get("traceparent"))
+		assert.Regexp(t, `^morpherctl/\S+ \(\S+; \w+/\w+\)$`, headers.Get("User-Agent"))
+		assert.Contains(t, errOut.String(), "(request ID "+f.RequestID+", trace ID "+traceID+")\n")
+	})
+
+	t.Run("should reject invalid trace id", func(t *testing.T) {
+		code := Run(context.Background(), newFactory(), []string{"controller", "status", "--trace-id", "abc", "--config", configFile})
+
+		assert.Equal(t, cmdutil.ExitUsage, code)
+		assert.Contains(t, errOut.String(), "invalid --trace-id")
+	})
+
+	t.Run("should not print request ids without controller requests", func(t *testing.T) {
+		code := Run(context.Background(), newFactory(), []string{"bogus"})
+
+		assert.Equal(t, cmdutil.ExitUsage, code)
+		assert.NotContains(t, errOut.String(), "request ID")
+	})
+}
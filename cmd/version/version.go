@@ -1,21 +1,139 @@
 package version
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
 
-	"morpherctl/internal/version"
+	"github.com/morpher-vm/morpherctl/internal/cmdutil"
+	"github.com/morpher-vm/morpherctl/internal/version"
 )
 
-// VersionCmd represents the version command.
-var VersionCmd = &cobra.Command{
-	Use:   "version",
-	Short: "Print the version number",
-	Long:  `Print the version number of morpherctl.`,
-	Run:   runVersion,
+// versionOptions holds the flags of the version command.
+type versionOptions struct {
+	server bool
 }
 
-func runVersion(_ *cobra.Command, _ []string) {
-	fmt.Print(version.GetVersionInfo())
+// NewVersionCmd creates the version command.
+func NewVersionCmd(f *cmdutil.Factory) *cobra.Command {
+	var opts versionOptions
+
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print the version number",
+		Long: `Print the version number of morpherctl.
+
+With --server the version of the controller is shown as well, together with
+whether the two versions are within the skew supported by this build.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runVersion(cmd.Context(), f, opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.server, "server", false, "also print the controller version and compatibility")
+
+	return cmd
+}
+
+// serverVersion is the version information reported by the controller.
+type serverVersion struct {
+	Controller  string   `json:"controller"`
+	Version     string   `json:"version"`
+	GitCommit   string   `json:"git_commit"`
+	APIVersions []string `json:"api_versions,omitempty"`
+}
+
+// versionOutput is the printable form of the build information.
+type versionOutput struct {
+	version.Info
+	Server        *serverVersion         `json:"server,omitempty"`
+	Compatibility *version.Compatibility `json:"compatibility,omitempty"`
+}
+
+// TableHeader returns no header since version is printed as key-value pairs.
+func (o versionOutput) TableHeader(_ bool) []string {
+	return nil
+}
+
+// TableRows returns the build information as key-value pairs.
+func (o versionOutput) TableRows(_ bool) [][]string {
+	rows := [][]string{
+		{"Version:", o.Version},
+		{"Git Commit:", o.GitCommit},
+		{"Build Date:", o.BuildDate},
+	}
+	if o.Server != nil {
+		rows = append(rows,
+			[]string{"Controller:", o.Server.Controller},
+			[]string{"Server Version:", o.Server.Version},
+			[]string{"Server Git Commit:", o.Server.GitCommit},
+		)
+	}
+	if o.Compatibility != nil {
+		compat := o.Compatibility.Status
+		if o.Compatibility.SupportedRange != "" {
+			compat += fmt.Sprintf(" (supported controllers: %s)", o.Compatibility.SupportedRange)
+		}
+		rows = append(rows, []string{"Compatibility:", compat})
+	}
+	return rows
+}
+
+// Names returns the version.
+func (o versionOutput) Names() []string {
+	return []string{o.Version}
+}
+
+func runVersion(ctx context.Context, f *cmdutil.Factory, opts versionOptions) error {
+	p, err := f.NewPrinter()
+	if err != nil {
+		return err
+	}
+
+	output := versionOutput{Info: version.Get()}
+	if !opts.server {
+		return p.Print(f.IOStreams.Out, output)
+	}
+
+	server, err := getServerVersion(ctx, f)
+	if err != nil {
+		// Still show the client version when the controller cannot be reached.
+		if printErr := p.Print(f.IOStreams.Out, output); printErr != nil {
+			return printErr
+		}
+		return err
+	}
+
+	compat := version.CheckCompatibility(output.Version, server.Version)
+	output.Server = server
+	output.Compatibility = &compat
+
+	return p.Print(f.IOStreams.Out, output)
+}
+
+// getServerVersion retrieves the version of the configured controller.
+func getServerVersion(ctx context.Context, f *cmdutil.Factory) (*serverVersion, error) {
+	c, err := f.ControllerClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create controller client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.GetTimeout())
+	defer cancel()
+
+	response, err := c.GetInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := response.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get controller version: %w", err)
+	}
+
+	return &serverVersion{
+		Controller:  c.GetBaseURL(),
+		Version:     response.Result.Version,
+		GitCommit:   response.Result.GitCommit,
+		APIVersions: response.Result.APIVersions,
+	}, nil
 }
@@ -0,0 +1,129 @@
+// Package agentlog streams agent logs through the controller, reconnecting
+// when a followed stream breaks, and formats log entries as text.
+package agentlog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/pkg/client"
+)
+
+// Default reconnect delays.
+const (
+	DefaultBackoff    = time.Second
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// Follower streams the logs of an agent. A followed stream that breaks or is
+// closed by the controller is reopened from the last entry received, so no
+// entry is passed twice.
+type Follower struct {
+	Client *client.Client
+	Agent  string
+	// Since only passes entries logged at or after the time.
+	Since  time.Time
+	Follow bool
+	// Grep, if set, only passes entries whose text matches.
+	Grep *regexp.Regexp
+	// Backoff is the delay before the first reconnect; it doubles up to
+	// MaxBackoff and starts over once entries are received again.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// OnReconnect, if set, is called with the error that ended the stream
+	// before waiting to reconnect.
+	OnReconnect func(err error, delay time.Duration)
+}
+
+// Run calls fn for every entry until the logs end, which only happens
+// without Follow, fn returns an error, the stream fails permanently or ctx
+// is done.
+func (f *Follower) Run(ctx context.Context, fn func(client.LogEntry) error) error {
+	backoff := cmpOr(f.Backoff, DefaultBackoff)
+	maxBackoff := max(cmpOr(f.MaxBackoff, DefaultMaxBackoff), backoff)
+
+	// last is the time of the last entry received and atLast the number of
+	// entries received with that time, which are skipped after reconnecting.
+	var last time.Time
+	atLast := 0
+	since := f.Since
+	delay := backoff
+	for {
+		var fnErr error
+		skip := atLast
+		received := false
+		err := f.Client.StreamAgentLogs(ctx, f.Agent, client.AgentLogOptions{Since: since, Follow: f.Follow}, func(entry client.LogEntry) error {
+			switch {
+			case entry.Time.Before(last):
+				return nil
+			case entry.Time.Equal(last) && skip > 0:
+				skip--
+				return nil
+			case entry.Time.Equal(last):
+				atLast++
+			default:
+				last, atLast = entry.Time, 1
+			}
+			received = true
+
+			if f.Grep != nil && !f.Grep.MatchString(Formatter{}.Format(entry)) {
+				return nil
+			}
+			fnErr = fn(entry)
+			return fnErr
+		})
+		switch {
+		case err == nil:
+			return nil
+		case fnErr != nil:
+			return fnErr
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case !f.Follow || !retryable(err):
+			return err
+		}
+
+		if received {
+			delay = backoff
+		}
+		if f.OnReconnect != nil {
+			f.OnReconnect(err, delay)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		delay = min(delay*2, maxBackoff)
+		if !last.IsZero() {
+			since = last
+		}
+	}
+}
+
+// retryable reports whether reopening a stream that ended with err may
+// succeed. Requests the controller rejects and entries that cannot be
+// parsed fail the same way again.
+func retryable(err error) bool {
+	var statusErr *client.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError
+	}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return !errors.As(err, &syntaxErr) && !errors.As(err, &typeErr)
+}
+
+// cmpOr returns d, or def if d is not positive.
+func cmpOr(d, def time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return def
+}
@@ -0,0 +1,95 @@
+package agentlog
+
+import (
+	"context"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+	"github.com/morpher-vm/morpherctl/pkg/client"
+	"github.com/morpher-vm/morpherctl/pkg/fakecontroller"
+)
+
+func newFollower(t *testing.T, fake *fakecontroller.Server) *Follower {
+	t.Helper()
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return &Follower{
+		Client:     client.NewClient(server.URL, time.Second, ""),
+		Agent:      "agent-a",
+		Backoff:    5 * time.Millisecond,
+		MaxBackoff: 20 * time.Millisecond,
+	}
+}
+
+func TestFollower_Run(t *testing.T) {
+	t0 := time.Now().Truncate(time.Second)
+	entries := []client.LogEntry{
+		{Time: t0, Level: client.LogInfo, Message: "first"},
+		{Time: t0, Level: client.LogWarn, Message: "second", Fields: map[string]string{"disk": "sda"}},
+	}
+
+	t.Run("should reconnect without repeating entries", func(t *testing.T) {
+		fake := fakecontroller.New(fakecontroller.WithAgents(client.Agent{ID: "agent-a"}), fakecontroller.WithAgentLogs("agent-a", entries...))
+		follower := newFollower(t, fake)
+		follower.Follow = true
+		reconnects := 0
+		follower.OnReconnect = func(err error, delay time.Duration) {
+			assert.ErrorIs(t, err, client.ErrLogStreamClosed)
+			assert.Equal(t, 5*time.Millisecond, delay)
+			reconnects++
+			// Logged at the same time as the entries already received.
+			fake.AppendAgentLog("agent-a", client.LogEntry{Time: t0, Level: client.LogInfo, Message: "third"})
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		var messages []string
+		err := follower.Run(ctx, func(entry client.LogEntry) error {
+			messages = append(messages, entry.Message)
+			switch len(messages) {
+			case 2:
+				fake.CloseLogStreams()
+			case 3:
+				cancel()
+			}
+			return nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, []string{"first", "second", "third"}, messages)
+		assert.Equal(t, 1, reconnects)
+	})
+
+	t.Run("should only pass matching entries", func(t *testing.T) {
+		fake := fakecontroller.New(fakecontroller.WithAgents(client.Agent{ID: "agent-a"}), fakecontroller.WithAgentLogs("agent-a", entries...))
+		follower := newFollower(t, fake)
+		follower.Grep = regexp.MustCompile(`WARN|disk=sdb`)
+
+		var messages []string
+		err := follower.Run(context.Background(), func(entry client.LogEntry) error {
+			messages = append(messages, entry.Message)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"second"}, messages)
+	})
+
+	t.Run("should not reconnect after permanent errors", func(t *testing.T) {
+		follower := newFollower(t, fakecontroller.New())
+		follower.Follow = true
+		follower.OnReconnect = func(err error, _ time.Duration) {
+			t.Errorf("unexpected reconnect after %v", err)
+		}
+
+		err := follower.Run(context.Background(), func(client.LogEntry) error { return nil })
+		assert.Equal(t, errdefs.KindNotFound, errdefs.KindOf(err))
+	})
+}
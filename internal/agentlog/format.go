@@ -0,0 +1,69 @@
+package agentlog
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/morpher-vm/morpherctl/pkg/client"
+)
+
+// TimeFormat is the format of entry times.
+const TimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// ANSI escape sequences for the colors of log levels.
+const (
+	colorReset  = "\x1b[0m"
+	colorGray   = "\x1b[90m"
+	colorCyan   = "\x1b[36m"
+	colorYellow = "\x1b[33m"
+	colorRed    = "\x1b[31m"
+)
+
+var levelColors = map[string]string{
+	client.LogDebug: colorGray,
+	client.LogInfo:  colorCyan,
+	client.LogWarn:  colorYellow,
+	client.LogError: colorRed,
+}
+
+// Formatter formats log entries as single lines of text: the time, the level
+// and the message followed by the fields as sorted key=value pairs.
+type Formatter struct {
+	// Color highlights the level with ANSI colors.
+	Color bool
+}
+
+// Format returns the entry as a line without a trailing newline.
+func (f Formatter) Format(entry client.LogEntry) string {
+	var b strings.Builder
+	b.WriteString(entry.Time.Format(TimeFormat))
+	b.WriteByte(' ')
+
+	level := strings.ToUpper(entry.Level)
+	padding := strings.Repeat(" ", max(5-len(level), 0))
+	if color, ok := levelColors[entry.Level]; ok && f.Color {
+		level = color + level + colorReset
+	}
+	b.WriteString(level)
+	b.WriteString(padding)
+	b.WriteByte(' ')
+	b.WriteString(entry.Message)
+
+	for _, key := range slices.Sorted(maps.Keys(entry.Fields)) {
+		b.WriteByte(' ')
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(quoteValue(entry.Fields[key]))
+	}
+	return b.String()
+}
+
+// quoteValue quotes field values that would otherwise be ambiguous.
+func quoteValue(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		return strconv.Quote(value)
+	}
+	return value
+}
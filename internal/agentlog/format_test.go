@@ -0,0 +1,50 @@
+package agentlog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/morpher-vm/morpherctl/pkg/client"
+)
+
+func TestFormatter_Format(t *testing.T) {
+	entry := client.LogEntry{
+		Time:    time.Date(2026, 10, 18, 10, 0, 0, 250_000_000, time.UTC),
+		Level:   client.LogWarn,
+		Message: "disk copy throttled",
+		Fields:  map[string]string{"migration": "mig-2", "reason": "io limit", "empty": ""},
+	}
+
+	tests := []struct {
+		name      string
+		formatter Formatter
+		entry     client.LogEntry
+		expected  string
+	}{
+		{
+			name:     "should print time, level, message and sorted fields",
+			entry:    entry,
+			expected: `2026-10-18T10:00:00.250Z WARN  disk copy throttled empty="" migration=mig-2 reason="io limit"`,
+		},
+		{
+			name:      "should color the level",
+			formatter: Formatter{Color: true},
+			entry:     client.LogEntry{Time: entry.Time, Level: client.LogError, Message: "failed"},
+			expected:  "2026-10-18T10:00:00.250Z \x1b[31mERROR\x1b[0m failed",
+		},
+		{
+			name:      "should keep unknown levels uncolored",
+			formatter: Formatter{Color: true},
+			entry:     client.LogEntry{Time: entry.Time, Level: "trace", Message: "step"},
+			expected:  "2026-10-18T10:00:00.250Z TRACE step",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.formatter.Format(tt.entry))
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package cmdutil
+
+import (
+	"context"
+	"errors"
+
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes returned by morpherctl. They are part of the public interface
+// and must not be renumbered.
+const (
+	ExitOK        = 0
+	ExitFailure   = 1
+	ExitUsage     = 2
+	ExitConfig    = 3
+	ExitNetwork   = 4
+	ExitAuth      = 5
+	ExitNotFound  = 6
+	ExitConflict  = 7
+	ExitServer    = 8
+	ExitTimeout   = 9
+	ExitDegraded  = 10
+	ExitUnhealthy = 11
+
+	// ExitInterrupted follows the shell convention of 128 + SIGINT.
+	ExitInterrupted = 130
+)
+
+// ExitError is an error that terminates the command with a specific exit code.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+// NewExitError wraps err so that the command exits with the given code.
+func NewExitError(code int, err error) *ExitError {
+	return &ExitError{Code: code, Err: err}
+}
+
+// Error returns the message of the wrapped error.
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the process exit code for an error returned by a command.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return ExitInterrupted
+	}
+
+	switch errdefs.KindOf(err) {
+	case errdefs.KindUsage:
+		return ExitUsage
+	case errdefs.KindConfig:
+		return ExitConfig
+	case errdefs.KindNetwork:
+		return ExitNetwork
+	case errdefs.KindAuth:
+		return ExitAuth
+	case errdefs.KindNotFound:
+		return ExitNotFound
+	case errdefs.KindConflict:
+		return ExitConflict
+	case errdefs.KindServer:
+		return ExitServer
+	case errdefs.KindTimeout:
+		return ExitTimeout
+	default:
+		return ExitFailure
+	}
+}
+
+// UsageArgs wraps a positional argument validator so that its errors are
+// reported as usage errors.
+func UsageArgs(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		return errdefs.Usage(validate(cmd, args))
+	}
+}
+
+// FlagErrorFunc reports flag parsing errors as usage errors.
+func FlagErrorFunc(_ *cobra.Command, err error) error {
+	return errdefs.Usage(err)
+}
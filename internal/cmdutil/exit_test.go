@@ -0,0 +1,62 @@
+package cmdutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{
+			name:     "should return success for nil error",
+			err:      nil,
+			expected: ExitOK,
+		},
+		{
+			name:     "should return failure for plain error",
+			err:      errors.New("boom"),
+			expected: ExitFailure,
+		},
+		{
+			name:     "should return code of exit error",
+			err:      NewExitError(ExitUnhealthy, errors.New("unhealthy")),
+			expected: ExitUnhealthy,
+		},
+		{
+			name:     "should return code of wrapped exit error",
+			err:      fmt.Errorf("status: %w", NewExitError(ExitDegraded, errors.New("degraded"))),
+			expected: ExitDegraded,
+		},
+		{
+			name:     "should return usage for usage errors",
+			err:      errdefs.Usage(errors.New("invalid count")),
+			expected: ExitUsage,
+		},
+		{
+			name:     "should return timeout for deadline errors",
+			err:      fmt.Errorf("failed to ping: %w", context.DeadlineExceeded),
+			expected: ExitTimeout,
+		},
+		{
+			name:     "should return interrupted for cancelled context",
+			err:      fmt.Errorf("failed to ping: %w", context.Canceled),
+			expected: ExitInterrupted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ExitCode(tt.err))
+		})
+	}
+}
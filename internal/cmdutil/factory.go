@@ -0,0 +1,224 @@
+package cmdutil
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/internal/config"
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+	"github.com/morpher-vm/morpherctl/internal/printer"
+	"github.com/morpher-vm/morpherctl/internal/recorder"
+	"github.com/morpher-vm/morpherctl/internal/version"
+	"github.com/morpher-vm/morpherctl/pkg/client"
+)
+
+const (
+	defaultControllerURL = "http://localhost:9000"
+	defaultTimeout       = 30 * time.Second
+)
+
+// Factory provides the dependencies shared by all commands: standard streams,
+// the configuration and the controller client. Replacing its fields allows
+// running commands in-process against other streams or controllers.
+type Factory struct {
+	IOStreams IOStreams
+
+	// ConfigFile is the configuration file selected with --config.
+	// An empty path selects the default location.
+	ConfigFile string
+
+	// OutputFormat is the output format selected with --output.
+	OutputFormat string
+
+	// RecordFile is the session file selected with --record.
+	RecordFile string
+
+	// ReplayFile is the session file selected with --replay.
+	ReplayFile string
+
+	// RequestID identifies this invocation in the X-Request-Id header of
+	// every controller request.
+	RequestID string
+
+	// TraceID is the W3C trace ID selected with --trace-id. A random trace ID
+	// is chosen when the first controller client is created.
+	TraceID string
+
+	// Config returns the configuration manager.
+	Config func() *config.Manager
+
+	// ControllerClient returns a client for the configured controller.
+	ControllerClient func() (*client.Client, error)
+
+	transport        http.RoundTripper
+	skewOnce         sync.Once
+	controllerCalled bool
+}
+
+// NewFactory creates a factory that reads the configuration file and
+// connects to the configured controller.
+func NewFactory(streams IOStreams) *Factory {
+	f := &Factory{
+		IOStreams:    streams,
+		OutputFormat: printer.FormatTable,
+		RequestID:    client.NewRequestID(),
+	}
+	f.Config = func() *config.Manager {
+		return config.NewManager(f.ConfigFile)
+	}
+	f.ControllerClient = func() (*client.Client, error) {
+		if f.TraceID == "" {
+			f.TraceID = client.NewTraceID()
+		} else if err := client.ValidateTraceID(f.TraceID); err != nil {
+			return nil, errdefs.Usage(fmt.Errorf("invalid --%s: %w", TraceIDFlag, err))
+		}
+
+		transport, err := f.HTTPTransport()
+		if err != nil {
+			return nil, err
+		}
+
+		f.controllerCalled = true
+		return NewControllerClient(f.Config(),
+			client.WithTransport(transport),
+			client.WithVersionHandler(f.warnVersionSkew),
+			client.WithUserAgent(version.UserAgent()),
+			client.WithRequestID(f.RequestID),
+			client.WithTraceID(f.TraceID),
+		)
+	}
+	return f
+}
+
+// HTTPTransport returns the transport for controller requests: a recorder
+// with --record, a replayer with --replay and nil for the default transport.
+// The transport is created once and shared by all clients of the invocation.
+func (f *Factory) HTTPTransport() (http.RoundTripper, error) {
+	if f.transport != nil {
+		return f.transport, nil
+	}
+
+	switch {
+	case f.RecordFile != "" && f.ReplayFile != "":
+		return nil, errdefs.Usage(fmt.Errorf("--%s and --%s cannot be used together", RecordFlag, ReplayFlag))
+	case f.RecordFile != "":
+		f.transport = recorder.NewRecorder(f.RecordFile, http.DefaultTransport)
+	case f.ReplayFile != "":
+		session, err := recorder.LoadSession(f.ReplayFile)
+		if err != nil {
+			return nil, errdefs.Usage(fmt.Errorf("invalid --%s: %w", ReplayFlag, err))
+		}
+		f.transport = recorder.NewReplayer(session)
+	}
+
+	return f.transport, nil
+}
+
+// RequestInfo describes the request and trace IDs sent to the controller so
+// that failures can be correlated with controller logs. It is empty if no
+// controller client was created.
+func (f *Factory) RequestInfo() string {
+	if !f.controllerCalled {
+		return ""
+	}
+	return fmt.Sprintf("request ID %s, trace ID %s", f.RequestID, f.TraceID)
+}
+
+// warnVersionSkew prints a warning once per invocation when the controller
+// version is outside the skew supported by this build of morpherctl.
+func (f *Factory) warnVersionSkew(controllerVersion string) {
+	f.skewOnce.Do(func() {
+		compat := version.CheckCompatibility(version.Version, controllerVersion)
+		if !compat.Supported() {
+			fmt.Fprintf(f.IOStreams.ErrOut, "Warning: %s\n", compat.Message)
+		}
+	})
+}
+
+// ControllerURLs returns the configured controller endpoints, starting with
+// the one requests are sent to first: controller.urls if set, otherwise
+// controller.url, otherwise the default. It never returns an empty list.
+func ControllerURLs(configMgr *config.Manager) []string {
+	if urls, err := configMgr.GetStringSlice("controller.urls"); err == nil && len(urls) > 0 {
+		return urls
+	}
+	controllerURL, err := configMgr.GetString("controller.url")
+	if err != nil || controllerURL == "" {
+		controllerURL = defaultControllerURL
+	}
+	return []string{controllerURL}
+}
+
+// NewControllerClient creates a controller client from the configuration.
+// Missing or invalid values fall back to the defaults.
+// Additional options are applied after the configured values.
+//
+// When controller.urls lists several endpoints they take precedence over
+// controller.url; the client fails over between them according to
+// controller.strategy and controller.cooldown.
+func NewControllerClient(configMgr *config.Manager, opts ...client.Option) (*client.Client, error) {
+	urls := ControllerURLs(configMgr)
+	controllerURL, endpoints := urls[0], urls[1:]
+
+	timeout := defaultTimeout
+	if timeoutStr, err := configMgr.GetString("controller.timeout"); err == nil && timeoutStr != "" {
+		parsed, err := time.ParseDuration(timeoutStr)
+		if err != nil {
+			return nil, errdefs.Config(fmt.Errorf("invalid controller.timeout %q: %w", timeoutStr, err))
+		}
+		timeout = parsed
+	}
+
+	strategyStr, _ := configMgr.GetString("controller.strategy")
+	strategy, err := client.ParseStrategy(strategyStr)
+	if err != nil {
+		return nil, errdefs.Config(fmt.Errorf("invalid controller.strategy: %w", err))
+	}
+
+	var cooldown time.Duration
+	if cooldownStr, err := configMgr.GetString("controller.cooldown"); err == nil && cooldownStr != "" {
+		cooldown, err = time.ParseDuration(cooldownStr)
+		if err != nil {
+			return nil, errdefs.Config(fmt.Errorf("invalid controller.cooldown %q: %w", cooldownStr, err))
+		}
+	}
+
+	token, err := configMgr.GetString("auth.token")
+	if err != nil {
+		token = ""
+	}
+
+	opts = append([]client.Option{
+		client.WithTimeout(timeout),
+		client.WithToken(token),
+		client.WithEndpoints(endpoints...),
+		client.WithStrategy(strategy),
+		client.WithCooldown(cooldown),
+	}, opts...)
+	c, err := client.New(controllerURL, opts...)
+	if err != nil {
+		return nil, errdefs.Config(err)
+	}
+	return c, nil
+}
+
+// NewPrinter returns the printer for the selected output format.
+func (f *Factory) NewPrinter() (printer.Printer, error) {
+	p, err := printer.New(f.OutputFormat)
+	if err != nil {
+		return nil, errdefs.Usage(fmt.Errorf("invalid --%s: %w", OutputFlag, err))
+	}
+	return p, nil
+}
+
+// InfoOut returns where informational messages such as progress should be
+// written: stdout for human-readable output and stderr for structured output.
+func (f *Factory) InfoOut() io.Writer {
+	if printer.IsStructured(f.OutputFormat) {
+		return f.IOStreams.ErrOut
+	}
+	return f.IOStreams.Out
+}
@@ -0,0 +1,131 @@
+package cmdutil
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/morpher-vm/morpherctl/internal/config"
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+)
+
+func TestNewControllerClient(t *testing.T) {
+	t.Run("should use defaults without configuration file", func(t *testing.T) {
+		client, err := NewControllerClient(config.NewManager(filepath.Join(t.TempDir(), "missing.yaml")))
+		require.NoError(t, err)
+
+		assert.Equal(t, defaultControllerURL, client.GetBaseURL())
+		assert.Equal(t, defaultTimeout, client.GetTimeout())
+	})
+
+	t.Run("should use configured values", func(t *testing.T) {
+		configMgr := config.NewManager(filepath.Join(t.TempDir(), "config.yaml"))
+		require.NoError(t, configMgr.Init())
+		require.NoError(t, configMgr.Set("controller.url", "http://controller:9000"))
+		require.NoError(t, configMgr.Set("controller.timeout", "5s"))
+
+		client, err := NewControllerClient(configMgr)
+		require.NoError(t, err)
+
+		assert.Equal(t, "http://controller:9000", client.GetBaseURL())
+		assert.Equal(t, 5*time.Second, client.GetTimeout())
+	})
+
+	t.Run("should prefer endpoint list", func(t *testing.T) {
+		configMgr := config.NewManager(filepath.Join(t.TempDir(), "config.yaml"))
+		require.NoError(t, configMgr.Init())
+		require.NoError(t, configMgr.Set("controller.urls", "http://a:9000,http://b:9000"))
+		require.NoError(t, configMgr.Set("controller.strategy", "round-robin"))
+
+		client, err := NewControllerClient(configMgr)
+		require.NoError(t, err)
+
+		assert.Equal(t, "http://a:9000", client.GetBaseURL())
+		assert.Equal(t, []string{"http://a:9000", "http://b:9000"}, client.Endpoints())
+	})
+
+	t.Run("should reject invalid strategy", func(t *testing.T) {
+		configMgr := config.NewManager(filepath.Join(t.TempDir(), "config.yaml"))
+		require.NoError(t, configMgr.Init())
+		require.NoError(t, configMgr.Set("controller.strategy", "random"))
+
+		_, err := NewControllerClient(configMgr)
+		require.Error(t, err)
+		assert.Equal(t, errdefs.KindConfig, errdefs.KindOf(err))
+	})
+
+	t.Run("should reject invalid timeout", func(t *testing.T) {
+		configMgr := config.NewManager(filepath.Join(t.TempDir(), "config.yaml"))
+		require.NoError(t, configMgr.Init())
+		require.NoError(t, configMgr.Set("controller.timeout", "soon"))
+
+		_, err := NewControllerClient(configMgr)
+		require.Error(t, err)
+		assert.Equal(t, errdefs.KindConfig, errdefs.KindOf(err))
+	})
+}
+
+func TestControllerURLs(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]string
+		expected []string
+	}{
+		{
+			name:     "should prefer the endpoint list",
+			settings: map[string]string{"controller.url": "http://c:9000", "controller.urls": "http://a:9000,http://b:9000"},
+			expected: []string{"http://a:9000", "http://b:9000"},
+		},
+		{
+			name:     "should use the controller URL",
+			settings: map[string]string{"controller.url": "http://c:9000"},
+			expected: []string{"http://c:9000"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configMgr := config.NewManager(filepath.Join(t.TempDir(), "config.yaml"))
+			require.NoError(t, configMgr.Init())
+			for key, value := range tt.settings {
+				require.NoError(t, configMgr.Set(key, value))
+			}
+			assert.Equal(t, tt.expected, ControllerURLs(configMgr))
+		})
+	}
+
+	t.Run("should use the default without configuration file", func(t *testing.T) {
+		configMgr := config.NewManager(filepath.Join(t.TempDir(), "missing.yaml"))
+		assert.Equal(t, []string{defaultControllerURL}, ControllerURLs(configMgr))
+	})
+}
+
+func TestFactory(t *testing.T) {
+	streams, _, out, errOut := NewTestIOStreams()
+	f := NewFactory(streams)
+
+	t.Run("should use selected configuration file", func(t *testing.T) {
+		f.ConfigFile = filepath.Join(t.TempDir(), "custom.yaml")
+		assert.Equal(t, f.ConfigFile, f.Config().GetConfigFile())
+	})
+
+	t.Run("should write informational output to stdout for tables", func(t *testing.T) {
+		f.OutputFormat = "table"
+		assert.Same(t, out, f.InfoOut())
+	})
+
+	t.Run("should write informational output to stderr for structured output", func(t *testing.T) {
+		f.OutputFormat = "json"
+		assert.Same(t, errOut, f.InfoOut())
+	})
+
+	t.Run("should report invalid output format as usage error", func(t *testing.T) {
+		f.OutputFormat = "xml"
+		_, err := f.NewPrinter()
+		require.Error(t, err)
+		assert.Equal(t, errdefs.KindUsage, errdefs.KindOf(err))
+	})
+}
@@ -0,0 +1,35 @@
+package cmdutil
+
+import (
+	"github.com/morpher-vm/morpherctl/internal/printer"
+
+	"github.com/spf13/cobra"
+)
+
+// Names of the global flags.
+const (
+	OutputFlag  = "output"
+	ConfigFlag  = "config"
+	RecordFlag  = "record"
+	ReplayFlag  = "replay"
+	TraceIDFlag = "trace-id"
+)
+
+// AddGlobalFlags registers the global flags on the root command and binds them to the factory.
+func AddGlobalFlags(cmd *cobra.Command, f *Factory) {
+	cmd.PersistentFlags().StringVar(&f.ConfigFile, ConfigFlag, f.ConfigFile,
+		"config file (default is $HOME/.morpherctl/config.yaml)")
+	cmd.PersistentFlags().StringVarP(&f.OutputFormat, OutputFlag, "o", f.OutputFormat,
+		"output format: table, wide, json, yaml, name, jsonpath=TEMPLATE or go-template=TEMPLATE")
+	cmd.PersistentFlags().StringVar(&f.RecordFile, RecordFlag, f.RecordFile,
+		"record controller requests and responses to a session file, with secrets scrubbed")
+	cmd.PersistentFlags().StringVar(&f.ReplayFile, ReplayFlag, f.ReplayFile,
+		"answer controller requests from a recorded session file instead of the network")
+	cmd.PersistentFlags().StringVar(&f.TraceID, TraceIDFlag, f.TraceID,
+		"W3C trace ID (32 hex characters) for the traceparent header of controller requests (default random)")
+	_ = cmd.MarkPersistentFlagFilename(RecordFlag, "json")
+	_ = cmd.MarkPersistentFlagFilename(ReplayFlag, "json")
+	_ = cmd.RegisterFlagCompletionFunc(OutputFlag, func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return printer.SupportedFormats(), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	})
+}
@@ -0,0 +1,43 @@
+package cmdutil
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// IOStreams holds the standard streams used by commands.
+type IOStreams struct {
+	In     io.Reader
+	Out    io.Writer
+	ErrOut io.Writer
+}
+
+// SystemIOStreams returns the streams of the current process.
+func SystemIOStreams() IOStreams {
+	return IOStreams{
+		In:     os.Stdin,
+		Out:    os.Stdout,
+		ErrOut: os.Stderr,
+	}
+}
+
+// NewTestIOStreams returns streams backed by buffers for running commands in tests.
+func NewTestIOStreams() (IOStreams, *bytes.Buffer, *bytes.Buffer, *bytes.Buffer) {
+	in := &bytes.Buffer{}
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+
+	return IOStreams{In: in, Out: out, ErrOut: errOut}, in, out, errOut
+}
+
+// IsTerminal reports whether w is a terminal, for example to decide whether
+// to color output.
+func IsTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
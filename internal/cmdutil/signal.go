@@ -0,0 +1,46 @@
+package cmdutil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// SignalContext returns a context that is cancelled on the first SIGINT or
+// SIGTERM so in-flight requests can be aborted cleanly. "interrupted" is
+// printed on the first signal and a second signal exits immediately.
+func SignalContext(parent context.Context, errOut io.Writer) (context.Context, context.CancelFunc) {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	ctx, cancel := notifyContext(parent, errOut, signals, os.Exit)
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel()
+	}
+}
+
+// notifyContext cancels the returned context on the first value received from
+// signals and calls exit on the second.
+func notifyContext(parent context.Context, errOut io.Writer, signals <-chan os.Signal, exit func(int)) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+
+	go func() {
+		select {
+		case <-signals:
+		case <-ctx.Done():
+			return
+		}
+		fmt.Fprintln(errOut, "interrupted")
+		cancel()
+
+		<-signals
+		fmt.Fprintln(errOut, "forced exit")
+		exit(ExitInterrupted)
+	}()
+
+	return ctx, cancel
+}
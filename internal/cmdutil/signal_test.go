@@ -0,0 +1,78 @@
+package cmdutil
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifyContext(t *testing.T) {
+	signals := make(chan os.Signal, 2)
+	exited := make(chan int, 1)
+
+	var mu sync.Mutex
+	var out []byte
+	errOut := writerFunc(func(p []byte) (int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		out = append(out, p...)
+		return len(p), nil
+	})
+
+	ctx, cancel := notifyContext(context.Background(), errOut, signals, func(code int) {
+		exited <- code
+	})
+	defer cancel()
+
+	t.Run("should cancel the context on the first signal", func(t *testing.T) {
+		signals <- os.Interrupt
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatal("context was not cancelled")
+		}
+		require.ErrorIs(t, ctx.Err(), context.Canceled)
+
+		mu.Lock()
+		assert.Equal(t, "interrupted\n", string(out))
+		mu.Unlock()
+	})
+
+	t.Run("should force exit on the second signal", func(t *testing.T) {
+		signals <- os.Interrupt
+
+		select {
+		case code := <-exited:
+			assert.Equal(t, ExitInterrupted, code)
+		case <-time.After(time.Second):
+			t.Fatal("process did not exit")
+		}
+	})
+}
+
+func TestNotifyContext_Cancel(t *testing.T) {
+	signals := make(chan os.Signal, 1)
+
+	ctx, cancel := notifyContext(context.Background(), writerFunc(func(p []byte) (int, error) {
+		t.Errorf("unexpected output: %s", p)
+		return len(p), nil
+	}), signals, func(int) {
+		t.Error("unexpected exit")
+	})
+	cancel()
+
+	<-ctx.Done()
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
@@ -2,7 +2,6 @@ package completion
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
 )
@@ -11,22 +10,22 @@ import (
 func GenerateCompletion(cmd *cobra.Command, shell string) error {
 	switch shell {
 	case "bash":
-		if err := cmd.Root().GenBashCompletion(os.Stdout); err != nil {
+		if err := cmd.Root().GenBashCompletion(cmd.OutOrStdout()); err != nil {
 			return fmt.Errorf("failed to generate bash completion: %w", err)
 		}
 		return nil
 	case "zsh":
-		if err := cmd.Root().GenZshCompletion(os.Stdout); err != nil {
+		if err := cmd.Root().GenZshCompletion(cmd.OutOrStdout()); err != nil {
 			return fmt.Errorf("failed to generate zsh completion: %w", err)
 		}
 		return nil
 	case "fish":
-		if err := cmd.Root().GenFishCompletion(os.Stdout, true); err != nil {
+		if err := cmd.Root().GenFishCompletion(cmd.OutOrStdout(), true); err != nil {
 			return fmt.Errorf("failed to generate fish completion: %w", err)
 		}
 		return nil
 	case "powershell":
-		if err := cmd.Root().GenPowerShellCompletion(os.Stdout); err != nil {
+		if err := cmd.Root().GenPowerShellCompletion(cmd.OutOrStdout()); err != nil {
 			return fmt.Errorf("failed to generate powershell completion: %w", err)
 		}
 		return nil
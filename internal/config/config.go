@@ -4,15 +4,19 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
+
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
 )
 
 // Manager handles configuration operations.
 type Manager struct {
 	configFile string
 	configDir  string
+	v          *viper.Viper
 }
 
 // NewManager creates a new configuration manager.
@@ -40,6 +44,7 @@ func NewManager(configFile string) *Manager {
 	return &Manager{
 		configFile: configFile,
 		configDir:  configDir,
+		v:          viper.New(),
 	}
 }
 
@@ -53,19 +58,19 @@ func (m *Manager) Init() error {
 	}
 
 	// Set default configuration values.
-	viper.SetDefault("controller.url", "http://localhost:8080")
-	viper.SetDefault("controller.timeout", "30s")
-	viper.SetDefault("auth.token", "")
-	viper.SetDefault("auth.refresh_token", "")
-	viper.SetDefault("agent.install_path", "/opt/morpher")
-	viper.SetDefault("agent.log_level", "info")
+	m.v.SetDefault("controller.url", "http://localhost:8080")
+	m.v.SetDefault("controller.timeout", "30s")
+	m.v.SetDefault("auth.token", "")
+	m.v.SetDefault("auth.refresh_token", "")
+	m.v.SetDefault("agent.install_path", "/opt/morpher")
+	m.v.SetDefault("agent.log_level", "info")
 
 	// Set configuration file path.
-	viper.SetConfigFile(m.configFile)
-	viper.SetConfigType("yaml")
+	m.v.SetConfigFile(m.configFile)
+	m.v.SetConfigType("yaml")
 
 	// Save configuration file.
-	if err := viper.WriteConfigAs(m.configFile); err != nil {
+	if err := m.v.WriteConfigAs(m.configFile); err != nil {
 		return fmt.Errorf("failed to save configuration file: %w", err)
 	}
 
@@ -80,10 +85,10 @@ func (m *Manager) Set(key, value string) error {
 	}
 
 	// Set configuration value.
-	viper.Set(key, value)
+	m.v.Set(key, value)
 
 	// Save configuration file.
-	if err := viper.WriteConfig(); err != nil {
+	if err := m.v.WriteConfig(); err != nil {
 		return fmt.Errorf("failed to save configuration: %w", err)
 	}
 
@@ -97,9 +102,9 @@ func (m *Manager) Get(key string) (any, error) {
 		return nil, err
 	}
 
-	value := viper.Get(key)
+	value := m.v.Get(key)
 	if value == nil {
-		return nil, fmt.Errorf("configuration key '%s' not found", key)
+		return nil, errdefs.New(errdefs.KindNotFound, fmt.Errorf("configuration key '%s' not found", key))
 	}
 
 	return value, nil
@@ -112,7 +117,7 @@ func (m *Manager) GetAll() (map[string]any, error) {
 		return nil, err
 	}
 
-	return viper.AllSettings(), nil
+	return m.v.AllSettings(), nil
 }
 
 // GetString retrieves a string configuration value by key.
@@ -122,7 +127,7 @@ func (m *Manager) GetString(key string) (string, error) {
 		return "", err
 	}
 
-	return viper.GetString(key), nil
+	return m.v.GetString(key), nil
 }
 
 // GetDuration retrieves a duration configuration value by key.
@@ -132,16 +137,43 @@ func (m *Manager) GetDuration(key string) (time.Duration, error) {
 		return 0, err
 	}
 
-	return viper.GetDuration(key), nil
+	return m.v.GetDuration(key), nil
+}
+
+// GetStringSlice retrieves a list configuration value by key. Besides YAML
+// lists, comma separated strings as written by Set are accepted.
+func (m *Manager) GetStringSlice(key string) ([]string, error) {
+	// Load configuration file.
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+
+	var values []string
+	switch value := m.v.Get(key).(type) {
+	case nil:
+		return nil, nil
+	case string:
+		values = strings.Split(value, ",")
+	default:
+		values = m.v.GetStringSlice(key)
+	}
+
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result, nil
 }
 
 // load loads the configuration file.
 func (m *Manager) load() error {
-	viper.SetConfigFile(m.configFile)
-	viper.SetConfigType("yaml")
+	m.v.SetConfigFile(m.configFile)
+	m.v.SetConfigType("yaml")
 
-	if err := viper.ReadInConfig(); err != nil {
-		return fmt.Errorf("failed to read configuration file: %w", err)
+	if err := m.v.ReadInConfig(); err != nil {
+		return errdefs.Config(fmt.Errorf("failed to read configuration file: %w", err))
 	}
 
 	return nil
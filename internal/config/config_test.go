@@ -8,6 +8,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
 )
 
 func TestNewManager(t *testing.T) {
@@ -218,6 +220,41 @@ func TestManager_GetDuration(t *testing.T) {
 	})
 }
 
+func TestManager_GetStringSlice(t *testing.T) {
+	// Create temporary directory for testing.
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "test_config.yaml")
+
+	manager := NewManager(configFile)
+
+	// Initialize first.
+	err := manager.Init()
+	require.NoError(t, err)
+
+	t.Run("should split comma separated value", func(t *testing.T) {
+		require.NoError(t, manager.Set("controller.urls", "http://a:9000, http://b:9000,"))
+
+		urls, err := manager.GetStringSlice("controller.urls")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"http://a:9000", "http://b:9000"}, urls)
+	})
+
+	t.Run("should read yaml list", func(t *testing.T) {
+		listFile := filepath.Join(tempDir, "list_config.yaml")
+		require.NoError(t, os.WriteFile(listFile, []byte("controller:\n  urls:\n    - http://a:9000\n    - http://b:9000\n"), 0600))
+
+		urls, err := NewManager(listFile).GetStringSlice("controller.urls")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"http://a:9000", "http://b:9000"}, urls)
+	})
+
+	t.Run("should return nil for non-existent key", func(t *testing.T) {
+		urls, err := manager.GetStringSlice("non.existent.key")
+		require.NoError(t, err)
+		assert.Nil(t, urls)
+	})
+}
+
 func TestManager_LoadError(t *testing.T) {
 	// Create manager with non-existent config file.
 	manager := NewManager("/non/existent/config.yaml")
@@ -228,3 +265,23 @@ func TestManager_LoadError(t *testing.T) {
 		assert.Contains(t, err.Error(), "failed to read configuration file")
 	})
 }
+
+func TestManager_ErrorKinds(t *testing.T) {
+	t.Run("should report missing configuration file as config error", func(t *testing.T) {
+		manager := NewManager(filepath.Join(t.TempDir(), "missing.yaml"))
+
+		_, err := manager.GetAll()
+		require.Error(t, err)
+		assert.Equal(t, errdefs.KindConfig, errdefs.KindOf(err))
+	})
+
+	t.Run("should report missing key as not found", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "config.yaml")
+		manager := NewManager(configFile)
+		require.NoError(t, manager.Init())
+
+		_, err := manager.Get("does.not.exist")
+		require.Error(t, err)
+		assert.Equal(t, errdefs.KindNotFound, errdefs.KindOf(err))
+	})
+}
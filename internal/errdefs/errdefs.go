@@ -0,0 +1,146 @@
+package errdefs
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// Kind classifies an error by its cause.
+type Kind int
+
+// Error kinds recognized by morpherctl.
+const (
+	KindUnknown Kind = iota
+	KindUsage
+	KindConfig
+	KindNetwork
+	KindAuth
+	KindNotFound
+	KindConflict
+	KindServer
+	KindTimeout
+)
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	switch k {
+	case KindUsage:
+		return "usage"
+	case KindConfig:
+		return "config"
+	case KindNetwork:
+		return "network"
+	case KindAuth:
+		return "auth"
+	case KindNotFound:
+		return "not-found"
+	case KindConflict:
+		return "conflict"
+	case KindServer:
+		return "server"
+	case KindTimeout:
+		return "timeout"
+	default:
+		return "unknown"
+	}
+}
+
+// Error is an error explicitly tagged with a kind.
+type Error struct {
+	Kind Kind
+	Err  error
+}
+
+// Error returns the message of the wrapped error.
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// HTTPStatusError is implemented by errors carrying an HTTP response status code.
+type HTTPStatusError interface {
+	HTTPStatus() int
+}
+
+// New tags err with the given kind. A nil error stays nil.
+func New(kind Kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// Usage tags err as an invalid command line.
+func Usage(err error) error {
+	return New(KindUsage, err)
+}
+
+// Config tags err as an invalid or unreadable configuration.
+func Config(err error) error {
+	return New(KindConfig, err)
+}
+
+// KindOf classifies err. Explicitly tagged errors take precedence, then HTTP
+// status codes, then timeouts and network failures.
+func KindOf(err error) Kind {
+	if err == nil {
+		return KindUnknown
+	}
+
+	var tagged *Error
+	if errors.As(err, &tagged) {
+		return tagged.Kind
+	}
+
+	var statusErr HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return KindFromStatus(statusErr.HTTPStatus())
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return KindTimeout
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return KindTimeout
+		}
+		return KindNetwork
+	}
+
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	if errors.As(err, &opErr) || errors.As(err, &dnsErr) || errors.Is(err, syscall.ECONNREFUSED) {
+		return KindNetwork
+	}
+
+	return KindUnknown
+}
+
+// KindFromStatus classifies an HTTP response status code.
+func KindFromStatus(statusCode int) Kind {
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return KindAuth
+	case statusCode == http.StatusNotFound:
+		return KindNotFound
+	case statusCode == http.StatusConflict:
+		return KindConflict
+	case statusCode == http.StatusRequestTimeout || statusCode == http.StatusGatewayTimeout:
+		return KindTimeout
+	case statusCode == http.StatusBadRequest || statusCode == http.StatusUnprocessableEntity:
+		return KindUsage
+	case statusCode >= 500:
+		return KindServer
+	default:
+		return KindUnknown
+	}
+}
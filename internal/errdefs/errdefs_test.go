@@ -0,0 +1,83 @@
+package errdefs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type statusError int
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("status %d", int(e))
+}
+
+func (e statusError) HTTPStatus() int {
+	return int(e)
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected Kind
+	}{
+		{name: "should return unknown for nil", err: nil, expected: KindUnknown},
+		{name: "should return unknown for plain error", err: errors.New("boom"), expected: KindUnknown},
+		{name: "should return tagged usage kind", err: Usage(errors.New("bad flag")), expected: KindUsage},
+		{name: "should return wrapped tagged kind", err: fmt.Errorf("load: %w", Config(errors.New("bad file"))), expected: KindConfig},
+		{name: "should classify 401 as auth", err: statusError(http.StatusUnauthorized), expected: KindAuth},
+		{name: "should classify 403 as auth", err: statusError(http.StatusForbidden), expected: KindAuth},
+		{name: "should classify 404 as not found", err: statusError(http.StatusNotFound), expected: KindNotFound},
+		{name: "should classify 409 as conflict", err: statusError(http.StatusConflict), expected: KindConflict},
+		{name: "should classify 500 as server", err: fmt.Errorf("ping: %w", statusError(http.StatusInternalServerError)), expected: KindServer},
+		{name: "should classify 504 as timeout", err: statusError(http.StatusGatewayTimeout), expected: KindTimeout},
+		{name: "should classify 400 as usage", err: statusError(http.StatusBadRequest), expected: KindUsage},
+		{name: "should classify deadline as timeout", err: fmt.Errorf("ping: %w", context.DeadlineExceeded), expected: KindTimeout},
+		{
+			name:     "should classify network timeouts as timeout",
+			err:      &url.Error{Op: "Get", URL: "http://controller/ping", Err: timeoutError{}},
+			expected: KindTimeout,
+		},
+		{
+			name: "should classify connection refused as network",
+			err: &url.Error{Op: "Get", URL: "http://controller/ping", Err: &net.OpError{
+				Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED,
+			}},
+			expected: KindNetwork,
+		},
+		{name: "should classify DNS failures as network", err: &net.DNSError{Err: "no such host", Name: "controller"}, expected: KindNetwork},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, KindOf(tt.err))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	assert.NoError(t, New(KindServer, nil))
+
+	err := New(KindConflict, errors.New("agent has active migrations"))
+	assert.Equal(t, "agent has active migrations", err.Error())
+	assert.Equal(t, KindConflict, KindOf(err))
+}
+
+func TestKind_String(t *testing.T) {
+	assert.Equal(t, "not-found", KindNotFound.String())
+	assert.Equal(t, "unknown", Kind(99).String())
+}
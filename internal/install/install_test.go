@@ -0,0 +1,153 @@
+package install
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeBinary writes a fake agent binary and returns its path and checksum.
+func writeBinary(t *testing.T) (string, string) {
+	t.Helper()
+
+	content := []byte("#!/bin/sh\necho agent\n")
+	name := filepath.Join(t.TempDir(), "morpher-agent")
+	require.NoError(t, os.WriteFile(name, content, 0o755))
+
+	sum := sha256.Sum256(content)
+	return name, hex.EncodeToString(sum[:])
+}
+
+func TestNewPlan(t *testing.T) {
+	binary, checksum := writeBinary(t)
+
+	t.Run("should render config and unit", func(t *testing.T) {
+		plan, err := NewPlan(Options{
+			Binary:        binary,
+			ControllerURL: "https://controller:9000",
+			JoinToken:     "s3cr3t",
+			LogLevel:      "debug",
+			Start:         true,
+		})
+		require.NoError(t, err)
+
+		require.Len(t, plan.Files, 3)
+		assert.Equal(t, "/opt/morpher/bin/morpher-agent", plan.Files[0].Path)
+		assert.Equal(t, checksum, plan.BinarySHA256)
+
+		config := string(plan.Files[1].Content)
+		assert.Equal(t, "/opt/morpher/etc/agent.yaml", plan.Files[1].Path)
+		assert.Contains(t, config, "  url: https://controller:9000\n")
+		assert.Contains(t, config, "  join_token: s3cr3t\n")
+		assert.Contains(t, config, "log_level: debug\n")
+
+		assert.Equal(t, UnitPath, plan.Files[2].Path)
+		assert.Contains(t, string(plan.Files[2].Content),
+			"ExecStart=/opt/morpher/bin/morpher-agent --config /opt/morpher/etc/agent.yaml\n")
+
+		assert.Equal(t, [][]string{
+			{"systemctl", "daemon-reload"},
+			{"systemctl", "enable", "--now", "morpher-agent.service"},
+		}, plan.Commands)
+	})
+
+	tests := []struct {
+		name          string
+		opts          Options
+		checksumFile  string
+		expectedError string
+	}{
+		{
+			name: "should accept matching checksum",
+			opts: Options{SHA256: checksum},
+		},
+		{
+			name:          "should reject checksum mismatch",
+			opts:          Options{SHA256: "0000"},
+			expectedError: "checksum mismatch",
+		},
+		{
+			name:         "should verify against checksum file",
+			checksumFile: checksum + "  morpher-agent\n",
+		},
+		{
+			name:          "should reject mismatch with checksum file",
+			checksumFile:  "0000  morpher-agent\n",
+			expectedError: "checksum mismatch",
+		},
+		{
+			name:          "should reject relative install path",
+			opts:          Options{InstallPath: "opt/morpher"},
+			expectedError: "must be absolute",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binary, _ := writeBinary(t)
+			if tt.checksumFile != "" {
+				require.NoError(t, os.WriteFile(binary+".sha256", []byte(tt.checksumFile), 0o644))
+			}
+
+			opts := tt.opts
+			opts.Binary = binary
+			opts.ControllerURL = "https://controller:9000"
+
+			_, err := NewPlan(opts)
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestPlan_Print(t *testing.T) {
+	binary, checksum := writeBinary(t)
+	plan, err := NewPlan(Options{Binary: binary, ControllerURL: "https://controller:9000", JoinToken: "s3cr3t", Start: true})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, plan.Print(&out, "/staging"))
+
+	assert.Contains(t, out.String(), "# /staging/opt/morpher/bin/morpher-agent (0755, 21 bytes, sha256 "+checksum+")\n")
+	assert.Contains(t, out.String(), "  join_token: REDACTED\n")
+	assert.NotContains(t, out.String(), "s3cr3t")
+	assert.Contains(t, out.String(), "$ systemctl enable --now morpher-agent.service\n")
+}
+
+func TestApply_LocalTarget(t *testing.T) {
+	binary, _ := writeBinary(t)
+	plan, err := NewPlan(Options{Binary: binary, ControllerURL: "https://controller:9000", InstallPath: "/srv/morpher"})
+	require.NoError(t, err)
+
+	root := t.TempDir()
+	require.NoError(t, Apply(context.Background(), plan, LocalTarget{Root: root}))
+
+	info, err := os.Stat(filepath.Join(root, "srv/morpher/bin/morpher-agent"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+
+	info, err = os.Stat(filepath.Join(root, "srv/morpher/etc/agent.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	_, err = os.Stat(filepath.Join(root, "etc/systemd/system/morpher-agent.service"))
+	require.NoError(t, err)
+
+	t.Run("should detect corrupted copy", func(t *testing.T) {
+		plan.BinarySHA256 = "0000"
+		err := Apply(context.Background(), plan, LocalTarget{Root: root})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "checksum mismatch for installed")
+	})
+}
@@ -0,0 +1,273 @@
+package install
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Defaults of the agent installation.
+const (
+	DefaultInstallPath = "/opt/morpher"
+	DefaultLogLevel    = "info"
+)
+
+// LogLevels are the log levels supported by the agent.
+var LogLevels = []string{"debug", "info", "warn", "error"}
+
+// ServiceName is the name of the systemd service of the agent.
+const ServiceName = "morpher-agent"
+
+// UnitPath is the path of the generated systemd unit.
+const UnitPath = "/etc/systemd/system/" + ServiceName + ".service"
+
+// Options configure an agent installation.
+type Options struct {
+	// InstallPath is the directory the agent is installed into.
+	InstallPath string
+
+	// Binary is the local path of the agent binary to install.
+	Binary string
+
+	// SHA256 is the expected hex checksum of the binary. When empty, the
+	// checksum is read from a "<binary>.sha256" file next to the binary
+	// if there is one.
+	SHA256 string
+
+	// ControllerURL is the controller the agent registers with.
+	ControllerURL string
+
+	// JoinToken is the bootstrap token the agent registers with.
+	JoinToken string
+
+	// LogLevel is the log level of the agent.
+	LogLevel string
+
+	// Start enables and starts the service after the files are written.
+	Start bool
+}
+
+// File is a file written by an installation.
+type File struct {
+	Path    string
+	Mode    fs.FileMode
+	Content []byte
+}
+
+// Plan lists the files and commands of an installation.
+type Plan struct {
+	Files    []File
+	Commands [][]string
+
+	// BinaryPath is the installed path of the agent binary.
+	BinaryPath string
+
+	// BinarySHA256 is the verified checksum of the agent binary.
+	BinarySHA256 string
+
+	// secrets are masked when the plan is printed.
+	secrets []string
+	// opts are the options the plan was created with.
+	opts Options
+}
+
+// agentConfig is the configuration file of the agent.
+type agentConfig struct {
+	Controller struct {
+		URL       string `yaml:"url"`
+		JoinToken string `yaml:"join_token,omitempty"`
+	} `yaml:"controller"`
+	LogLevel    string `yaml:"log_level"`
+	InstallPath string `yaml:"install_path"`
+}
+
+var unitTemplate = template.Must(template.New("unit").Parse(`[Unit]
+Description=Morpher agent
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+Type=simple
+ExecStart={{.Binary}} --config {{.Config}}
+Restart=on-failure
+RestartSec=5s
+LimitNOFILE=65536
+
+[Install]
+WantedBy=multi-user.target
+`))
+
+// BinaryPath returns the path of the agent binary below the install path.
+func BinaryPath(installPath string) string {
+	return path.Join(installPath, "bin", ServiceName)
+}
+
+// ConfigPath returns the path of the agent configuration below the install path.
+func ConfigPath(installPath string) string {
+	return path.Join(installPath, "etc", "agent.yaml")
+}
+
+// NewPlan reads and verifies the agent binary and returns the files and
+// commands that install it.
+func NewPlan(opts Options) (*Plan, error) {
+	if opts.InstallPath == "" {
+		opts.InstallPath = DefaultInstallPath
+	}
+	if opts.LogLevel == "" {
+		opts.LogLevel = DefaultLogLevel
+	}
+	if !path.IsAbs(opts.InstallPath) {
+		return nil, fmt.Errorf("install path %q must be absolute", opts.InstallPath)
+	}
+	if opts.ControllerURL == "" {
+		return nil, errors.New("controller URL is required")
+	}
+
+	binary, err := os.ReadFile(opts.Binary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agent binary: %w", err)
+	}
+	checksum, err := verifyChecksum(opts.Binary, binary, opts.SHA256)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := renderConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var unit bytes.Buffer
+	err = unitTemplate.Execute(&unit, map[string]string{
+		"Binary": BinaryPath(opts.InstallPath),
+		"Config": ConfigPath(opts.InstallPath),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render systemd unit: %w", err)
+	}
+
+	plan := &Plan{
+		Files: []File{
+			{Path: BinaryPath(opts.InstallPath), Mode: 0o755, Content: binary},
+			// The config holds the join token.
+			{Path: ConfigPath(opts.InstallPath), Mode: 0o600, Content: config},
+			{Path: UnitPath, Mode: 0o644, Content: unit.Bytes()},
+		},
+		BinaryPath:   BinaryPath(opts.InstallPath),
+		BinarySHA256: checksum,
+		opts:         opts,
+	}
+	if opts.JoinToken != "" {
+		plan.secrets = append(plan.secrets, opts.JoinToken)
+	}
+	if opts.Start {
+		plan.Commands = [][]string{
+			{"systemctl", "daemon-reload"},
+			{"systemctl", "enable", "--now", ServiceName + ".service"},
+		}
+	}
+
+	return plan, nil
+}
+
+// SetJoinToken changes the join token written to the agent configuration, so
+// that a token can be created once the plan is known to be valid.
+func (p *Plan) SetJoinToken(token string) error {
+	opts := p.opts
+	opts.JoinToken = token
+	config, err := renderConfig(opts)
+	if err != nil {
+		return err
+	}
+
+	for i, file := range p.Files {
+		if file.Path == ConfigPath(opts.InstallPath) {
+			p.Files[i].Content = config
+		}
+	}
+	p.opts = opts
+	if token != "" {
+		p.secrets = append(p.secrets, token)
+	}
+	return nil
+}
+
+// renderConfig returns the agent configuration file of the options.
+func renderConfig(opts Options) ([]byte, error) {
+	var cfg agentConfig
+	cfg.Controller.URL = opts.ControllerURL
+	cfg.Controller.JoinToken = opts.JoinToken
+	cfg.LogLevel = opts.LogLevel
+	cfg.InstallPath = opts.InstallPath
+	config := bytes.NewBufferString("# Generated by morpherctl agent install.\n")
+	encoder := yaml.NewEncoder(config)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cfg); err != nil {
+		return nil, fmt.Errorf("failed to render agent config: %w", err)
+	}
+	return config.Bytes(), nil
+}
+
+// verifyChecksum checks the binary against the expected checksum, or the
+// checksum file next to it, and returns its actual checksum.
+func verifyChecksum(name string, binary []byte, expected string) (string, error) {
+	sum := sha256.Sum256(binary)
+	actual := hex.EncodeToString(sum[:])
+
+	if expected == "" {
+		data, err := os.ReadFile(name + ".sha256")
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			return actual, nil
+		case err != nil:
+			return "", fmt.Errorf("failed to read checksum file: %w", err)
+		}
+		// The file is in the format of sha256sum: "<checksum>  <name>".
+		fields := strings.Fields(string(data))
+		if len(fields) == 0 {
+			return "", fmt.Errorf("checksum file %s.sha256 is empty", name)
+		}
+		expected = fields[0]
+	}
+
+	if !strings.EqualFold(expected, actual) {
+		return "", fmt.Errorf("checksum mismatch for %s: expected sha256 %s, got %s", name, expected, actual)
+	}
+	return actual, nil
+}
+
+// Print writes every file and command of the plan, with secrets masked.
+// File paths are prefixed with root. Binary files are summarized instead of
+// printed.
+func (p *Plan) Print(w io.Writer, root string) error {
+	for _, file := range p.Files {
+		name := path.Join(root, file.Path)
+		if file.Path == p.BinaryPath {
+			fmt.Fprintf(w, "# %s (%04o, %d bytes, sha256 %s)\n\n", name, file.Mode, len(file.Content), p.BinarySHA256)
+			continue
+		}
+		fmt.Fprintf(w, "# %s (%04o)\n%s\n", name, file.Mode, p.mask(string(file.Content)))
+	}
+	for _, command := range p.Commands {
+		fmt.Fprintf(w, "$ %s\n", strings.Join(command, " "))
+	}
+	return nil
+}
+
+// mask replaces the secrets in s.
+func (p *Plan) mask(s string) string {
+	for _, secret := range p.secrets {
+		s = strings.ReplaceAll(s, secret, "REDACTED")
+	}
+	return s
+}
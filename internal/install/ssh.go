@@ -0,0 +1,387 @@
+package install
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// DefaultSSHPort is the port used for hosts without a port.
+const DefaultSSHPort = "22"
+
+// SSHHost is a host to install on over SSH.
+type SSHHost struct {
+	User string
+	Addr string
+}
+
+// String returns the host in user@host:port form.
+func (h SSHHost) String() string {
+	return h.User + "@" + h.Addr
+}
+
+// ParseSSHHosts parses a comma separated list of [user@]host[:port]. Hosts
+// without a user inherit the user of the previous host; the first one
+// defaults to the current user.
+func ParseSSHHosts(spec string) ([]SSHHost, error) {
+	currentUser := "root"
+	if u, err := user.Current(); err == nil {
+		currentUser = u.Username
+	}
+
+	var hosts []SSHHost
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		login, host, ok := strings.Cut(entry, "@")
+		if !ok {
+			login, host = currentUser, entry
+		}
+		if login == "" || host == "" {
+			return nil, fmt.Errorf("invalid SSH host %q: must be [user@]host[:port]", entry)
+		}
+		currentUser = login
+
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			host = net.JoinHostPort(strings.Trim(host, "[]"), DefaultSSHPort)
+		}
+		hosts = append(hosts, SSHHost{User: login, Addr: host})
+	}
+	if len(hosts) == 0 {
+		return nil, errors.New("no SSH hosts given")
+	}
+	return hosts, nil
+}
+
+// SSHConfig holds the settings shared by all SSH connections.
+type SSHConfig struct {
+	// IdentityFiles are private keys to authenticate with. Keys of a running
+	// ssh-agent are used as well. Without either, the default keys in
+	// ~/.ssh are tried.
+	IdentityFiles []string
+
+	// KnownHostsFile is the known_hosts file host keys are checked against.
+	// It defaults to ~/.ssh/known_hosts.
+	KnownHostsFile string
+
+	// InsecureIgnoreHostKey disables host key checking.
+	InsecureIgnoreHostKey bool
+
+	// Timeout limits establishing a connection.
+	Timeout time.Duration
+}
+
+// defaultIdentityFiles are the keys tried when no key is configured.
+var defaultIdentityFiles = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// ClientConfig builds the SSH client configuration. The user is set per host
+// by DialSSH. The returned function closes the connection to ssh-agent and
+// must be called once no more connections are made with the configuration.
+func (c SSHConfig) ClientConfig() (*ssh.ClientConfig, func() error, error) {
+	auth, closeAgent, err := c.authMethods()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hostKeyCallback := ssh.InsecureIgnoreHostKey() //nolint:gosec // Only when explicitly requested.
+	if !c.InsecureIgnoreHostKey {
+		file := c.KnownHostsFile
+		if file == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				_ = closeAgent()
+				return nil, nil, fmt.Errorf("failed to locate known_hosts: %w", err)
+			}
+			file = filepath.Join(home, ".ssh", "known_hosts")
+		}
+		hostKeyCallback, err = knownhosts.New(file)
+		if err != nil {
+			_ = closeAgent()
+			return nil, nil, fmt.Errorf("failed to read known_hosts: %w", err)
+		}
+	}
+
+	return &ssh.ClientConfig{
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         c.Timeout,
+	}, closeAgent, nil
+}
+
+// authMethods returns the keys of the identity files and the SSH agent,
+// together with a function closing the connection to the agent.
+func (c SSHConfig) authMethods() ([]ssh.AuthMethod, func() error, error) {
+	var signers []ssh.Signer
+	for _, file := range c.IdentityFiles {
+		signer, err := readPrivateKey(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		signers = append(signers, signer)
+	}
+
+	var methods []ssh.AuthMethod
+	if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+	closeAgent := func() error { return nil }
+	if socket := os.Getenv("SSH_AUTH_SOCK"); socket != "" {
+		if conn, err := net.Dial("unix", socket); err == nil {
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+			closeAgent = conn.Close
+		}
+	}
+
+	if len(methods) == 0 {
+		home, _ := os.UserHomeDir()
+		for _, name := range defaultIdentityFiles {
+			if signer, err := readPrivateKey(filepath.Join(home, ".ssh", name)); err == nil {
+				signers = append(signers, signer)
+			}
+		}
+		if len(signers) == 0 {
+			return nil, nil, errors.New("no SSH keys: use --ssh-key or start ssh-agent")
+		}
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+	return methods, closeAgent, nil
+}
+
+// readPrivateKey reads an unencrypted private key.
+func readPrivateKey(file string) (ssh.Signer, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH key: %w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(data)
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		return nil, fmt.Errorf("SSH key %s is encrypted: add it to ssh-agent instead", file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH key %s: %w", file, err)
+	}
+	return signer, nil
+}
+
+// SSHTarget installs on a remote host over SSH. Commands run through
+// sudo unless the user is root. All paths are relative to Root.
+type SSHTarget struct {
+	Root string
+
+	client *ssh.Client
+	sudo   bool
+}
+
+// DialSSH connects to the host. cfg.Timeout limits both the TCP connection
+// and the SSH handshake, which are also abandoned when ctx is done.
+func DialSSH(ctx context.Context, host SSHHost, cfg *ssh.ClientConfig) (*SSHTarget, error) {
+	dialer := net.Dialer{Timeout: cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", host.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", host.Addr, err)
+	}
+
+	if cfg.Timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(cfg.Timeout))
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+
+	clientConfig := *cfg
+	clientConfig.User = host.User
+	sshConn, channels, requests, err := ssh.NewClientConn(conn, host.Addr, &clientConfig)
+	if !stop() && err == nil {
+		sshConn.Close()
+		err = ctx.Err()
+	}
+	if err != nil {
+		conn.Close()
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return nil, fmt.Errorf("failed to connect to %s: %w", host.Addr, err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	return &SSHTarget{
+		client: ssh.NewClient(sshConn, channels, requests),
+		sudo:   host.User != "root",
+	}, nil
+}
+
+// Close closes the connection.
+func (t *SSHTarget) Close() error {
+	return t.client.Close()
+}
+
+// WriteFile uploads the file to a temporary file and renames it into place.
+// The temporary file is only accessible by its owner until it has its mode,
+// so that secrets such as the join token are never readable by others.
+func (t *SSHTarget) WriteFile(ctx context.Context, name string, content []byte, mode fs.FileMode) error {
+	name = t.path(name)
+	tmp := name + ".morpherctl-tmp"
+	script := fmt.Sprintf("mkdir -p %s && (umask 077 && cat > %s) && chmod %04o %s && mv -f %s %s",
+		shellQuote(path.Dir(name)), shellQuote(tmp), mode, shellQuote(tmp), shellQuote(tmp), shellQuote(name))
+
+	_, err := t.run(ctx, script, bytes.NewReader(content))
+	return err
+}
+
+// SHA256 returns the hex checksum of a file.
+func (t *SSHTarget) SHA256(ctx context.Context, name string) (string, error) {
+	output, err := t.run(ctx, "sha256sum "+shellQuote(t.path(name)), nil)
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return "", errors.New("sha256sum printed no checksum")
+	}
+	return fields[0], nil
+}
+
+// Run runs a command.
+func (t *SSHTarget) Run(ctx context.Context, command []string) error {
+	quoted := make([]string, 0, len(command))
+	for _, arg := range command {
+		quoted = append(quoted, shellQuote(arg))
+	}
+	_, err := t.run(ctx, strings.Join(quoted, " "), nil)
+	return err
+}
+
+// List returns the path and every path below it.
+func (t *SSHTarget) List(ctx context.Context, name string) ([]string, error) {
+	name = t.path(name)
+	output, err := t.run(ctx,
+		fmt.Sprintf("if [ -e %s ]; then find %s | LC_ALL=C sort; fi", shellQuote(name), shellQuote(name)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line == "" {
+			continue
+		}
+		rel, ok := strings.CutPrefix(line, path.Join("/", t.Root))
+		if !ok {
+			rel = line
+		}
+		paths = append(paths, path.Join("/", rel))
+	}
+	return paths, nil
+}
+
+// Remove removes a file or an empty directory.
+func (t *SSHTarget) Remove(ctx context.Context, name string) error {
+	name = shellQuote(t.path(name))
+	_, err := t.run(ctx, fmt.Sprintf("if [ -d %s ] && [ ! -L %s ]; then rmdir %s; else rm -f %s; fi", name, name, name, name), nil)
+	return err
+}
+
+// run runs a shell script and returns its output. The error includes the
+// standard error of the script.
+func (t *SSHTarget) run(ctx context.Context, script string, stdin io.Reader) (string, error) {
+	session, err := t.client.NewSession()
+	if err != nil {
+		return "", fmt.Errorf("failed to open SSH session: %w", err)
+	}
+	defer session.Close()
+
+	var stdout, stderr bytes.Buffer
+	session.Stdin = stdin
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+
+	command := "sh -c " + shellQuote(script)
+	if t.sudo {
+		command = "sudo -n " + command
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- session.Run(command) }()
+
+	select {
+	case <-ctx.Done():
+		session.Close()
+		return "", ctx.Err()
+	case err := <-done:
+		if err != nil {
+			return "", fmt.Errorf("%s failed: %w: %s", script, err, strings.TrimSpace(stderr.String()))
+		}
+		return stdout.String(), nil
+	}
+}
+
+// path returns the remote path of a target path.
+func (t *SSHTarget) path(name string) string {
+	return path.Join("/", t.Root, name)
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:@") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// ApplySSH applies the plan to every host, at most parallel hosts at a time,
+// and returns the error of every host in the order of hosts.
+func ApplySSH(ctx context.Context, plan *Plan, hosts []SSHHost, cfg *ssh.ClientConfig, root string, parallel int) []error {
+	return ForEachSSH(ctx, hosts, cfg, root, parallel, func(ctx context.Context, _ int, target *SSHTarget) error {
+		return Apply(ctx, plan, target)
+	})
+}
+
+// ForEachSSH connects to every host, at most parallel hosts at a time, and
+// calls fn with the index of the host in hosts and a target whose paths are
+// relative to root. It returns the error of every host in the order of hosts.
+func ForEachSSH(ctx context.Context, hosts []SSHHost, cfg *ssh.ClientConfig, root string, parallel int,
+	fn func(ctx context.Context, i int, target *SSHTarget) error,
+) []error {
+	errs := make([]error, len(hosts))
+	sem := make(chan struct{}, max(parallel, 1))
+
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			target, err := DialSSH(ctx, host, cfg)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			defer target.Close()
+
+			target.Root = root
+			errs[i] = fn(ctx, i, target)
+		}()
+	}
+	wg.Wait()
+
+	return errs
+}
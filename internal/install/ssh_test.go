@@ -0,0 +1,231 @@
+package install
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/morpher-vm/morpherctl/internal/sshtest"
+)
+
+func TestParseSSHHosts(t *testing.T) {
+	tests := []struct {
+		name          string
+		spec          string
+		expected      []SSHHost
+		expectedError string
+	}{
+		{
+			name:     "should default the port",
+			spec:     "admin@hv1",
+			expected: []SSHHost{{User: "admin", Addr: "hv1:22"}},
+		},
+		{
+			name: "should inherit the user of the previous host",
+			spec: "admin@hv1,hv2:2222, ops@hv3,hv4",
+			expected: []SSHHost{
+				{User: "admin", Addr: "hv1:22"},
+				{User: "admin", Addr: "hv2:2222"},
+				{User: "ops", Addr: "hv3:22"},
+				{User: "ops", Addr: "hv4:22"},
+			},
+		},
+		{
+			name:     "should accept IPv6 addresses",
+			spec:     "root@[fd00::1]",
+			expected: []SSHHost{{User: "root", Addr: "[fd00::1]:22"}},
+		},
+		{
+			name:          "should reject empty user",
+			spec:          "@hv1",
+			expectedError: "invalid SSH host",
+		},
+		{
+			name:          "should reject empty list",
+			spec:          " , ",
+			expectedError: "no SSH hosts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hosts, err := ParseSSHHosts(tt.spec)
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, hosts)
+		})
+	}
+}
+
+// sshFixture is an in-process SSH server with a client key and known_hosts
+// file trusting it.
+type sshFixture struct {
+	server     *sshtest.Server
+	keyFile    string
+	knownHosts string
+}
+
+// newSSHFixture starts an SSH server that runs commands with sh, except for
+// systemctl, and strips sudo.
+func newSSHFixture(t *testing.T) *sshFixture {
+	t.Helper()
+
+	for _, tool := range []string{"sh", "sha256sum"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s is not available", tool)
+		}
+	}
+
+	signer, key, err := sshtest.NewKey()
+	require.NoError(t, err)
+
+	handler := func(command string, stdin io.Reader, stdout, stderr io.Writer) int {
+		command = strings.TrimPrefix(command, "sudo -n ")
+		if strings.Contains(command, "systemctl") {
+			return 0
+		}
+		return sshtest.ShellHandler(command, stdin, stdout, stderr)
+	}
+	server, err := sshtest.NewServer(handler, signer.PublicKey())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = server.Close() })
+
+	dir := t.TempDir()
+	fixture := &sshFixture{
+		server:     server,
+		keyFile:    filepath.Join(dir, "id_ed25519"),
+		knownHosts: filepath.Join(dir, "known_hosts"),
+	}
+	require.NoError(t, os.WriteFile(fixture.keyFile, key, 0o600))
+	require.NoError(t, os.WriteFile(fixture.knownHosts, []byte(server.KnownHosts()), 0o600))
+	return fixture
+}
+
+func TestApplySSH(t *testing.T) {
+	fixture := newSSHFixture(t)
+	binary, _ := writeBinary(t)
+	plan, err := NewPlan(Options{Binary: binary, ControllerURL: "https://controller:9000", Start: true})
+	require.NoError(t, err)
+
+	cfg, closeConfig, err := SSHConfig{
+		IdentityFiles:  []string{fixture.keyFile},
+		KnownHostsFile: fixture.knownHosts,
+		Timeout:        5 * time.Second,
+	}.ClientConfig()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = closeConfig() })
+
+	t.Run("should upload files and start the service", func(t *testing.T) {
+		root := t.TempDir()
+		hosts := []SSHHost{{User: "root", Addr: fixture.server.Addr()}}
+
+		errs := ApplySSH(context.Background(), plan, hosts, cfg, root, 2)
+		require.NoError(t, errs[0])
+
+		installed, err := os.ReadFile(filepath.Join(root, "opt/morpher/bin/morpher-agent"))
+		require.NoError(t, err)
+		assert.Equal(t, plan.Files[0].Content, installed)
+
+		info, err := os.Stat(filepath.Join(root, "opt/morpher/etc/agent.yaml"))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+		commands := fixture.server.Commands()
+		assert.Contains(t, commands, "sh -c 'systemctl enable --now morpher-agent.service'")
+		for _, command := range commands {
+			if strings.Contains(command, "cat >") {
+				assert.Contains(t, command, "(umask 077 && cat >")
+			}
+		}
+	})
+
+	t.Run("should use sudo for other users", func(t *testing.T) {
+		hosts := []SSHHost{{User: "deploy", Addr: fixture.server.Addr()}}
+
+		errs := ApplySSH(context.Background(), plan, hosts, cfg, t.TempDir(), 1)
+		require.NoError(t, errs[0])
+
+		commands := fixture.server.Commands()
+		assert.True(t, strings.HasPrefix(commands[len(commands)-1], "sudo -n sh -c "))
+	})
+
+	t.Run("should report every host", func(t *testing.T) {
+		hosts := []SSHHost{
+			{User: "root", Addr: fixture.server.Addr()},
+			{User: "root", Addr: "127.0.0.1:1"},
+		}
+
+		errs := ApplySSH(context.Background(), plan, hosts, cfg, t.TempDir(), 2)
+		require.Len(t, errs, 2)
+		assert.NoError(t, errs[0])
+		require.Error(t, errs[1])
+		assert.Contains(t, errs[1].Error(), "failed to connect to 127.0.0.1:1")
+	})
+
+	t.Run("should give up on a host that never completes the handshake", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = listener.Close() })
+		go func() {
+			// Accept connections but never speak SSH.
+			var conns []net.Conn
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					for _, conn := range conns {
+						_ = conn.Close()
+					}
+					return
+				}
+				conns = append(conns, conn)
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		errs := ApplySSH(ctx, plan, []SSHHost{{User: "root", Addr: listener.Addr().String()}}, cfg, t.TempDir(), 1)
+		require.Error(t, errs[0])
+		assert.ErrorIs(t, errs[0], context.DeadlineExceeded)
+	})
+
+	t.Run("should reject unknown host key", func(t *testing.T) {
+		other := filepath.Join(t.TempDir(), "known_hosts")
+		require.NoError(t, os.WriteFile(other, nil, 0o600))
+		cfg, closeConfig, err := SSHConfig{IdentityFiles: []string{fixture.keyFile}, KnownHostsFile: other}.ClientConfig()
+		require.NoError(t, err)
+		defer closeConfig()
+
+		errs := ApplySSH(context.Background(), plan, []SSHHost{{User: "root", Addr: fixture.server.Addr()}}, cfg, t.TempDir(), 1)
+		require.Error(t, errs[0])
+		assert.Contains(t, errs[0].Error(), "key is unknown")
+	})
+
+	t.Run("should reject unauthorized key", func(t *testing.T) {
+		_, key, err := sshtest.NewKey()
+		require.NoError(t, err)
+		keyFile := filepath.Join(t.TempDir(), "id_ed25519")
+		require.NoError(t, os.WriteFile(keyFile, key, 0o600))
+		t.Setenv("SSH_AUTH_SOCK", "")
+
+		cfg, closeConfig, err := SSHConfig{IdentityFiles: []string{keyFile}, KnownHostsFile: fixture.knownHosts}.ClientConfig()
+		require.NoError(t, err)
+		defer closeConfig()
+
+		errs := ApplySSH(context.Background(), plan, []SSHHost{{User: "root", Addr: fixture.server.Addr()}}, cfg, t.TempDir(), 1)
+		require.Error(t, errs[0])
+		assert.Contains(t, errs[0].Error(), "unable to authenticate")
+	})
+}
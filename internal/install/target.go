@@ -0,0 +1,153 @@
+package install
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Target is a machine the agent is installed on. Paths are absolute paths
+// on the target.
+type Target interface {
+	// WriteFile writes a file, creating its parent directories.
+	WriteFile(ctx context.Context, path string, content []byte, mode fs.FileMode) error
+
+	// SHA256 returns the hex checksum of a file.
+	SHA256(ctx context.Context, path string) (string, error)
+
+	// Run runs a command.
+	Run(ctx context.Context, command []string) error
+
+	// List returns the path and, for a directory, every path below it in
+	// lexical order. It returns nothing if the path does not exist.
+	List(ctx context.Context, path string) ([]string, error)
+
+	// Remove removes a file or an empty directory. It does nothing if the
+	// path does not exist.
+	Remove(ctx context.Context, path string) error
+}
+
+// Apply writes the files of the plan to the target, verifies the installed
+// binary and runs the commands of the plan.
+func Apply(ctx context.Context, plan *Plan, target Target) error {
+	for _, file := range plan.Files {
+		if err := target.WriteFile(ctx, file.Path, file.Content, file.Mode); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.Path, err)
+		}
+	}
+
+	checksum, err := target.SHA256(ctx, plan.BinaryPath)
+	if err != nil {
+		return fmt.Errorf("failed to verify %s: %w", plan.BinaryPath, err)
+	}
+	if checksum != plan.BinarySHA256 {
+		return fmt.Errorf("checksum mismatch for installed %s: expected sha256 %s, got %s",
+			plan.BinaryPath, plan.BinarySHA256, checksum)
+	}
+
+	for _, command := range plan.Commands {
+		if err := target.Run(ctx, command); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LocalTarget installs on this machine. All paths are relative to Root,
+// which allows installing into a staging directory.
+type LocalTarget struct {
+	Root string
+}
+
+// WriteFile writes the file atomically by renaming a temporary file.
+func (t LocalTarget) WriteFile(_ context.Context, path string, content []byte, mode fs.FileMode) error {
+	name := t.path(path)
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// SHA256 returns the hex checksum of a file.
+func (t LocalTarget) SHA256(_ context.Context, path string) (string, error) {
+	file, err := os.Open(t.path(path))
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Run runs a command and includes its output in the error if it fails.
+func (t LocalTarget) Run(ctx context.Context, command []string) error {
+	output, err := exec.CommandContext(ctx, command[0], command[1:]...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed: %w: %s", strings.Join(command, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// List returns the path and every path below it.
+func (t LocalTarget) List(_ context.Context, name string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(t.path(name), func(local string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(t.path("/"), local)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, path.Join("/", filepath.ToSlash(rel)))
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return paths, err
+}
+
+// Remove removes a file or an empty directory.
+func (t LocalTarget) Remove(_ context.Context, path string) error {
+	if err := os.Remove(t.path(path)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path returns the local path of a target path.
+func (t LocalTarget) path(path string) string {
+	return filepath.Join(t.Root, filepath.FromSlash(path))
+}
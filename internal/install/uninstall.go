@@ -0,0 +1,103 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"path"
+)
+
+// UninstallResult lists the paths an uninstallation removed and the paths
+// that are still present afterwards.
+type UninstallResult struct {
+	Removed    []string
+	LeftBehind []string
+}
+
+// StopService stops and disables the agent service.
+func StopService(ctx context.Context, target Target) error {
+	return target.Run(ctx, []string{"systemctl", "disable", "--now", ServiceName + ".service"})
+}
+
+// RemoveFiles removes the files written by the installation, the binary, the
+// configuration and the systemd unit, then removes the directories of the
+// install path if they are empty, and checks which paths are gone. Anything
+// else below the install path is left in place. With reload, systemd is
+// reloaded after the unit is removed. Removal continues past errors so that
+// as much as possible is cleaned up; the first error is returned with the
+// result.
+func RemoveFiles(ctx context.Context, target Target, installPath string, reload bool) (UninstallResult, error) {
+	var result UninstallResult
+	if !path.IsAbs(installPath) || path.Clean(installPath) == "/" {
+		return result, fmt.Errorf("refusing to remove install path %q", installPath)
+	}
+	installPath = path.Clean(installPath)
+
+	binDir, etcDir := path.Dir(BinaryPath(installPath)), path.Dir(ConfigPath(installPath))
+	files := []string{BinaryPath(installPath), ConfigPath(installPath), UnitPath}
+	// Directories are removed deepest first, so that the install path is
+	// empty once its subdirectories are gone.
+	dirs := []string{binDir, etcDir, installPath}
+	paths := []string{installPath, binDir, BinaryPath(installPath), etcDir, ConfigPath(installPath), UnitPath}
+
+	var before []string
+	for _, p := range paths {
+		exists, err := pathExists(ctx, target, p)
+		if err != nil {
+			return result, fmt.Errorf("failed to list %s: %w", p, err)
+		}
+		if exists {
+			before = append(before, p)
+		}
+	}
+
+	var firstErr error
+	for _, p := range files {
+		if err := target.Remove(ctx, p); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to remove %s: %w", p, err)
+		}
+	}
+	for _, dir := range dirs {
+		listed, err := target.List(ctx, dir)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to list %s: %w", dir, err)
+			}
+			continue
+		}
+		// Only the directory itself is listed when it is empty.
+		if len(listed) != 1 {
+			continue
+		}
+		if err := target.Remove(ctx, dir); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to remove %s: %w", dir, err)
+		}
+	}
+	if reload {
+		if err := target.Run(ctx, []string{"systemctl", "daemon-reload"}); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	for _, p := range before {
+		exists, err := pathExists(ctx, target, p)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to verify removal of %s: %w", p, err)
+			}
+			// Without a listing, assume the path was not removed.
+			exists = true
+		}
+		if exists {
+			result.LeftBehind = append(result.LeftBehind, p)
+		} else {
+			result.Removed = append(result.Removed, p)
+		}
+	}
+	return result, firstErr
+}
+
+// pathExists reports whether the path exists on the target.
+func pathExists(ctx context.Context, target Target, name string) (bool, error) {
+	listed, err := target.List(ctx, name)
+	return len(listed) > 0, err
+}
@@ -0,0 +1,94 @@
+package install
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stuckTarget is a local target that fails to remove some paths and records
+// the commands it runs.
+type stuckTarget struct {
+	LocalTarget
+	stuck    string
+	commands [][]string
+}
+
+func (t *stuckTarget) Remove(ctx context.Context, path string) error {
+	if path == t.stuck {
+		return errors.New("device or resource busy")
+	}
+	return t.LocalTarget.Remove(ctx, path)
+}
+
+func (t *stuckTarget) Run(_ context.Context, command []string) error {
+	t.commands = append(t.commands, command)
+	return nil
+}
+
+func TestRemoveFiles(t *testing.T) {
+	install := func(t *testing.T) string {
+		t.Helper()
+		binary, _ := writeBinary(t)
+		plan, err := NewPlan(Options{Binary: binary, ControllerURL: "https://controller:9000", InstallPath: "/srv/morpher"})
+		require.NoError(t, err)
+
+		root := t.TempDir()
+		require.NoError(t, Apply(context.Background(), plan, LocalTarget{Root: root}))
+		return root
+	}
+
+	t.Run("should remove install path and unit", func(t *testing.T) {
+		root := install(t)
+		target := &stuckTarget{LocalTarget: LocalTarget{Root: root}}
+		result, err := RemoveFiles(context.Background(), target, "/srv/morpher", true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"/srv/morpher",
+			"/srv/morpher/bin",
+			"/srv/morpher/bin/morpher-agent",
+			"/srv/morpher/etc",
+			"/srv/morpher/etc/agent.yaml",
+			UnitPath,
+		}, result.Removed)
+		assert.Empty(t, result.LeftBehind)
+		assert.Equal(t, [][]string{{"systemctl", "daemon-reload"}}, target.commands)
+
+		_, err = os.Stat(filepath.Join(root, "srv/morpher"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("should report paths left behind", func(t *testing.T) {
+		root := install(t)
+		target := &stuckTarget{LocalTarget: LocalTarget{Root: root}, stuck: "/srv/morpher/bin/morpher-agent"}
+		result, err := RemoveFiles(context.Background(), target, "/srv/morpher/", false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "device or resource busy")
+		assert.Equal(t, []string{"/srv/morpher/etc", "/srv/morpher/etc/agent.yaml", UnitPath}, result.Removed)
+		assert.Equal(t, []string{"/srv/morpher", "/srv/morpher/bin", "/srv/morpher/bin/morpher-agent"}, result.LeftBehind)
+		assert.Empty(t, target.commands)
+	})
+
+	t.Run("should only remove what was installed", func(t *testing.T) {
+		root := install(t)
+		other := filepath.Join(root, "srv/morpher/bin/other-tool")
+		require.NoError(t, os.WriteFile(other, []byte("keep"), 0o755))
+
+		result, err := RemoveFiles(context.Background(), LocalTarget{Root: root}, "/srv/morpher", false)
+		require.NoError(t, err)
+		assert.Contains(t, result.Removed, "/srv/morpher/bin/morpher-agent")
+		assert.Equal(t, []string{"/srv/morpher", "/srv/morpher/bin"}, result.LeftBehind)
+		assert.FileExists(t, other)
+	})
+
+	t.Run("should refuse to remove root", func(t *testing.T) {
+		_, err := RemoveFiles(context.Background(), LocalTarget{Root: t.TempDir()}, "/", false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "refusing to remove")
+	})
+}
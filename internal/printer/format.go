@@ -0,0 +1,53 @@
+package printer
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// None is printed in table cells without a value.
+const None = "<none>"
+
+// HumanAge renders the time elapsed since t in its largest unit, for example
+// "45s", "12m", "3h" or "5d". A zero time is rendered as "-".
+func HumanAge(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	age := max(time.Since(t), 0)
+	switch {
+	case age < time.Minute:
+		return fmt.Sprintf("%ds", int(age.Seconds()))
+	case age < time.Hour:
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(age.Hours()/24))
+	}
+}
+
+// FormatLabels renders labels as sorted key=value pairs separated by commas.
+func FormatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return None
+	}
+
+	pairs := make([]string, 0, len(labels))
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		pairs = append(pairs, key+"="+labels[key])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// ValueOrNone returns value, or None if it is empty.
+func ValueOrNone(value string) string {
+	if value == "" {
+		return None
+	}
+	return value
+}
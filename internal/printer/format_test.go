@@ -0,0 +1,39 @@
+package printer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHumanAge(t *testing.T) {
+	tests := []struct {
+		name     string
+		t        time.Time
+		expected string
+	}{
+		{name: "should render zero time as dash", expected: "-"},
+		{name: "should render seconds", t: time.Now().Add(-45 * time.Second), expected: "45s"},
+		{name: "should render minutes", t: time.Now().Add(-12*time.Minute - 30*time.Second), expected: "12m"},
+		{name: "should render hours", t: time.Now().Add(-30 * time.Hour), expected: "30h"},
+		{name: "should render days", t: time.Now().Add(-5*24*time.Hour - time.Hour), expected: "5d"},
+		{name: "should clamp future times", t: time.Now().Add(time.Hour), expected: "0s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, HumanAge(tt.t))
+		})
+	}
+}
+
+func TestFormatLabels(t *testing.T) {
+	assert.Equal(t, None, FormatLabels(nil))
+	assert.Equal(t, "role=compute,zone=a", FormatLabels(map[string]string{"zone": "a", "role": "compute"}))
+}
+
+func TestValueOrNone(t *testing.T) {
+	assert.Equal(t, None, ValueOrNone(""))
+	assert.Equal(t, "v1.4.0", ValueOrNone("v1.4.0"))
+}
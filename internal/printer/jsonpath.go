@@ -0,0 +1,296 @@
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSONPath is a compiled JSONPath template in the style of kubectl, such as
+// "{.items[*].name}" or "{range .items[*]}{.id}{\"\\n\"}{end}".
+// Fields are resolved against the JSON representation of the object.
+type JSONPath struct {
+	nodes []jsonPathNode
+}
+
+type jsonPathNode struct {
+	text     string
+	path     []pathSegment
+	isPath   bool
+	children []jsonPathNode
+	isRange  bool
+}
+
+type pathSegment struct {
+	field    string
+	index    int
+	wildcard bool
+	isIndex  bool
+}
+
+// ParseJSONPath compiles a JSONPath template.
+func ParseJSONPath(template string) (*JSONPath, error) {
+	nodes, _, foundEnd, err := parseJSONPathNodes(template)
+	if err != nil {
+		return nil, err
+	}
+	if foundEnd {
+		return nil, fmt.Errorf("invalid jsonpath %q: {end} without {range}", template)
+	}
+	return &JSONPath{nodes: nodes}, nil
+}
+
+// parseJSONPathNodes parses nodes until the end of input or the next {end},
+// returning the unparsed remainder and whether {end} was found.
+func parseJSONPathNodes(template string) ([]jsonPathNode, string, bool, error) {
+	var nodes []jsonPathNode
+	for template != "" {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			nodes = append(nodes, jsonPathNode{text: unescape(template)})
+			break
+		}
+		if start > 0 {
+			nodes = append(nodes, jsonPathNode{text: unescape(template[:start])})
+		}
+
+		end := closingBrace(template[start:])
+		if end < 0 {
+			return nil, "", false, fmt.Errorf("invalid jsonpath %q: unclosed '{'", template)
+		}
+		expr := strings.TrimSpace(template[start+1 : start+end])
+		template = template[start+end+1:]
+
+		switch {
+		case expr == "end":
+			return nodes, template, true, nil
+		case strings.HasPrefix(expr, "range "):
+			rangeExpr := strings.TrimSpace(strings.TrimPrefix(expr, "range "))
+			path, err := parsePath(rangeExpr)
+			if err != nil {
+				return nil, "", false, err
+			}
+			children, rest, foundEnd, err := parseJSONPathNodes(template)
+			if err != nil {
+				return nil, "", false, err
+			}
+			if !foundEnd {
+				return nil, "", false, fmt.Errorf("invalid jsonpath: {range %s} is missing {end}", rangeExpr)
+			}
+			nodes = append(nodes, jsonPathNode{path: path, isRange: true, children: children})
+			template = rest
+		case len(expr) >= 2 && expr[0] == '"' && expr[len(expr)-1] == '"':
+			text, err := strconv.Unquote(expr)
+			if err != nil {
+				return nil, "", false, fmt.Errorf("invalid jsonpath string %s: %w", expr, err)
+			}
+			nodes = append(nodes, jsonPathNode{text: text})
+		default:
+			path, err := parsePath(expr)
+			if err != nil {
+				return nil, "", false, err
+			}
+			nodes = append(nodes, jsonPathNode{path: path, isPath: true})
+		}
+	}
+
+	return nodes, "", false, nil
+}
+
+// closingBrace returns the index of the '}' that closes the expression at
+// the start of s, skipping braces inside quoted strings, or -1.
+func closingBrace(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '}':
+			return i
+		}
+	}
+	return -1
+}
+
+// parsePath parses an expression such as ".items[*].labels.site" or "$.name".
+func parsePath(expr string) ([]pathSegment, error) {
+	rest := strings.TrimPrefix(strings.TrimPrefix(expr, "$"), "@")
+
+	var segments []pathSegment
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			rest = rest[end:]
+			switch name {
+			case "":
+				if rest != "" {
+					return nil, fmt.Errorf("invalid jsonpath expression %q: empty field name", expr)
+				}
+			case "*":
+				segments = append(segments, pathSegment{wildcard: true})
+			default:
+				segments = append(segments, pathSegment{field: name})
+			}
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid jsonpath expression %q: unclosed '['", expr)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				segments = append(segments, pathSegment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, pathSegment{field: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid jsonpath expression %q: invalid index %q", expr, inner)
+				}
+				segments = append(segments, pathSegment{index: index, isIndex: true})
+			}
+		default:
+			return nil, fmt.Errorf("invalid jsonpath expression %q: must start with '.' or '['", expr)
+		}
+	}
+
+	return segments, nil
+}
+
+// Execute writes the template evaluated against the JSON form of obj.
+func (j *JSONPath) Execute(w io.Writer, obj any) error {
+	data, err := toJSONValue(obj)
+	if err != nil {
+		return err
+	}
+	return executeNodes(w, j.nodes, data)
+}
+
+func executeNodes(w io.Writer, nodes []jsonPathNode, data any) error {
+	for _, node := range nodes {
+		switch {
+		case node.isRange:
+			for _, item := range evalPath(node.path, data) {
+				if err := executeNodes(w, node.children, item); err != nil {
+					return err
+				}
+			}
+		case node.isPath:
+			results := evalPath(node.path, data)
+			values := make([]string, 0, len(results))
+			for _, result := range results {
+				value, err := formatValue(result)
+				if err != nil {
+					return err
+				}
+				values = append(values, value)
+			}
+			if _, err := io.WriteString(w, strings.Join(values, " ")); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+		default:
+			if _, err := io.WriteString(w, node.text); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// evalPath resolves a path against data. Missing fields yield no results.
+func evalPath(path []pathSegment, data any) []any {
+	current := []any{data}
+	for _, segment := range path {
+		var next []any
+		for _, value := range current {
+			switch v := value.(type) {
+			case map[string]any:
+				switch {
+				case segment.wildcard:
+					keys := make([]string, 0, len(v))
+					for key := range v {
+						keys = append(keys, key)
+					}
+					sort.Strings(keys)
+					for _, key := range keys {
+						next = append(next, v[key])
+					}
+				case !segment.isIndex:
+					if field, ok := v[segment.field]; ok {
+						next = append(next, field)
+					}
+				}
+			case []any:
+				switch {
+				case segment.wildcard:
+					next = append(next, v...)
+				case segment.isIndex:
+					index := segment.index
+					if index < 0 {
+						index += len(v)
+					}
+					if index >= 0 && index < len(v) {
+						next = append(next, v[index])
+					}
+				}
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+// formatValue renders scalars as plain text and everything else as JSON.
+func formatValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case map[string]any, []any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode value: %w", err)
+		}
+		return string(data), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// toJSONValue converts obj to the generic form produced by decoding its JSON.
+func toJSONValue(obj any) (any, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode object: %w", err)
+	}
+
+	var value any
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode object: %w", err)
+	}
+	return value, nil
+}
+
+// unescape interprets the escape sequences commonly passed on the command line.
+func unescape(text string) string {
+	return strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\r`, "\r").Replace(text)
+}
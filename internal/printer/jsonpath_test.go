@@ -0,0 +1,80 @@
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONPath_Execute(t *testing.T) {
+	obj := map[string]any{
+		"kind": "AgentList",
+		"items": []map[string]any{
+			{"id": "agent-1", "labels": map[string]string{"site": "dc1"}, "active": 2},
+			{"id": "agent-2", "labels": map[string]string{"site": "dc2", "tier": "gold"}, "active": 0},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{name: "should print a field", template: "{.kind}", expected: "AgentList"},
+		{name: "should accept root prefix", template: "{$.kind}", expected: "AgentList"},
+		{name: "should print literal text", template: "kind={.kind}\\n", expected: "kind=AgentList\n"},
+		{name: "should index arrays", template: "{.items[0].id}", expected: "agent-1"},
+		{name: "should support negative indexes", template: "{.items[-1].id}", expected: "agent-2"},
+		{name: "should expand wildcards", template: "{.items[*].id}", expected: "agent-1 agent-2"},
+		{name: "should support quoted fields", template: "{.items[1].labels['tier']}", expected: "gold"},
+		{name: "should print numbers", template: "{.items[*].active}", expected: "2 0"},
+		{name: "should print objects as json", template: "{.items[0].labels}", expected: `{"site":"dc1"}`},
+		{name: "should ignore missing fields", template: "{.items[*].labels.tier}", expected: "gold"},
+		{name: "should print quoted strings", template: `{.kind}{"\t"}{.items[0].id}`, expected: "AgentList\tagent-1"},
+		{name: "should print quoted braces", template: `{"}"}{.kind}{"\n}"}`, expected: "}AgentList\n}"},
+		{name: "should print escaped quotes", template: `{"\"}"}`, expected: `"}`},
+		{name: "should support braces in quoted fields", template: "{.items[0].labels['}']}", expected: ""},
+		{
+			name:     "should iterate ranges",
+			template: `{range .items[*]}{.id}={.labels.site}{"\n"}{end}`,
+			expected: "agent-1=dc1\nagent-2=dc2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonPath, err := ParseJSONPath(tt.template)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			require.NoError(t, jsonPath.Execute(&buf, obj))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestParseJSONPath_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{name: "should reject unclosed brace", template: "{.kind", expected: "unclosed '{'"},
+		{name: "should reject unclosed string", template: `{"}`, expected: "unclosed '{'"},
+		{name: "should reject unclosed bracket", template: "{.items[0}", expected: "unclosed '['"},
+		{name: "should reject invalid index", template: "{.items[x]}", expected: "invalid index"},
+		{name: "should reject range without end", template: "{range .items[*]}{.id}", expected: "missing {end}"},
+		{name: "should reject end without range", template: "{.kind}{end}", expected: "{end} without {range}"},
+		{name: "should reject relative expressions", template: "{kind}", expected: "must start with"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseJSONPath(tt.template)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Supported output formats.
+const (
+	FormatTable      = "table"
+	FormatWide       = "wide"
+	FormatJSON       = "json"
+	FormatYAML       = "yaml"
+	FormatName       = "name"
+	FormatJSONPath   = "jsonpath"
+	FormatGoTemplate = "go-template"
+)
+
+// Printer writes objects to an output stream in a specific format.
+type Printer interface {
+	Print(w io.Writer, obj any) error
+}
+
+// Tabular is implemented by objects that can be rendered as a table.
+// Wide tables may include additional columns. A nil header prints the rows
+// without a header line.
+type Tabular interface {
+	TableHeader(wide bool) []string
+	TableRows(wide bool) [][]string
+}
+
+// Named is implemented by objects that can be printed with the name format.
+type Named interface {
+	Names() []string
+}
+
+// New returns the printer for the given output format. The jsonpath and
+// go-template formats take their template after an equals sign, for example
+// "jsonpath={.version}".
+func New(format string) (Printer, error) {
+	name, arg, hasArg := strings.Cut(format, "=")
+
+	switch name {
+	case "", FormatTable:
+		return &TablePrinter{}, nil
+	case FormatWide:
+		return &TablePrinter{Wide: true}, nil
+	case FormatJSON:
+		return &JSONPrinter{}, nil
+	case FormatYAML:
+		return &YAMLPrinter{}, nil
+	case FormatName:
+		return &NamePrinter{}, nil
+	case FormatJSONPath:
+		if !hasArg || arg == "" {
+			return nil, fmt.Errorf("output format %s requires a template, for example %s={.name}", name, name)
+		}
+		jsonPath, err := ParseJSONPath(arg)
+		if err != nil {
+			return nil, err
+		}
+		return &JSONPathPrinter{jsonPath: jsonPath}, nil
+	case FormatGoTemplate:
+		if !hasArg || arg == "" {
+			return nil, fmt.Errorf("output format %s requires a template, for example %s={{.name}}", name, name)
+		}
+		tmpl, err := template.New("output").Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid go-template: %w", err)
+		}
+		return &TemplatePrinter{template: tmpl}, nil
+	default:
+		return nil, fmt.Errorf("unsupported output format %q: must be one of %s",
+			format, strings.Join(SupportedFormats(), ", "))
+	}
+}
+
+// SupportedFormats returns the list of supported output formats.
+func SupportedFormats() []string {
+	return []string{
+		FormatTable, FormatWide, FormatJSON, FormatYAML, FormatName,
+		FormatJSONPath + "=...", FormatGoTemplate + "=...",
+	}
+}
+
+// IsStructured reports whether the format is meant for machines rather than humans.
+// Commands write progress and other informational messages to stderr for
+// structured formats so stdout can be parsed.
+func IsStructured(format string) bool {
+	name, _, _ := strings.Cut(format, "=")
+	return name != "" && name != FormatTable && name != FormatWide
+}
+
+// TablePrinter prints objects as aligned columns.
+type TablePrinter struct {
+	Wide bool
+}
+
+// Print writes obj as a table.
+func (p *TablePrinter) Print(w io.Writer, obj any) error {
+	tabular, ok := obj.(Tabular)
+	if !ok {
+		return fmt.Errorf("table output is not supported for %T", obj)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	if header := tabular.TableHeader(p.Wide); len(header) > 0 {
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+	}
+	for _, row := range tabular.TableRows(p.Wide) {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write table: %w", err)
+	}
+	return nil
+}
+
+// JSONPrinter prints objects as indented JSON.
+type JSONPrinter struct{}
+
+// Print writes obj as JSON.
+func (p *JSONPrinter) Print(w io.Writer, obj any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(obj); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
+
+// YAMLPrinter prints objects as YAML.
+// Field names follow the JSON tags of the object so both formats match.
+type YAMLPrinter struct{}
+
+// Print writes obj as YAML.
+func (p *YAMLPrinter) Print(w io.Writer, obj any) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("failed to encode object: %w", err)
+	}
+
+	// JSON is valid YAML, so decoding it into a node keeps the field order.
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return fmt.Errorf("failed to convert object to YAML: %w", err)
+	}
+	resetStyle(&node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return fmt.Errorf("failed to write YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to write YAML: %w", err)
+	}
+	return nil
+}
+
+// resetStyle clears the flow and quoting styles inherited from JSON.
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+// NamePrinter prints the name of every object on its own line.
+type NamePrinter struct{}
+
+// Print writes the names of obj.
+func (p *NamePrinter) Print(w io.Writer, obj any) error {
+	named, ok := obj.(Named)
+	if !ok {
+		return fmt.Errorf("name output is not supported for %T", obj)
+	}
+
+	for _, name := range named.Names() {
+		if _, err := fmt.Fprintln(w, name); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+	return nil
+}
+
+// JSONPathPrinter prints the result of a JSONPath template.
+type JSONPathPrinter struct {
+	jsonPath *JSONPath
+}
+
+// Print writes the template evaluated against obj.
+func (p *JSONPathPrinter) Print(w io.Writer, obj any) error {
+	return p.jsonPath.Execute(w, obj)
+}
+
+// TemplatePrinter prints the result of a Go template.
+// The template is executed against the JSON form of the object.
+type TemplatePrinter struct {
+	template *template.Template
+}
+
+// Print writes the template executed against obj.
+func (p *TemplatePrinter) Print(w io.Writer, obj any) error {
+	data, err := toJSONValue(obj)
+	if err != nil {
+		return err
+	}
+
+	if err := p.template.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to execute go-template: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testObject struct {
+	Name   string            `json:"name"`
+	Count  int               `json:"count"`
+	Labels []string          `json:"labels,omitempty"`
+	Extra  map[string]string `json:"extra,omitempty"`
+}
+
+func (o testObject) TableHeader(wide bool) []string {
+	if wide {
+		return []string{"NAME", "COUNT", "LABELS"}
+	}
+	return []string{"NAME", "COUNT"}
+}
+
+func (o testObject) TableRows(wide bool) [][]string {
+	if wide {
+		return [][]string{{o.Name, "3", "a,b"}}
+	}
+	return [][]string{{o.Name, "3"}}
+}
+
+func (o testObject) Names() []string {
+	return []string{"object/" + o.Name}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      string
+		expectError string
+	}{
+		{name: "should default to table", format: ""},
+		{name: "should support table", format: FormatTable},
+		{name: "should support wide", format: FormatWide},
+		{name: "should support json", format: FormatJSON},
+		{name: "should support yaml", format: FormatYAML},
+		{name: "should support name", format: FormatName},
+		{name: "should support jsonpath", format: "jsonpath={.name}"},
+		{name: "should support go-template", format: "go-template={{.name}}"},
+		{name: "should require a jsonpath template", format: "jsonpath", expectError: "requires a template"},
+		{name: "should require a go-template", format: "go-template=", expectError: "requires a template"},
+		{name: "should reject invalid go-template", format: "go-template={{.name", expectError: "invalid go-template"},
+		{name: "should reject invalid jsonpath", format: "jsonpath={.name", expectError: "unclosed"},
+		{name: "should reject unknown format", format: "xml", expectError: "unsupported output format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(tt.format)
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, p)
+		})
+	}
+}
+
+func TestPrinters(t *testing.T) {
+	obj := testObject{Name: "controller-1", Count: 3, Labels: []string{"a", "b"}}
+
+	tests := []struct {
+		name     string
+		format   string
+		expected string
+	}{
+		{
+			name:     "should print table",
+			format:   FormatTable,
+			expected: "NAME           COUNT\ncontroller-1   3\n",
+		},
+		{
+			name:     "should print wide table",
+			format:   FormatWide,
+			expected: "NAME           COUNT   LABELS\ncontroller-1   3       a,b\n",
+		},
+		{
+			name:     "should print json",
+			format:   FormatJSON,
+			expected: "{\n  \"name\": \"controller-1\",\n  \"count\": 3,\n  \"labels\": [\n    \"a\",\n    \"b\"\n  ]\n}\n",
+		},
+		{
+			name:     "should print yaml using json field names in order",
+			format:   FormatYAML,
+			expected: "name: controller-1\ncount: 3\nlabels:\n  - a\n  - b\n",
+		},
+		{
+			name:     "should print names",
+			format:   FormatName,
+			expected: "object/controller-1\n",
+		},
+		{
+			name:     "should print jsonpath",
+			format:   `jsonpath={.name} has {.count} labels: {.labels[*]}\n`,
+			expected: "controller-1 has 3 labels: a b\n",
+		},
+		{
+			name:     "should print go-template",
+			format:   `go-template={{.name}}{{range .labels}} {{.}}{{end}}`,
+			expected: "controller-1 a b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(tt.format)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			require.NoError(t, p.Print(&buf, obj))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestPrinters_Unsupported(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := (&TablePrinter{}).Print(&buf, struct{}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "table output is not supported")
+
+	err = (&NamePrinter{}).Print(&buf, struct{}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "name output is not supported")
+}
+
+func TestIsStructured(t *testing.T) {
+	assert.False(t, IsStructured(""))
+	assert.False(t, IsStructured(FormatTable))
+	assert.False(t, IsStructured(FormatWide))
+	assert.True(t, IsStructured(FormatJSON))
+	assert.True(t, IsStructured(FormatName))
+	assert.True(t, IsStructured("jsonpath={.name}"))
+}
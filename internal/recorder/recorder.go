@@ -0,0 +1,144 @@
+package recorder
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Recorder is an http.RoundTripper that records every request and response
+// with secrets scrubbed. The session file is rewritten after each interaction
+// so that it is complete even if the command is interrupted.
+type Recorder struct {
+	path    string
+	next    http.RoundTripper
+	mu      sync.Mutex
+	session Session
+}
+
+// NewRecorder creates a recorder that sends requests through next and writes
+// the session to path. A nil next uses http.DefaultTransport.
+func NewRecorder(path string, next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{
+		path: path,
+		next: next,
+		session: Session{
+			Version:      sessionVersion,
+			RecordedAt:   time.Now().UTC(),
+			Interactions: []Interaction{},
+		},
+	}
+}
+
+// RoundTrip sends the request and records the interaction. Streamed
+// responses, such as followed agent logs, are passed on as they arrive and
+// recorded once their body is closed.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if isStream(resp) {
+		resp.Body = &recordingBody{
+			ReadCloser: resp.Body,
+			record: func(body []byte) error {
+				return r.record(req, reqBody, resp, body)
+			},
+		}
+		return resp, nil
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response for recording: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	if err := r.record(req, reqBody, resp, respBody); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// record adds an interaction to the session and saves it.
+func (r *Recorder) record(req *http.Request, reqBody string, resp *http.Response, respBody []byte) error {
+	interaction := Interaction{
+		Request: Request{
+			Method:  req.Method,
+			Path:    scrubPath(requestPath(req)),
+			Headers: scrubHeaders(req.Header),
+			Body:    scrubBody(reqBody),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Headers:    scrubHeaders(resp.Header),
+			Body:       scrubBody(string(respBody)),
+		},
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.session.Interactions = append(r.session.Interactions, interaction)
+	return r.session.Save(r.path)
+}
+
+// isStream reports whether a response is streamed as newline-delimited JSON
+// and may not end until the request is cancelled.
+func isStream(resp *http.Response) bool {
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/x-ndjson"
+}
+
+// recordingBody keeps a copy of a streamed response body and records it
+// when the body is closed.
+type recordingBody struct {
+	io.ReadCloser
+	record func(body []byte) error
+	buf    bytes.Buffer
+	once   sync.Once
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		if recordErr := b.record(b.buf.Bytes()); recordErr != nil && err == nil {
+			err = recordErr
+		}
+	})
+	return err
+}
+
+// readRequestBody reads the request body and restores it for sending.
+func readRequestBody(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return string(body), nil
+}
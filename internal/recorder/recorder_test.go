@@ -0,0 +1,180 @@
+package recorder
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/morpher-vm/morpherctl/pkg/client"
+	"github.com/morpher-vm/morpherctl/pkg/fakecontroller"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(fakecontroller.New(fakecontroller.WithToken("secret"), fakecontroller.WithDemoData()))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "session.json")
+	ctx := context.Background()
+
+	// Record a session against the fake controller.
+	recording, err := client.New(server.URL, client.WithToken("secret"), client.WithTransport(NewRecorder(path, nil)))
+	require.NoError(t, err)
+
+	ping, err := recording.Ping(ctx)
+	require.NoError(t, err)
+	require.True(t, ping.Success)
+	agents, err := recording.ListAgents(ctx, client.ListAgentsOptions{Status: client.AgentReady})
+	require.NoError(t, err)
+	require.Len(t, agents, 2)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret")
+	assert.Contains(t, string(data), redacted)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// Replay the session after the controller is gone.
+	server.Close()
+	session, err := LoadSession(path)
+	require.NoError(t, err)
+	require.Len(t, session.Interactions, 2)
+
+	replaying, err := client.New(server.URL, client.WithTransport(NewReplayer(session)))
+	require.NoError(t, err)
+
+	ping, err = replaying.Ping(ctx)
+	require.NoError(t, err)
+	assert.True(t, ping.Success)
+
+	replayed, err := replaying.ListAgents(ctx, client.ListAgentsOptions{Status: client.AgentReady})
+	require.NoError(t, err)
+	assert.Equal(t, agents, replayed)
+
+	_, err = replaying.ListAgents(ctx, client.ListAgentsOptions{Status: client.AgentNotReady})
+	var mismatch *MismatchError
+	require.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, "/agents?status=not_ready", mismatch.Path)
+	assert.Equal(t, "--- recorded\n+++ request\n- GET /agents?status=ready\n+ GET /agents?status=not_ready", mismatch.Diff)
+}
+
+func TestRecordStream(t *testing.T) {
+	first := client.LogEntry{Time: time.Now().UTC(), Level: client.LogInfo, Message: "agent started"}
+	fake := fakecontroller.New(
+		fakecontroller.WithAgents(client.Agent{ID: "agent-1"}),
+		fakecontroller.WithAgentLogs("agent-1", first),
+	)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "session.json")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Entries of a followed stream arrive before the stream ends.
+	recording, err := client.New(server.URL, client.WithTransport(NewRecorder(path, nil)))
+	require.NoError(t, err)
+	errDone := errors.New("done")
+	var messages []string
+	err = recording.StreamAgentLogs(ctx, "agent-1", client.AgentLogOptions{Follow: true}, func(entry client.LogEntry) error {
+		messages = append(messages, entry.Message)
+		if len(messages) == 1 {
+			fake.AppendAgentLog("agent-1", client.LogEntry{Time: time.Now().UTC(), Level: client.LogWarn, Message: "disk full"})
+			return nil
+		}
+		return errDone
+	})
+	require.ErrorIs(t, err, errDone)
+	assert.Equal(t, []string{"agent started", "disk full"}, messages)
+
+	// The stream is recorded once closed and replayed until the recording ends.
+	session, err := LoadSession(path)
+	require.NoError(t, err)
+	require.Len(t, session.Interactions, 1)
+
+	replaying, err := client.New(server.URL, client.WithTransport(NewReplayer(session)))
+	require.NoError(t, err)
+	messages = nil
+	err = replaying.StreamAgentLogs(ctx, "agent-1", client.AgentLogOptions{Follow: true}, func(entry client.LogEntry) error {
+		messages = append(messages, entry.Message)
+		return nil
+	})
+	require.ErrorIs(t, err, client.ErrLogStreamClosed)
+	assert.Equal(t, []string{"agent started", "disk full"}, messages)
+}
+
+func TestReplayer_Match(t *testing.T) {
+	session := &Session{
+		Version: sessionVersion,
+		Interactions: []Interaction{
+			{Request: Request{Method: "GET", Path: "/ping"}, Response: Response{StatusCode: 503}},
+			{Request: Request{Method: "GET", Path: "/ping"}, Response: Response{StatusCode: 200}},
+			{Request: Request{Method: "POST", Path: "/drain", Body: `{"b": 2, "a": 1}`}, Response: Response{StatusCode: 202}},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{name: "should use first recorded response", method: "GET", path: "/ping", expectedStatus: 503},
+		{name: "should use next recorded response", method: "GET", path: "/ping", expectedStatus: 200},
+		{name: "should repeat last recorded response", method: "GET", path: "/ping", expectedStatus: 200},
+		{name: "should match json body regardless of field order", method: "POST", path: "/drain", body: `{"a":1,"b":2}`, expectedStatus: 202},
+	}
+
+	replayer := NewReplayer(session)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "http://controller"+tt.path, nil)
+			if tt.body != "" {
+				req = httptest.NewRequest(tt.method, "http://controller"+tt.path, stringsReader(tt.body))
+			}
+
+			resp, err := replayer.RoundTrip(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+		})
+	}
+
+	t.Run("should diff request body", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "http://controller/drain", stringsReader(`{"a":1,"b":3}`))
+		_, err := replayer.RoundTrip(req)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "- {\"b\": 2, \"a\": 1}\n+ {\"a\":1,\"b\":3}")
+	})
+}
+
+func TestScrub(t *testing.T) {
+	headers := http.Header{"Authorization": {"Bearer abc"}, "Accept": {"application/json"}}
+	scrubbed := scrubHeaders(headers)
+	assert.Equal(t, redacted, scrubbed.Get("Authorization"))
+	assert.Equal(t, "application/json", scrubbed.Get("Accept"))
+	assert.Equal(t, "Bearer abc", headers.Get("Authorization"))
+
+	assert.Equal(t, "/join?name=a&token=REDACTED", scrubPath("/join?token=abc&name=a"))
+	assert.Equal(t, "/agents?status=ready", scrubPath("/agents?status=ready"))
+
+	assert.Equal(t, `{"Name":"a","Nested":{"JoinToken":"REDACTED"},"Password":"REDACTED"}`,
+		scrubBody(`{"Name": "a", "Password": "p", "Nested": {"JoinToken": "t"}}`))
+	assert.Equal(t, "plain text", scrubBody("plain text"))
+}
+
+func stringsReader(s string) *strings.Reader {
+	return strings.NewReader(s)
+}
@@ -0,0 +1,167 @@
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+)
+
+// Replayer is an http.RoundTripper that answers requests from a recorded
+// session without network access. Requests are matched by method, path and
+// body. Each interaction is used once in recording order; when all matching
+// interactions have been used the last one is repeated.
+type Replayer struct {
+	mu      sync.Mutex
+	session *Session
+	used    []bool
+}
+
+// MismatchError is returned when no recorded interaction matches a request.
+type MismatchError struct {
+	Method string
+	Path   string
+	// Diff compares the closest recorded request with the actual request.
+	Diff string
+}
+
+// Error returns the request and the diff against the closest recorded request.
+func (e *MismatchError) Error() string {
+	msg := fmt.Sprintf("no recorded response for %s %s", e.Method, e.Path)
+	if e.Diff != "" {
+		msg += "; closest recorded request differs:\n" + e.Diff
+	}
+	return msg
+}
+
+// NewReplayer creates a replayer for the given session.
+func NewReplayer(session *Session) *Replayer {
+	return &Replayer{
+		session: session,
+		used:    make([]bool, len(session.Interactions)),
+	}
+}
+
+// RoundTrip returns the recorded response matching the request.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	actual := Request{
+		Method: req.Method,
+		Path:   scrubPath(requestPath(req)),
+		Body:   scrubBody(body),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	index := r.match(actual)
+	if index < 0 {
+		// The invocation differs from the recorded one; retrying cannot help.
+		return nil, errdefs.Usage(r.mismatch(actual))
+	}
+	r.used[index] = true
+
+	recorded := r.session.Interactions[index].Response
+	headers := recorded.Headers.Clone()
+	if headers == nil {
+		headers = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        headers,
+		Body:          io.NopCloser(bytes.NewReader([]byte(recorded.Body))),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+// match returns the index of the first unused matching interaction, or the
+// last matching one if all were used, or -1.
+func (r *Replayer) match(actual Request) int {
+	last := -1
+	for i, interaction := range r.session.Interactions {
+		if !sameRequest(interaction.Request, actual) {
+			continue
+		}
+		if !r.used[i] {
+			return i
+		}
+		last = i
+	}
+	return last
+}
+
+// mismatch builds the error for a request without recorded response.
+func (r *Replayer) mismatch(actual Request) error {
+	err := &MismatchError{Method: actual.Method, Path: actual.Path}
+
+	closest := r.closest(actual)
+	if closest >= 0 {
+		err.Diff = diffLines(describe(r.session.Interactions[closest].Request), describe(actual))
+	}
+	return err
+}
+
+// closest returns the recorded request most similar to actual, preferring
+// unused interactions with the same method and path, then the same method.
+func (r *Replayer) closest(actual Request) int {
+	best, bestScore := -1, -1
+	for i, interaction := range r.session.Interactions {
+		score := 0
+		if interaction.Request.Method == actual.Method {
+			score += 2
+		}
+		if strings.SplitN(interaction.Request.Path, "?", 2)[0] == strings.SplitN(actual.Path, "?", 2)[0] {
+			score += 4
+		}
+		if !r.used[i] {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
+
+// sameRequest reports whether two requests match by method, path and body.
+func sameRequest(recorded, actual Request) bool {
+	return recorded.Method == actual.Method &&
+		recorded.Path == actual.Path &&
+		normalizeBody(recorded.Body) == normalizeBody(actual.Body)
+}
+
+// normalizeBody formats JSON bodies canonically so that field order and
+// whitespace do not affect matching.
+func normalizeBody(body string) string {
+	var v any
+	if json.Unmarshal([]byte(body), &v) != nil {
+		return strings.TrimSpace(body)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return strings.TrimSpace(body)
+	}
+	return string(data)
+}
+
+// describe renders a request for diffing.
+func describe(req Request) string {
+	s := req.Method + " " + req.Path
+	if body := strings.TrimSpace(req.Body); body != "" {
+		s += "\n\n" + body
+	}
+	return s
+}
@@ -0,0 +1,114 @@
+package recorder
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// redacted replaces scrubbed secrets.
+const redacted = "REDACTED"
+
+// sensitiveHeaders are always scrubbed from recorded requests and responses.
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+// sensitiveKeys are substrings of JSON field and query parameter names whose
+// values are scrubbed.
+var sensitiveKeys = []string{"token", "password", "secret", "key", "credential"}
+
+// isSensitiveKey reports whether a field or parameter name holds a secret.
+func isSensitiveKey(name string) bool {
+	name = strings.ToLower(name)
+	for _, key := range sensitiveKeys {
+		if strings.Contains(name, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// scrubHeaders returns a copy of the headers with secrets replaced.
+func scrubHeaders(headers http.Header) http.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	scrubbed := headers.Clone()
+	for _, name := range sensitiveHeaders {
+		if scrubbed.Get(name) != "" {
+			scrubbed.Set(name, redacted)
+		}
+	}
+	return scrubbed
+}
+
+// scrubPath replaces the values of sensitive query parameters.
+func scrubPath(path string) string {
+	p, rawQuery, ok := strings.Cut(path, "?")
+	if !ok {
+		return path
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return path
+	}
+	changed := false
+	for name := range query {
+		if isSensitiveKey(name) {
+			query.Set(name, redacted)
+			changed = true
+		}
+	}
+	if !changed {
+		return path
+	}
+	return p + "?" + query.Encode()
+}
+
+// scrubBody replaces the values of sensitive fields in JSON bodies.
+// Other bodies are returned unchanged.
+func scrubBody(body string) string {
+	var v any
+	if body == "" || json.Unmarshal([]byte(body), &v) != nil {
+		return body
+	}
+	if !scrubValue(v) {
+		return body
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return string(data)
+}
+
+// scrubValue scrubs v in place and reports whether anything was replaced.
+func scrubValue(v any) bool {
+	changed := false
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if _, isString := value.(string); isString && isSensitiveKey(key) {
+				v[key] = redacted
+				changed = true
+				continue
+			}
+			changed = scrubValue(value) || changed
+		}
+	case []any:
+		for _, value := range v {
+			changed = scrubValue(value) || changed
+		}
+	}
+	return changed
+}
@@ -0,0 +1,272 @@
+package upgrade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/internal/printer"
+	"github.com/morpher-vm/morpherctl/pkg/client"
+)
+
+// ErrAborted is returned when a rollout stops because too many upgrades failed.
+var ErrAborted = errors.New("rollout aborted")
+
+// Event reports that the upgrade of an agent entered a phase.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Agent   string    `json:"agent"`
+	Phase   string    `json:"phase"`
+	Message string    `json:"message"`
+}
+
+// TableHeader returns no header so events can be streamed line by line.
+func (e Event) TableHeader(_ bool) []string {
+	return nil
+}
+
+// TableRows returns the event as a single row.
+func (e Event) TableRows(_ bool) [][]string {
+	return [][]string{{e.Time.Format(time.RFC3339), e.Agent, e.Message}}
+}
+
+// Names returns the agent ID.
+func (e Event) Names() []string {
+	return []string{e.Agent}
+}
+
+// Rollout upgrades the agents of a status. Up to MaxUnavailable agents are
+// upgraded at a time, each by draining it, asking the controller to upgrade
+// it, waiting until it reports the new version in a healthy heartbeat and
+// uncordoning it. No further upgrades are started once MaxFailures upgrades
+// have failed; failed agents are left cordoned.
+type Rollout struct {
+	Client *client.Client
+	Status *Status
+	// StatusFile, if set, is rewritten after every change of the status.
+	StatusFile string
+	// DrainTimeout limits waiting for the migrations of an agent to finish.
+	DrainTimeout time.Duration
+	// HealthTimeout limits waiting for an upgraded agent to become healthy.
+	HealthTimeout time.Duration
+	Poll          time.Duration
+	Printer       printer.Printer
+	Out           io.Writer
+
+	mu sync.Mutex
+}
+
+// Run upgrades every pending agent of the status. It returns ErrAborted if
+// the failure threshold was hit, an error if any other upgrade failed, and
+// the context error if the rollout was interrupted. The status is left
+// running when interrupted, and failed when any upgrade failed, so that the
+// rollout can be resumed.
+func (r *Rollout) Run(ctx context.Context) error {
+	if err := r.update(func() {}); err != nil {
+		return err
+	}
+
+	sem := make(chan struct{}, max(r.Status.MaxUnavailable, 1))
+	var wg sync.WaitGroup
+	var saveErr error
+	for i := range r.Status.Agents {
+		if r.Status.Agents[i].Phase != AgentPending {
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil || r.aborted() {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := r.upgrade(ctx, i); err != nil {
+				r.mu.Lock()
+				saveErr = err
+				r.mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if saveErr != nil {
+		return saveErr
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	failed := r.Status.Count(AgentFailed)
+	aborted := r.aborted()
+	err := r.update(func() {
+		switch {
+		case aborted:
+			r.Status.Phase = RolloutAborted
+		case failed > 0:
+			r.Status.Phase = RolloutFailed
+		default:
+			r.Status.Phase = RolloutCompleted
+		}
+	})
+	switch {
+	case err != nil:
+		return err
+	case aborted:
+		return fmt.Errorf("%w after %d failed upgrades", ErrAborted, failed)
+	case failed > 0:
+		return fmt.Errorf("%d agents failed to upgrade", failed)
+	default:
+		return nil
+	}
+}
+
+// aborted reports whether the failure threshold has been hit.
+func (r *Rollout) aborted() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.Status.MaxFailures > 0 && r.Status.Count(AgentFailed) >= r.Status.MaxFailures
+}
+
+// upgrade upgrades a single agent. Only failures to save the status are
+// returned; failed upgrades are recorded in the status.
+func (r *Rollout) upgrade(ctx context.Context, i int) error {
+	id := r.Status.Agents[i].ID
+	version := r.Status.Version
+
+	if err := r.enter(i, AgentDraining, "draining "+id); err != nil {
+		return err
+	}
+	_, err := r.Client.DrainAgent(ctx, id, client.DrainOptions{Poll: r.Poll, Timeout: r.DrainTimeout})
+	if err != nil {
+		return r.fail(ctx, i, fmt.Errorf("failed to drain: %w", err))
+	}
+
+	agent, err := r.getAgent(ctx, id)
+	if err != nil {
+		return r.fail(ctx, i, err)
+	}
+	if err := r.enter(i, AgentUpgrading, fmt.Sprintf("upgrading %s from %s to %s", id, agent.Version, version)); err != nil {
+		return err
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, r.Client.GetTimeout())
+	err = r.Client.UpgradeAgent(reqCtx, id, version)
+	cancel()
+	if err != nil {
+		return r.fail(ctx, i, err)
+	}
+
+	if err := r.enter(i, AgentWaiting, fmt.Sprintf("waiting for %s to report %s", id, version)); err != nil {
+		return err
+	}
+	if err := r.waitHealthy(ctx, agent); err != nil {
+		return r.fail(ctx, i, err)
+	}
+
+	reqCtx, cancel = context.WithTimeout(ctx, r.Client.GetTimeout())
+	err = r.Client.UncordonAgent(reqCtx, id)
+	cancel()
+	if err != nil {
+		return r.fail(ctx, i, err)
+	}
+	return r.enter(i, AgentUpgraded, fmt.Sprintf("upgraded %s to %s", id, version))
+}
+
+// waitHealthy waits until the agent is ready with the new version and has
+// sent a heartbeat since before it was upgraded. Heartbeats are compared
+// with each other rather than with the local clock.
+func (r *Rollout) waitHealthy(ctx context.Context, before *client.Agent) error {
+	ctx, cancel := context.WithTimeout(ctx, r.HealthTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(r.Poll)
+	defer ticker.Stop()
+
+	alreadyUpgraded := sameVersion(before.Version, r.Status.Version)
+	for {
+		agent, err := r.getAgent(ctx, before.ID)
+		if err == nil && agent.Status == client.AgentReady && sameVersion(agent.Version, r.Status.Version) &&
+			(alreadyUpgraded || agent.LastHeartbeat.After(before.LastHeartbeat)) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if agent != nil {
+				return fmt.Errorf("agent did not become healthy with version %s: it is %s with version %s",
+					r.Status.Version, agent.Status, agent.Version)
+			}
+			return fmt.Errorf("agent did not become healthy with version %s: %w", r.Status.Version, err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// getAgent gets an agent, bounded by the client timeout.
+func (r *Rollout) getAgent(ctx context.Context, id string) (*client.Agent, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.Client.GetTimeout())
+	defer cancel()
+	return r.Client.GetAgent(ctx, id)
+}
+
+// enter moves an agent to a phase and reports it.
+func (r *Rollout) enter(i int, phase, message string) error {
+	return r.update(func() {
+		r.Status.Agents[i].Phase = phase
+		if phase == AgentUpgraded {
+			r.Status.Agents[i].FinishedAt = time.Now()
+		}
+		r.print(Event{Time: time.Now(), Agent: r.Status.Agents[i].ID, Phase: phase, Message: message})
+	})
+}
+
+// fail records a failed upgrade, unless the rollout was interrupted, in
+// which case the agent stays in its phase to be resumed.
+func (r *Rollout) fail(ctx context.Context, i int, err error) error {
+	if ctx.Err() != nil {
+		return nil
+	}
+	return r.update(func() {
+		agent := &r.Status.Agents[i]
+		agent.Phase = AgentFailed
+		agent.Error = err.Error()
+		agent.FinishedAt = time.Now()
+		r.print(Event{
+			Time:    agent.FinishedAt,
+			Agent:   agent.ID,
+			Phase:   AgentFailed,
+			Message: fmt.Sprintf("upgrade of %s failed, leaving it cordoned: %v", agent.ID, err),
+		})
+	})
+}
+
+// update changes the status and saves it.
+func (r *Rollout) update(change func()) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	change()
+	r.Status.UpdatedAt = time.Now()
+	if r.StatusFile == "" {
+		return nil
+	}
+	if err := r.Status.Save(r.StatusFile); err != nil {
+		return fmt.Errorf("failed to save upgrade status: %w", err)
+	}
+	return nil
+}
+
+// print reports an event. The caller must hold r.mu.
+func (r *Rollout) print(event Event) {
+	if r.Printer != nil {
+		_ = r.Printer.Print(r.Out, event)
+	}
+}
@@ -0,0 +1,139 @@
+package upgrade
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/morpher-vm/morpherctl/pkg/client"
+	"github.com/morpher-vm/morpherctl/pkg/fakecontroller"
+)
+
+func testAgents() []client.Agent {
+	now := time.Now()
+	agents := make([]client.Agent, 0, 3)
+	for _, id := range []string{"agent-a", "agent-b", "agent-c"} {
+		agents = append(agents, client.Agent{
+			ID:            id,
+			Status:        client.AgentReady,
+			Version:       "v0.9.2",
+			Schedulable:   true,
+			LastHeartbeat: now.Add(-time.Second),
+		})
+	}
+	return agents
+}
+
+func newRollout(t *testing.T, fake *fakecontroller.Server, status *Status) *Rollout {
+	t.Helper()
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return &Rollout{
+		Client:        client.NewClient(server.URL, time.Second, ""),
+		Status:        status,
+		StatusFile:    filepath.Join(t.TempDir(), "upgrade.json"),
+		DrainTimeout:  200 * time.Millisecond,
+		HealthTimeout: 200 * time.Millisecond,
+		Poll:          5 * time.Millisecond,
+	}
+}
+
+func TestRollout_Run(t *testing.T) {
+	t.Run("should upgrade every agent", func(t *testing.T) {
+		agents := testAgents()
+		agents[2].Version = "v1.0.0"
+		fake := fakecontroller.New(fakecontroller.WithAgents(agents...), fakecontroller.WithUpgradeDelay(10*time.Millisecond))
+		rollout := newRollout(t, fake, NewStatus("v1.0.0", "", agents, 2, 1))
+
+		require.NoError(t, rollout.Run(context.Background()))
+		assert.Equal(t, RolloutCompleted, rollout.Status.Phase)
+		assert.Equal(t, 2, rollout.Status.Count(AgentUpgraded))
+		assert.Equal(t, AgentSkipped, rollout.Status.Agents[2].Phase)
+
+		for _, agent := range agents {
+			upgraded, _ := fake.Agent(agent.ID)
+			assert.Equal(t, "v1.0.0", upgraded.Version)
+			assert.True(t, upgraded.Schedulable, agent.ID)
+		}
+
+		saved, err := LoadStatus(rollout.StatusFile)
+		require.NoError(t, err)
+		assert.Equal(t, RolloutCompleted, saved.Phase)
+		assert.Equal(t, 2, saved.Count(AgentUpgraded))
+	})
+
+	t.Run("should abort at the failure threshold and resume", func(t *testing.T) {
+		agents := testAgents()
+		fake := fakecontroller.New(fakecontroller.WithAgents(agents...), fakecontroller.WithFailingUpgrades("agent-a"))
+		rollout := newRollout(t, fake, NewStatus("v1.0.0", "", agents, 1, 1))
+
+		err := rollout.Run(context.Background())
+		require.ErrorIs(t, err, ErrAborted)
+		assert.Equal(t, RolloutAborted, rollout.Status.Phase)
+		assert.Equal(t, AgentFailed, rollout.Status.Agents[0].Phase)
+		assert.Contains(t, rollout.Status.Agents[0].Error, "did not become healthy with version v1.0.0")
+		assert.Equal(t, AgentPending, rollout.Status.Agents[1].Phase)
+
+		failed, _ := fake.Agent("agent-a")
+		assert.False(t, failed.Schedulable)
+		untouched, _ := fake.Agent("agent-b")
+		assert.Equal(t, "v0.9.2", untouched.Version)
+
+		// The agent is fixed and the rollout continues where it stopped.
+		status, err := LoadStatus(rollout.StatusFile)
+		require.NoError(t, err)
+		status.Resume()
+		fixed := fakecontroller.New(fakecontroller.WithAgents(agents...))
+		resumed := newRollout(t, fixed, status)
+		require.NoError(t, resumed.Run(context.Background()))
+		assert.Equal(t, 3, resumed.Status.Count(AgentUpgraded))
+	})
+
+	t.Run("should fail agents that cannot be drained", func(t *testing.T) {
+		agents := testAgents()[:1]
+		fake := fakecontroller.New(fakecontroller.WithAgents(agents...), fakecontroller.WithMigrations(client.Migration{
+			ID:          "mig-1",
+			SourceAgent: "agent-a",
+			Phase:       client.MigrationRunning,
+		}))
+		rollout := newRollout(t, fake, NewStatus("v1.0.0", "", agents, 1, 2))
+
+		err := rollout.Run(context.Background())
+		require.Error(t, err)
+		assert.False(t, errors.Is(err, ErrAborted))
+		assert.Equal(t, "1 agents failed to upgrade", err.Error())
+		assert.Contains(t, rollout.Status.Agents[0].Error, "still has 1 active migrations")
+		assert.Equal(t, RolloutFailed, rollout.Status.Phase)
+		assert.False(t, rollout.Status.Finished())
+	})
+}
+
+func TestNewStatus(t *testing.T) {
+	agents := testAgents()
+	agents[1].Version = "1.0.0"
+	agents[2].Version = "v1.0.0+build.7"
+
+	status := NewStatus("v1.0.0", "", agents, 1, 1)
+	assert.Equal(t, AgentPending, status.Agents[0].Phase)
+	assert.Equal(t, AgentSkipped, status.Agents[1].Phase)
+	assert.Equal(t, AgentSkipped, status.Agents[2].Phase)
+}
+
+func TestRollout_waitHealthy(t *testing.T) {
+	t.Run("should accept a version without prefix", func(t *testing.T) {
+		agents := testAgents()[:1]
+		agents[0].Version = "1.0.0"
+		fake := fakecontroller.New(fakecontroller.WithAgents(agents...))
+		rollout := newRollout(t, fake, &Status{Version: "v1.0.0"})
+
+		assert.NoError(t, rollout.waitHealthy(context.Background(), &agents[0]))
+	})
+}
@@ -0,0 +1,161 @@
+// Package upgrade rolls a new agent version out to agents, a few at a time,
+// and keeps the progress in a status file so that a rollout can be resumed.
+package upgrade
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/pkg/client"
+	"github.com/morpher-vm/morpherctl/pkg/semver"
+)
+
+// Phases of a rollout.
+const (
+	RolloutRunning   = "running"
+	RolloutCompleted = "completed"
+	RolloutFailed    = "failed"
+	RolloutAborted   = "aborted"
+)
+
+// Phases of the upgrade of a single agent, in the order they are passed.
+const (
+	AgentPending   = "pending"
+	AgentDraining  = "draining"
+	AgentUpgrading = "upgrading"
+	AgentWaiting   = "waiting"
+	AgentUpgraded  = "upgraded"
+	AgentFailed    = "failed"
+	AgentSkipped   = "skipped"
+)
+
+// Status is the progress of a rollout.
+type Status struct {
+	Version        string        `json:"version"`
+	Selector       string        `json:"selector,omitempty"`
+	MaxUnavailable int           `json:"max_unavailable"`
+	MaxFailures    int           `json:"max_failures"`
+	Phase          string        `json:"phase"`
+	StartedAt      time.Time     `json:"started_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	Agents         []AgentStatus `json:"agents"`
+}
+
+// AgentStatus is the progress of the upgrade of a single agent.
+type AgentStatus struct {
+	ID          string    `json:"id"`
+	FromVersion string    `json:"from_version"`
+	Phase       string    `json:"phase"`
+	Error       string    `json:"error,omitempty"`
+	FinishedAt  time.Time `json:"finished_at,omitzero"`
+}
+
+// NewStatus starts a rollout of a version to agents. Agents that already run
+// the version are skipped.
+func NewStatus(version, selector string, agents []client.Agent, maxUnavailable, maxFailures int) *Status {
+	now := time.Now()
+	status := &Status{
+		Version:        version,
+		Selector:       selector,
+		MaxUnavailable: maxUnavailable,
+		MaxFailures:    maxFailures,
+		Phase:          RolloutRunning,
+		StartedAt:      now,
+		UpdatedAt:      now,
+	}
+	for _, agent := range agents {
+		phase := AgentPending
+		if sameVersion(agent.Version, version) {
+			phase = AgentSkipped
+		}
+		status.Agents = append(status.Agents, AgentStatus{ID: agent.ID, FromVersion: agent.Version, Phase: phase})
+	}
+	return status
+}
+
+// sameVersion reports whether two versions are equal, so that "v1.4.0" and
+// "1.4.0" match. Versions that are not semantic are compared as strings.
+func sameVersion(a, b string) bool {
+	cmp, err := semver.Compare(a, b)
+	if err != nil {
+		return a == b
+	}
+	return cmp == 0
+}
+
+// Resume prepares an interrupted, failed or aborted rollout to continue. Agents that
+// failed or were interrupted are upgraded again; every step of an upgrade
+// may be repeated.
+func (s *Status) Resume() {
+	s.Phase = RolloutRunning
+	for i, agent := range s.Agents {
+		switch agent.Phase {
+		case AgentUpgraded, AgentSkipped:
+		default:
+			s.Agents[i].Phase = AgentPending
+			s.Agents[i].Error = ""
+			s.Agents[i].FinishedAt = time.Time{}
+		}
+	}
+}
+
+// Finished reports whether every agent of the rollout has been upgraded.
+// Rollouts that ended with failed agents are not finished and may be resumed.
+func (s *Status) Finished() bool {
+	return s.Phase == RolloutCompleted
+}
+
+// Count returns the number of agents in the given phase.
+func (s *Status) Count(phase string) int {
+	count := 0
+	for _, agent := range s.Agents {
+		if agent.Phase == phase {
+			count++
+		}
+	}
+	return count
+}
+
+// LoadStatus reads a status file. The error wraps fs.ErrNotExist if the file
+// does not exist.
+func LoadStatus(path string) (*Status, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var status Status
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("failed to parse upgrade status %s: %w", path, err)
+	}
+	return &status, nil
+}
+
+// Save writes the status file atomically.
+func (s *Status) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode upgrade status: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,155 @@
+package version
+
+import (
+	"cmp"
+	"fmt"
+
+	"github.com/morpher-vm/morpherctl/pkg/semver"
+)
+
+// Compatibility states of a client and controller version pair.
+const (
+	CompatSupported   = "supported"
+	CompatUnsupported = "unsupported"
+	CompatUnknown     = "unknown"
+)
+
+// compatRange lists the controller minor versions supported by a client minor version.
+type compatRange struct {
+	client        string
+	minController string
+	maxController string
+}
+
+// compatMatrix is the supported skew between morpherctl and the controller.
+// Clients without an entry support controllers of the same major version
+// that are at most one minor version apart.
+var compatMatrix = []compatRange{
+	{client: "v1.3", minController: "v1.2", maxController: "v1.4"},
+	{client: "v1.2", minController: "v1.1", maxController: "v1.3"},
+	{client: "v1.1", minController: "v1.0", maxController: "v1.2"},
+	{client: "v1.0", minController: "v0.9", maxController: "v1.1"},
+}
+
+// Compatibility describes whether a client and controller version are
+// within the supported skew.
+type Compatibility struct {
+	Client         string `json:"client"`
+	Controller     string `json:"controller"`
+	Status         string `json:"status"`
+	SupportedRange string `json:"supported_range,omitempty"`
+	Message        string `json:"message,omitempty"`
+}
+
+// Supported reports whether the versions are known to work together.
+// Unknown compatibility, for example of development builds, counts as supported.
+func (c Compatibility) Supported() bool {
+	return c.Status != CompatUnsupported
+}
+
+// CheckCompatibility compares a client version against a controller version
+// using the compatibility matrix. Versions are compared by major and minor.
+func CheckCompatibility(clientVersion, controllerVersion string) Compatibility {
+	compat := Compatibility{
+		Client:     clientVersion,
+		Controller: controllerVersion,
+		Status:     CompatUnknown,
+	}
+
+	client, err := semver.Parse(clientVersion)
+	if err != nil {
+		compat.Message = "client version is not a release version"
+		return compat
+	}
+	controller, err := semver.Parse(controllerVersion)
+	if err != nil {
+		compat.Message = "controller version is not a release version"
+		return compat
+	}
+
+	minVersion, maxVersion := supportedRange(client)
+	compat.SupportedRange = fmt.Sprintf("v%d.%d - v%d.%d", minVersion.Major, minVersion.Minor, maxVersion.Major, maxVersion.Minor)
+
+	if compareMinor(controller, minVersion) < 0 || compareMinor(controller, maxVersion) > 0 {
+		compat.Status = CompatUnsupported
+		compat.Message = fmt.Sprintf("morpherctl %s supports controllers %s, but the controller runs %s",
+			clientVersion, compat.SupportedRange, controllerVersion)
+		return compat
+	}
+
+	compat.Status = CompatSupported
+	return compat
+}
+
+// supportedRange returns the lowest and highest controller minor versions
+// supported by the client.
+func supportedRange(client semver.Version) (semver.Version, semver.Version) {
+	for _, entry := range compatMatrix {
+		v, err := semver.Parse(entry.client)
+		if err != nil || compareMinor(v, client) != 0 {
+			continue
+		}
+		minVersion, errMin := semver.Parse(entry.minController)
+		maxVersion, errMax := semver.Parse(entry.maxController)
+		if errMin == nil && errMax == nil {
+			return minVersion, maxVersion
+		}
+	}
+
+	minVersion := semver.Version{Major: client.Major, Minor: max(client.Minor-1, 0)}
+	maxVersion := semver.Version{Major: client.Major, Minor: client.Minor + 1}
+	return minVersion, maxVersion
+}
+
+// compareMinor compares two versions by major and minor version only.
+func compareMinor(a, b semver.Version) int {
+	return semver.Version{Major: a.Major, Minor: a.Minor}.Compare(semver.Version{Major: b.Major, Minor: b.Minor})
+}
+
+// defaultAgentSkew is the number of minor versions agents may lag behind a
+// controller that does not report the agent versions it supports.
+const defaultAgentSkew = 2
+
+// CheckAgentVersion returns an error if an agent version is outside the agent
+// versions a controller supports. The controller reports the lowest and
+// highest supported versions; when it does not, agents of the same major
+// version as the controller that are at most two minor versions older and
+// not newer are supported. Versions are compared by major and minor.
+func CheckAgentVersion(agentVersion, controllerVersion, minAgentVersion, maxAgentVersion string) error {
+	agent, err := semver.Parse(agentVersion)
+	if err != nil {
+		return err
+	}
+
+	minVersion, maxVersion, err := agentRange(controllerVersion, minAgentVersion, maxAgentVersion)
+	if err != nil {
+		return err
+	}
+
+	if compareMinor(agent, minVersion) < 0 || compareMinor(agent, maxVersion) > 0 {
+		return fmt.Errorf("controller %s supports agents v%d.%d - v%d.%d, not %s", controllerVersion,
+			minVersion.Major, minVersion.Minor, maxVersion.Major, maxVersion.Minor, agentVersion)
+	}
+	return nil
+}
+
+// agentRange returns the lowest and highest agent minor versions supported
+// by a controller.
+func agentRange(controllerVersion, minAgentVersion, maxAgentVersion string) (semver.Version, semver.Version, error) {
+	if minAgentVersion != "" && maxAgentVersion != "" {
+		minVersion, errMin := semver.Parse(minAgentVersion)
+		maxVersion, errMax := semver.Parse(maxAgentVersion)
+		if err := cmp.Or(errMin, errMax); err != nil {
+			return semver.Version{}, semver.Version{}, fmt.Errorf("controller reports invalid agent versions: %w", err)
+		}
+		return minVersion, maxVersion, nil
+	}
+
+	controller, err := semver.Parse(controllerVersion)
+	if err != nil {
+		return semver.Version{}, semver.Version{}, fmt.Errorf("cannot determine the agent versions supported by controller %q", controllerVersion)
+	}
+	minVersion := semver.Version{Major: controller.Major, Minor: max(controller.Minor-defaultAgentSkew, 0)}
+	maxVersion := semver.Version{Major: controller.Major, Minor: controller.Minor}
+	return minVersion, maxVersion, nil
+}
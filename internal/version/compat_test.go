@@ -0,0 +1,68 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	tests := []struct {
+		name           string
+		client         string
+		controller     string
+		expectedStatus string
+		expectedRange  string
+	}{
+		{name: "should support same version", client: "v1.2.0", controller: "v1.2.3", expectedStatus: CompatSupported, expectedRange: "v1.1 - v1.3"},
+		{name: "should support older controller in matrix", client: "v1.0.4", controller: "v0.9.1", expectedStatus: CompatSupported, expectedRange: "v0.9 - v1.1"},
+		{name: "should reject controller too old", client: "v1.3.0", controller: "v1.1.9", expectedStatus: CompatUnsupported, expectedRange: "v1.2 - v1.4"},
+		{name: "should reject controller too new", client: "v1.1.0", controller: "v1.3.0", expectedStatus: CompatUnsupported, expectedRange: "v1.0 - v1.2"},
+		{name: "should fall back to one minor skew", client: "v2.5.0", controller: "v2.4.0", expectedStatus: CompatSupported, expectedRange: "v2.4 - v2.6"},
+		{name: "should reject other major without entry", client: "v2.0.0", controller: "v1.9.0", expectedStatus: CompatUnsupported, expectedRange: "v2.0 - v2.1"},
+		{name: "should not judge development builds", client: "dev", controller: "v1.0.0", expectedStatus: CompatUnknown},
+		{name: "should not judge unparsable controller versions", client: "v1.0.0", controller: "main", expectedStatus: CompatUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compat := CheckCompatibility(tt.client, tt.controller)
+			assert.Equal(t, tt.expectedStatus, compat.Status)
+			assert.Equal(t, tt.expectedRange, compat.SupportedRange)
+			assert.Equal(t, tt.expectedStatus != CompatUnsupported, compat.Supported())
+			if tt.expectedStatus == CompatUnsupported {
+				assert.Contains(t, compat.Message, "supports controllers "+tt.expectedRange)
+			}
+		})
+	}
+}
+
+func TestCheckAgentVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		agent         string
+		controller    string
+		minAgent      string
+		maxAgent      string
+		expectedError string
+	}{
+		{name: "should accept version in reported range", agent: "v1.4.0", controller: "v1.4.2", minAgent: "v1.2", maxAgent: "v1.4"},
+		{name: "should reject version above reported range", agent: "v1.5.0", controller: "v1.4.2", minAgent: "v1.2", maxAgent: "v1.4", expectedError: "controller v1.4.2 supports agents v1.2 - v1.4, not v1.5.0"},
+		{name: "should reject version below reported range", agent: "v1.1.9", controller: "v1.4.2", minAgent: "v1.2", maxAgent: "v1.4", expectedError: "supports agents v1.2 - v1.4"},
+		{name: "should default to two older minor versions", agent: "v1.2.0", controller: "v1.4.0"},
+		{name: "should not default to newer agents", agent: "v1.5.0", controller: "v1.4.0", expectedError: "supports agents v1.2 - v1.4"},
+		{name: "should reject invalid agent version", agent: "latest", controller: "v1.4.0", expectedError: "invalid version"},
+		{name: "should reject unknown range", agent: "v1.4.0", controller: "main", expectedError: "cannot determine"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckAgentVersion(tt.agent, tt.controller, tt.minAgent, tt.maxAgent)
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package version
 
-import "fmt"
+import (
+	"fmt"
+	"runtime"
+)
 
 var (
 	// These variables are set during build time using ldflags.
@@ -9,7 +12,29 @@ var (
 	BuildDate = "unknown" // Default to "unknown" if not set during build.
 )
 
+// Info represents the build information of morpherctl.
+type Info struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit"`
+	BuildDate string `json:"build_date"`
+}
+
+// Get returns the build information of morpherctl.
+func Get() Info {
+	return Info{
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildDate: BuildDate,
+	}
+}
+
 // GetVersionInfo returns formatted version information.
 func GetVersionInfo() string {
 	return fmt.Sprintf("Version: %s\nGit Commit: %s\nBuild Date: %s\n", Version, GitCommit, BuildDate)
 }
+
+// UserAgent returns the User-Agent sent by morpherctl to the controller,
+// for example "morpherctl/v1.2.0 (abc123; linux/amd64)".
+func UserAgent() string {
+	return fmt.Sprintf("morpherctl/%s (%s; %s/%s)", Version, GitCommit, runtime.GOOS, runtime.GOARCH)
+}
@@ -1,6 +1,7 @@
 package version
 
 import (
+	"runtime"
 	"strings"
 	"testing"
 )
@@ -34,3 +35,13 @@ func TestGetVersionInfo(t *testing.T) {
 		}
 	}
 }
+
+func TestUserAgent(t *testing.T) {
+	defer func(v, c string) { Version, GitCommit = v, c }(Version, GitCommit)
+	Version, GitCommit = "v1.2.0", "abc123"
+
+	expected := "morpherctl/v1.2.0 (abc123; " + runtime.GOOS + "/" + runtime.GOARCH + ")"
+	if got := UserAgent(); got != expected {
+		t.Errorf("UserAgent() = %q, want %q", got, expected)
+	}
+}
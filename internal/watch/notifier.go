@@ -0,0 +1,96 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+)
+
+// HookNotifier runs a shell command for every event.
+// The event is passed as JSON on stdin and summarized in MORPHER_* environment variables.
+// The output of the command is written to Out, or discarded if Out is nil.
+type HookNotifier struct {
+	Command string
+	Out     io.Writer
+}
+
+// Notify runs the hook command for the event.
+func (n *HookNotifier) Notify(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", n.Command)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = n.Out
+	cmd.Stderr = n.Out
+	cmd.Env = append(os.Environ(),
+		"MORPHER_EVENT="+event.Type,
+		"MORPHER_MESSAGE="+event.Message,
+		"MORPHER_CONTROLLER="+event.Controller,
+	)
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("hook command failed: %w", err)
+	}
+	return nil
+}
+
+// WebhookNotifier posts every event as JSON to a URL.
+type WebhookNotifier struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+// ValidateWebhookURL checks that a webhook URL is an absolute http or https
+// URL.
+func ValidateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid webhook URL %q: scheme must be http or https", rawURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid webhook URL %q: host is missing", rawURL)
+	}
+	return nil
+}
+
+// Notify posts the event to the webhook URL.
+func (n *WebhookNotifier) Notify(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := n.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/internal/printer"
+	"github.com/morpher-vm/morpherctl/pkg/client"
+)
+
+// Event types emitted when the observed controller state changes.
+// EventInitial reports the first observed state and does not trigger notifiers.
+const (
+	EventInitial        = "initial"
+	EventUp             = "up"
+	EventDown           = "down"
+	EventHealthChanged  = "health_changed"
+	EventVersionChanged = "version_changed"
+	EventRestarted      = "restarted"
+)
+
+// Snapshot is the controller state observed by a single poll.
+type Snapshot struct {
+	Time    time.Time       `json:"time"`
+	Up      bool            `json:"up"`
+	Health  string          `json:"health,omitempty"`
+	Version string          `json:"version,omitempty"`
+	UpTime  client.Duration `json:"uptime,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// Event describes a transition between two snapshots.
+type Event struct {
+	Type       string    `json:"type"`
+	Time       time.Time `json:"time"`
+	Controller string    `json:"controller"`
+	Message    string    `json:"message"`
+	Previous   Snapshot  `json:"previous"`
+	Current    Snapshot  `json:"current"`
+}
+
+// TableHeader returns no header so events can be streamed line by line.
+func (e Event) TableHeader(_ bool) []string {
+	return nil
+}
+
+// TableRows returns the event as a single row.
+func (e Event) TableRows(wide bool) [][]string {
+	if wide {
+		return [][]string{{e.Time.Format(time.RFC3339), e.Controller, e.Type, e.Message}}
+	}
+	return [][]string{{e.Time.Format(time.RFC3339), e.Message}}
+}
+
+// Names returns the event type.
+func (e Event) Names() []string {
+	return []string{e.Type}
+}
+
+// DefaultNotifyTimeout limits every notification when
+// Watcher.NotifyTimeout is not set.
+const DefaultNotifyTimeout = 10 * time.Second
+
+// Notifier is informed about every transition observed by a Watcher.
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// Watcher polls the controller and reports state transitions.
+type Watcher struct {
+	Client    *client.Client
+	Interval  time.Duration
+	Printer   printer.Printer
+	Out       io.Writer
+	ErrOut    io.Writer
+	Notifiers []Notifier
+	// NotifyTimeout limits every notification, which delays the next poll
+	// while it runs. Zero means DefaultNotifyTimeout.
+	NotifyTimeout time.Duration
+}
+
+// Run polls the controller until the context is cancelled.
+// The initial state is printed but does not trigger notifiers.
+func (w *Watcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	var previous *Snapshot
+	for {
+		current := w.poll(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if previous == nil {
+			initial := Event{
+				Type:       EventInitial,
+				Time:       current.Time,
+				Controller: w.Client.GetBaseURL(),
+				Message:    describe(current),
+				Current:    current,
+			}
+			if err := w.Printer.Print(w.Out, initial); err != nil {
+				return err
+			}
+		}
+
+		for _, event := range Diff(w.Client.GetBaseURL(), previous, &current) {
+			if err := w.Printer.Print(w.Out, event); err != nil {
+				return err
+			}
+			w.notify(ctx, event)
+		}
+		previous = &current
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// notify passes an event to every notifier, each within the notify timeout.
+// Failures are reported as warnings.
+func (w *Watcher) notify(ctx context.Context, event Event) {
+	timeout := w.NotifyTimeout
+	if timeout <= 0 {
+		timeout = DefaultNotifyTimeout
+	}
+
+	for _, notifier := range w.Notifiers {
+		notifyCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := notifier.Notify(notifyCtx, event)
+		cancel()
+		if err != nil {
+			fmt.Fprintf(w.ErrOut, "Warning: %v\n", err)
+		}
+	}
+}
+
+// poll collects a snapshot from the ping, status and info endpoints.
+func (w *Watcher) poll(ctx context.Context) Snapshot {
+	ctx, cancel := context.WithTimeout(ctx, w.Client.GetTimeout())
+	defer cancel()
+
+	snapshot := Snapshot{Time: time.Now()}
+
+	ping, err := w.Client.Ping(ctx)
+	switch {
+	case err != nil:
+		snapshot.Error = err.Error()
+		return snapshot
+	case !ping.Success:
+		snapshot.Error = fmt.Sprintf("ping returned status code %d", ping.StatusCode)
+		return snapshot
+	}
+	snapshot.Up = true
+
+	// Status and info are best effort; older controllers may not serve them.
+	if status, err := w.Client.GetStatus(ctx); err == nil && status.Result != nil {
+		snapshot.Health = status.Result.Overall()
+	}
+	if info, err := w.Client.GetInfo(ctx); err == nil && info.Result != nil {
+		snapshot.Version = info.Result.Version
+		snapshot.UpTime = info.Result.UpTime
+	}
+
+	return snapshot
+}
+
+// Diff returns the events describing the transition from previous to current.
+// No events are returned for the first snapshot.
+func Diff(controllerURL string, previous, current *Snapshot) []Event {
+	if previous == nil || current == nil {
+		return nil
+	}
+
+	newEvent := func(eventType, message string) Event {
+		return Event{
+			Type:       eventType,
+			Time:       current.Time,
+			Controller: controllerURL,
+			Message:    message,
+			Previous:   *previous,
+			Current:    *current,
+		}
+	}
+
+	switch {
+	case previous.Up && !current.Up:
+		return []Event{newEvent(EventDown, "controller is down: "+current.Error)}
+	case !previous.Up && current.Up:
+		return []Event{newEvent(EventUp, "controller is up: "+describe(*current))}
+	case !current.Up:
+		return nil
+	}
+
+	var events []Event
+	if previous.Version != "" && current.Version != "" && previous.Version != current.Version {
+		events = append(events, newEvent(EventVersionChanged,
+			fmt.Sprintf("controller version changed from %s to %s", previous.Version, current.Version)))
+	}
+	if previous.UpTime > 0 && current.UpTime > 0 && current.UpTime < previous.UpTime {
+		events = append(events, newEvent(EventRestarted,
+			fmt.Sprintf("controller restarted: uptime reset from %s to %s", previous.UpTime, current.UpTime)))
+	}
+	if previous.Health != "" && current.Health != "" && previous.Health != current.Health {
+		events = append(events, newEvent(EventHealthChanged,
+			fmt.Sprintf("controller health changed from %s to %s", previous.Health, current.Health)))
+	}
+
+	return events
+}
+
+// describe renders a snapshot as a short human-readable state.
+func describe(s Snapshot) string {
+	if !s.Up {
+		return "controller is down: " + s.Error
+	}
+
+	state := "controller is up"
+	if s.Health != "" {
+		state += ", " + s.Health
+	}
+	if s.Version != "" {
+		state += ", version " + s.Version
+	}
+	if s.UpTime > 0 {
+		state += ", uptime " + s.UpTime.String()
+	}
+	return state
+}
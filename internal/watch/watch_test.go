@@ -0,0 +1,280 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/internal/printer"
+	"github.com/morpher-vm/morpherctl/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	up := Snapshot{Up: true, Health: "healthy", Version: "v1.3.0", UpTime: client.Duration(time.Hour)}
+
+	tests := []struct {
+		name     string
+		previous *Snapshot
+		current  Snapshot
+		expected []string
+	}{
+		{
+			name:     "should not report the first snapshot",
+			previous: nil,
+			current:  up,
+			expected: nil,
+		},
+		{
+			name:     "should not report unchanged state",
+			previous: &up,
+			current:  Snapshot{Up: true, Health: "healthy", Version: "v1.3.0", UpTime: client.Duration(2 * time.Hour)},
+			expected: nil,
+		},
+		{
+			name:     "should report controller going down",
+			previous: &up,
+			current:  Snapshot{Error: "connection refused"},
+			expected: []string{EventDown},
+		},
+		{
+			name:     "should report controller coming up",
+			previous: &Snapshot{Error: "connection refused"},
+			current:  up,
+			expected: []string{EventUp},
+		},
+		{
+			name:     "should not report while controller stays down",
+			previous: &Snapshot{Error: "connection refused"},
+			current:  Snapshot{Error: "timeout"},
+			expected: nil,
+		},
+		{
+			name:     "should report upgrade with restart",
+			previous: &up,
+			current:  Snapshot{Up: true, Health: "healthy", Version: "v1.4.0", UpTime: client.Duration(time.Minute)},
+			expected: []string{EventVersionChanged, EventRestarted},
+		},
+		{
+			name:     "should report health change",
+			previous: &up,
+			current:  Snapshot{Up: true, Health: "degraded", Version: "v1.3.0", UpTime: client.Duration(2 * time.Hour)},
+			expected: []string{EventHealthChanged},
+		},
+		{
+			name:     "should not report restart when info is missing",
+			previous: &up,
+			current:  Snapshot{Up: true, Health: "healthy"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := tt.current
+			events := Diff("http://controller", tt.previous, &current)
+
+			var types []string
+			for _, event := range events {
+				types = append(types, event.Type)
+				assert.Equal(t, "http://controller", event.Controller)
+				assert.NotEmpty(t, event.Message)
+			}
+			assert.Equal(t, tt.expected, types)
+		})
+	}
+}
+
+func TestWatcher_Run(t *testing.T) {
+	// Fake controller that is up, goes down, then comes back after a restart.
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		poll := polls.Load()
+		if r.URL.Path == "/ping" {
+			poll = polls.Add(1)
+		}
+
+		if poll == 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		uptime := "1h"
+		if poll >= 3 {
+			uptime = "5s"
+		}
+
+		switch r.URL.Path {
+		case "/health":
+			_, err := w.Write([]byte(`{"Status": "healthy"}`))
+			require.NoError(t, err)
+		case "/info":
+			_, err := fmt.Fprintf(w, `{"Version": "v1.3.0", "UpTime": %q}`, uptime)
+			require.NoError(t, err)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	// Local webhook receiver collecting the posted events.
+	var mu sync.Mutex
+	var received []Event
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var event Event
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+
+		mu.Lock()
+		received = append(received, event)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var out, errOut syncBuffer
+	watcher := &Watcher{
+		Client:    client.NewClient(server.URL, time.Second, ""),
+		Interval:  10 * time.Millisecond,
+		Printer:   &printer.TablePrinter{},
+		Out:       &out,
+		ErrOut:    &errOut,
+		Notifiers: []Notifier{&WebhookNotifier{URL: receiver.URL}},
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- watcher.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, EventDown, received[0].Type)
+	assert.Equal(t, EventUp, received[1].Type)
+	assert.Contains(t, out.String(), "controller is up, healthy, version v1.3.0")
+	assert.Contains(t, out.String(), "controller is down")
+	assert.Empty(t, errOut.String())
+}
+
+func TestHookNotifier_Notify(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "event.json")
+	notifier := &HookNotifier{Command: fmt.Sprintf(`cat > %q && echo "$MORPHER_EVENT" >> %q`, output, output)}
+
+	event := Event{Type: EventDown, Controller: "http://controller", Message: "controller is down"}
+	require.NoError(t, notifier.Notify(context.Background(), event))
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"type":"down"`)
+	assert.Contains(t, string(data), "}down\n")
+}
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	notifier := &WebhookNotifier{URL: receiver.URL}
+	err := notifier.Notify(context.Background(), Event{Type: EventUp})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status code: 500")
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{name: "should accept https", url: "https://hooks.example.com/morpher"},
+		{name: "should accept http", url: "http://localhost:8080/hook"},
+		{name: "should reject other schemes", url: "ftp://hooks.example.com", expected: "scheme must be http or https"},
+		{name: "should reject relative urls", url: "hooks.example.com/morpher", expected: "scheme must be http or https"},
+		{name: "should reject missing hosts", url: "https:///morpher", expected: "host is missing"},
+		{name: "should reject unparsable urls", url: "http://[::1", expected: "invalid webhook URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateWebhookURL(tt.url)
+			if tt.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+// notifierFunc adapts a function to the Notifier interface.
+type notifierFunc func(ctx context.Context, event Event) error
+
+func (f notifierFunc) Notify(ctx context.Context, event Event) error {
+	return f(ctx, event)
+}
+
+func TestWatcher_NotifyTimeout(t *testing.T) {
+	var calls atomic.Int32
+	blocking := notifierFunc(func(ctx context.Context, _ Event) error {
+		calls.Add(1)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	var errOut syncBuffer
+	watcher := &Watcher{
+		ErrOut:        &errOut,
+		Notifiers:     []Notifier{blocking, blocking},
+		NotifyTimeout: 10 * time.Millisecond,
+	}
+
+	start := time.Now()
+	watcher.notify(context.Background(), Event{Type: EventDown})
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, "Warning: context deadline exceeded\nWarning: context deadline exceeded\n", errOut.String())
+}
+
+// syncBuffer is an output buffer that is safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	return len(p), nil
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
@@ -1,6 +1,6 @@
 package main
 
-import "morpherctl/cmd"
+import "github.com/morpher-vm/morpherctl/cmd"
 
 func main() {
 	cmd.Execute()
@@ -0,0 +1,375 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Agent states reported by the controller.
+const (
+	AgentReady    = "ready"
+	AgentNotReady = "not_ready"
+	AgentUnknown  = "unknown"
+)
+
+// Agent represents a morpher agent registered with the controller.
+type Agent struct {
+	ID            string            `json:"ID"`
+	Hostname      string            `json:"Hostname"`
+	Address       string            `json:"Address"`
+	Status        string            `json:"Status"`
+	Version       string            `json:"Version"`
+	Labels        map[string]string `json:"Labels,omitempty"`
+	Annotations   map[string]string `json:"Annotations,omitempty"`
+	Schedulable   bool              `json:"Schedulable"`
+	LastHeartbeat time.Time         `json:"LastHeartbeat"`
+	RegisteredAt  time.Time         `json:"RegisteredAt"`
+	// ActiveMigrations counts pending and running migrations from or to the agent.
+	ActiveMigrations int `json:"ActiveMigrations"`
+}
+
+// ListAgentsOptions filters the agents returned by ListAgents.
+type ListAgentsOptions struct {
+	// Status only returns agents in the given state.
+	Status string
+	// Selector only returns agents whose labels match the label selector.
+	Selector string
+}
+
+// ListAgents returns the agents registered with the controller.
+func (c *Client) ListAgents(ctx context.Context, opts ListAgentsOptions) ([]Agent, error) {
+	query := url.Values{}
+	if opts.Status != "" {
+		query.Set("status", opts.Status)
+	}
+	if opts.Selector != "" {
+		// Invalid selectors are reported before anything is sent.
+		if _, err := ParseSelector(opts.Selector); err != nil {
+			return nil, err
+		}
+		query.Set("selector", opts.Selector)
+	}
+
+	var agents []Agent
+	if err := c.getJSON(ctx, "/agents", query, &agents); err != nil {
+		return nil, fmt.Errorf("failed to list agents: %w", err)
+	}
+	return agents, nil
+}
+
+// GetAgent returns the agent with the given ID.
+func (c *Client) GetAgent(ctx context.Context, id string) (*Agent, error) {
+	var agent Agent
+	if err := c.getJSON(ctx, "/agents/"+url.PathEscape(id), nil, &agent); err != nil {
+		return nil, fmt.Errorf("failed to get agent %q: %w", id, err)
+	}
+	return &agent, nil
+}
+
+// Event types reported by the controller.
+const (
+	EventNormal  = "Normal"
+	EventWarning = "Warning"
+)
+
+// HostFacts describes the machine an agent runs on.
+type HostFacts struct {
+	OS           OSInfo `json:"OS"`
+	Architecture string `json:"Architecture"`
+	CPUModel     string `json:"CPUModel"`
+	CPUCores     int    `json:"CPUCores"`
+	MemoryBytes  uint64 `json:"MemoryBytes"`
+	Disks        []Disk `json:"Disks"`
+	NICs         []NIC  `json:"NICs"`
+}
+
+// Disk is a block device of an agent host.
+type Disk struct {
+	Name       string `json:"Name"`
+	Model      string `json:"Model,omitempty"`
+	SizeBytes  uint64 `json:"SizeBytes"`
+	MountPoint string `json:"MountPoint,omitempty"`
+}
+
+// NIC is a network interface of an agent host.
+type NIC struct {
+	Name      string   `json:"Name"`
+	MAC       string   `json:"MAC"`
+	SpeedMbps int      `json:"SpeedMbps,omitempty"`
+	Addresses []string `json:"Addresses,omitempty"`
+}
+
+// AgentCapabilities are the migration capabilities an agent advertises.
+type AgentCapabilities struct {
+	SourceHypervisors       []string `json:"SourceHypervisors"`
+	TargetHypervisors       []string `json:"TargetHypervisors"`
+	MaxConcurrentMigrations int      `json:"MaxConcurrentMigrations"`
+}
+
+// AgentConfig is the configuration an agent runs with.
+type AgentConfig struct {
+	LogLevel    string `json:"LogLevel"`
+	InstallPath string `json:"InstallPath"`
+}
+
+// AgentDetails is an agent together with its host facts, capabilities and configuration.
+type AgentDetails struct {
+	Agent
+	Host         HostFacts         `json:"Host"`
+	Capabilities AgentCapabilities `json:"Capabilities"`
+	Config       AgentConfig       `json:"Config"`
+}
+
+// Heartbeat is a heartbeat received from an agent.
+type Heartbeat struct {
+	Time    time.Time `json:"Time"`
+	Latency Duration  `json:"Latency"`
+	Status  string    `json:"Status"`
+}
+
+// Event is something that happened to an agent, such as a status change.
+type Event struct {
+	Type     string    `json:"Type"`
+	Reason   string    `json:"Reason"`
+	Message  string    `json:"Message"`
+	Count    int       `json:"Count"`
+	LastSeen time.Time `json:"LastSeen"`
+}
+
+// GetAgentDetails returns the agent with the given ID together with its host
+// facts, capabilities and configuration.
+func (c *Client) GetAgentDetails(ctx context.Context, id string) (*AgentDetails, error) {
+	var details AgentDetails
+	if err := c.getJSON(ctx, "/agents/"+url.PathEscape(id)+"/details", nil, &details); err != nil {
+		return nil, fmt.Errorf("failed to get agent %q: %w", id, err)
+	}
+	return &details, nil
+}
+
+// ListAgentHeartbeats returns up to limit of the most recent heartbeats of
+// an agent, newest first. A limit of zero returns the controller's default.
+func (c *Client) ListAgentHeartbeats(ctx context.Context, id string, limit int) ([]Heartbeat, error) {
+	var heartbeats []Heartbeat
+	if err := c.getJSON(ctx, "/agents/"+url.PathEscape(id)+"/heartbeats", limitQuery(limit), &heartbeats); err != nil {
+		return nil, fmt.Errorf("failed to list heartbeats of agent %q: %w", id, err)
+	}
+	return heartbeats, nil
+}
+
+// ListAgentEvents returns up to limit of the most recent events of an agent,
+// newest first. A limit of zero returns the controller's default.
+func (c *Client) ListAgentEvents(ctx context.Context, id string, limit int) ([]Event, error) {
+	var events []Event
+	if err := c.getJSON(ctx, "/agents/"+url.PathEscape(id)+"/events", limitQuery(limit), &events); err != nil {
+		return nil, fmt.Errorf("failed to list events of agent %q: %w", id, err)
+	}
+	return events, nil
+}
+
+// limitQuery returns the query for a result limit, or nil for no limit.
+func limitQuery(limit int) url.Values {
+	if limit <= 0 {
+		return nil
+	}
+	return url.Values{"limit": {strconv.Itoa(limit)}}
+}
+
+// DeregisterAgent removes an agent from the controller. The controller refuses
+// to remove an agent with active migrations unless force is set.
+func (c *Client) DeregisterAgent(ctx context.Context, id string, force bool) error {
+	var query url.Values
+	if force {
+		query = url.Values{"force": {"true"}}
+	}
+	if err := c.sendJSON(ctx, http.MethodDelete, "/agents/"+url.PathEscape(id), query, nil, nil); err != nil {
+		return fmt.Errorf("failed to deregister agent %q: %w", id, err)
+	}
+	return nil
+}
+
+// CordonAgent marks an agent unschedulable so that no new migrations are
+// scheduled from or to it.
+func (c *Client) CordonAgent(ctx context.Context, id string) error {
+	if err := c.sendJSON(ctx, http.MethodPost, "/agents/"+url.PathEscape(id)+"/cordon", nil, nil, nil); err != nil {
+		return fmt.Errorf("failed to cordon agent %q: %w", id, err)
+	}
+	return nil
+}
+
+// UncordonAgent marks an agent schedulable again.
+func (c *Client) UncordonAgent(ctx context.Context, id string) error {
+	if err := c.sendJSON(ctx, http.MethodPost, "/agents/"+url.PathEscape(id)+"/uncordon", nil, nil, nil); err != nil {
+		return fmt.Errorf("failed to uncordon agent %q: %w", id, err)
+	}
+	return nil
+}
+
+// DefaultDrainPoll is the interval between checks for active migrations
+// used when DrainOptions.Poll is not set.
+const DefaultDrainPoll = 2 * time.Second
+
+// DrainingAnnotation is set by DrainAgent on the agent it drains, with the
+// time the drain started, and removed once the drain ends.
+const DrainingAnnotation = "morpher.io/draining"
+
+// DrainOptions configures DrainAgent.
+type DrainOptions struct {
+	// Poll is the interval between checks for active migrations, by default
+	// DefaultDrainPoll.
+	Poll time.Duration
+	// Timeout limits waiting for active migrations to finish. Zero waits
+	// until the context ends.
+	Timeout time.Duration
+	// Force hands the migrations that are still active after Timeout off to
+	// other agents instead of failing. With a zero Timeout they are handed
+	// off right away.
+	Force bool
+}
+
+// DrainAgent cordons an agent and waits until its active migrations have
+// finished. It returns the migrations that were handed off to other agents.
+func (c *Client) DrainAgent(ctx context.Context, id string, opts DrainOptions) ([]Migration, error) {
+	if opts.Poll <= 0 {
+		opts.Poll = DefaultDrainPoll
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	err := c.CordonAgent(reqCtx, id)
+	if err == nil {
+		_, err = c.UpdateAgentAnnotations(reqCtx, id, MetadataUpdate{
+			Set:       map[string]string{DrainingAnnotation: time.Now().UTC().Format(time.RFC3339)},
+			Overwrite: true,
+		})
+	}
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	defer c.endDrain(ctx, id)
+
+	if !opts.Force {
+		return nil, c.waitDrained(ctx, id, opts.Poll, opts.Timeout)
+	}
+
+	// Give the migrations the timeout to finish before handing them off.
+	if opts.Timeout > 0 {
+		err := c.waitDrained(ctx, id, opts.Poll, opts.Timeout)
+		if !errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil {
+			return nil, err
+		}
+	}
+
+	reqCtx, cancel = context.WithTimeout(ctx, c.timeout)
+	handedOff, err := c.HandOffMigrations(reqCtx, id)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	return handedOff, c.waitDrained(ctx, id, opts.Poll, 0)
+}
+
+// endDrain removes the DrainingAnnotation of an agent, also after ctx has
+// been cancelled. A failure leaves the annotation behind, which is harmless
+// once the agent is uncordoned.
+func (c *Client) endDrain(ctx context.Context, id string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.timeout)
+	defer cancel()
+	_, _ = c.UpdateAgentAnnotations(ctx, id, MetadataUpdate{Remove: []string{DrainingAnnotation}})
+}
+
+// waitDrained waits until an agent has no active migrations, for at most
+// the timeout unless it is zero.
+func (c *Client) waitDrained(ctx context.Context, id string, poll, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	active := -1
+	for {
+		reqCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		agent, err := c.GetAgent(reqCtx, id)
+		cancel()
+		switch {
+		case err != nil && (ctx.Err() == nil || active < 0):
+			return err
+		case err == nil && agent.ActiveMigrations == 0:
+			return nil
+		case err == nil:
+			active = agent.ActiveMigrations
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("agent %q still has %d active migrations: %w", id, active, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// HandOffMigrations asks the controller to move the active migrations of an
+// agent to other agents. Migrations that no other agent can take over are
+// cancelled. It returns the migrations that were changed.
+func (c *Client) HandOffMigrations(ctx context.Context, id string) ([]Migration, error) {
+	var migrations []Migration
+	if err := c.sendJSON(ctx, http.MethodPost, "/agents/"+url.PathEscape(id)+"/handoff", nil, nil, &migrations); err != nil {
+		return nil, fmt.Errorf("failed to hand off migrations of agent %q: %w", id, err)
+	}
+	return migrations, nil
+}
+
+// upgradeRequest is the body of an agent upgrade request.
+type upgradeRequest struct {
+	Version string `json:"Version"`
+}
+
+// UpgradeAgent asks the controller to upgrade an agent to the given version.
+// The agent replaces its binary and restarts; the upgrade has finished when
+// the agent reports the new version in a heartbeat.
+func (c *Client) UpgradeAgent(ctx context.Context, id, version string) error {
+	if err := c.sendJSON(ctx, http.MethodPost, "/agents/"+url.PathEscape(id)+"/upgrade", nil, upgradeRequest{Version: version}, nil); err != nil {
+		return fmt.Errorf("failed to upgrade agent %q: %w", id, err)
+	}
+	return nil
+}
+
+// MetadataUpdate changes the labels or annotations of an agent.
+type MetadataUpdate struct {
+	// Set adds keys or, with Overwrite, changes their values.
+	Set map[string]string `json:"Set,omitempty"`
+	// Remove deletes keys. Missing keys are ignored.
+	Remove []string `json:"Remove,omitempty"`
+	// Overwrite allows Set to change existing values.
+	Overwrite bool `json:"Overwrite"`
+}
+
+// UpdateAgentLabels changes the labels of an agent and returns the updated
+// agent. The controller refuses to change existing values unless
+// update.Overwrite is set.
+func (c *Client) UpdateAgentLabels(ctx context.Context, id string, update MetadataUpdate) (*Agent, error) {
+	var agent Agent
+	if err := c.sendJSON(ctx, http.MethodPatch, "/agents/"+url.PathEscape(id)+"/labels", nil, update, &agent); err != nil {
+		return nil, fmt.Errorf("failed to label agent %q: %w", id, err)
+	}
+	return &agent, nil
+}
+
+// UpdateAgentAnnotations changes the annotations of an agent and returns the
+// updated agent. The controller refuses to change existing values unless
+// update.Overwrite is set.
+func (c *Client) UpdateAgentAnnotations(ctx context.Context, id string, update MetadataUpdate) (*Agent, error) {
+	var agent Agent
+	if err := c.sendJSON(ctx, http.MethodPatch, "/agents/"+url.PathEscape(id)+"/annotations", nil, update, &agent); err != nil {
+		return nil, fmt.Errorf("failed to annotate agent %q: %w", id, err)
+	}
+	return &agent, nil
+}
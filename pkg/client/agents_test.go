@@ -0,0 +1,257 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+)
+
+func TestClient_ListAgents(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          ListAgentsOptions
+		expectedQuery string
+	}{
+		{
+			name: "should list all agents",
+		},
+		{
+			name:          "should filter by status and selector",
+			opts:          ListAgentsOptions{Status: AgentReady, Selector: "zone=a"},
+			expectedQuery: "selector=zone%3Da&status=ready",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/agents", r.URL.Path)
+				assert.Equal(t, tt.expectedQuery, r.URL.RawQuery)
+				require.NoError(t, json.NewEncoder(w).Encode([]Agent{
+					{ID: "agent-1", Hostname: "node1", Status: AgentReady, Labels: map[string]string{"zone": "a"}},
+				}))
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, 30*time.Second, "")
+
+			agents, err := client.ListAgents(context.Background(), tt.opts)
+			require.NoError(t, err)
+			require.Len(t, agents, 1)
+			assert.Equal(t, "agent-1", agents[0].ID)
+			assert.Equal(t, "a", agents[0].Labels["zone"])
+		})
+	}
+}
+
+func TestClient_GetAgent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/agents/agent-1" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": "agent not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ID": "agent-1", "Hostname": "node1", "Status": "ready", "Schedulable": true}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, 30*time.Second, "")
+
+	t.Run("should return agent", func(t *testing.T) {
+		agent, err := client.GetAgent(context.Background(), "agent-1")
+		require.NoError(t, err)
+		assert.Equal(t, "node1", agent.Hostname)
+		assert.True(t, agent.Schedulable)
+	})
+
+	t.Run("should return status error for unknown agent", func(t *testing.T) {
+		_, err := client.GetAgent(context.Background(), "missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "agent not found")
+		assert.Equal(t, errdefs.KindNotFound, errdefs.KindOf(err))
+	})
+}
+
+func TestClient_AgentDetails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/agents/agent-1/details":
+			_, _ = w.Write([]byte(`{"ID": "agent-1", "Host": {"CPUCores": 16}, "Config": {"LogLevel": "debug"}}`))
+		case "/agents/agent-1/heartbeats":
+			assert.Equal(t, "limit=5", r.URL.RawQuery)
+			_, _ = w.Write([]byte(`[{"Time": "2026-01-02T03:04:05Z", "Latency": "4ms", "Status": "ready"}]`))
+		case "/agents/agent-1/events":
+			assert.Empty(t, r.URL.RawQuery)
+			_, _ = w.Write([]byte(`[{"Type": "Warning", "Reason": "HeartbeatMissed", "Count": 3}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": "agent not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, 30*time.Second, "")
+	ctx := context.Background()
+
+	t.Run("should return details", func(t *testing.T) {
+		details, err := client.GetAgentDetails(ctx, "agent-1")
+		require.NoError(t, err)
+		assert.Equal(t, "agent-1", details.ID)
+		assert.Equal(t, 16, details.Host.CPUCores)
+		assert.Equal(t, "debug", details.Config.LogLevel)
+	})
+
+	t.Run("should send heartbeat limit", func(t *testing.T) {
+		heartbeats, err := client.ListAgentHeartbeats(ctx, "agent-1", 5)
+		require.NoError(t, err)
+		require.Len(t, heartbeats, 1)
+		assert.Equal(t, Duration(4*time.Millisecond), heartbeats[0].Latency)
+	})
+
+	t.Run("should omit zero event limit", func(t *testing.T) {
+		events, err := client.ListAgentEvents(ctx, "agent-1", 0)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, EventWarning, events[0].Type)
+		assert.Equal(t, 3, events[0].Count)
+	})
+
+	t.Run("should return not found for unknown agent", func(t *testing.T) {
+		_, err := client.GetAgentDetails(ctx, "missing")
+		assert.Equal(t, errdefs.KindNotFound, errdefs.KindOf(err))
+	})
+}
+
+func TestClient_DeregisterAgent(t *testing.T) {
+	tests := []struct {
+		name          string
+		force         bool
+		expectedQuery string
+	}{
+		{
+			name: "should send delete request",
+		},
+		{
+			name:          "should send force",
+			force:         true,
+			expectedQuery: "force=true",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodDelete, r.Method)
+				assert.Equal(t, "/agents/agent-1", r.URL.Path)
+				assert.Equal(t, tt.expectedQuery, r.URL.RawQuery)
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, 30*time.Second, "")
+			require.NoError(t, client.DeregisterAgent(context.Background(), "agent-1", tt.force))
+		})
+	}
+}
+
+func TestClient_DrainAgent(t *testing.T) {
+	var polls int
+	var updates []MetadataUpdate
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /agents/agent-1/cordon":
+			w.WriteHeader(http.StatusNoContent)
+		case "PATCH /agents/agent-1/annotations":
+			var update MetadataUpdate
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&update))
+			updates = append(updates, update)
+			_ = json.NewEncoder(w).Encode(Agent{ID: "agent-1"})
+		case "GET /agents/agent-1":
+			polls++
+			_ = json.NewEncoder(w).Encode(Agent{ID: "agent-1", ActiveMigrations: 3 - polls})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, 30*time.Second, "")
+	_, err := client.DrainAgent(context.Background(), "agent-1", DrainOptions{Poll: time.Millisecond})
+	require.NoError(t, err)
+	assert.Equal(t, 3, polls)
+
+	t.Run("should annotate the agent while draining", func(t *testing.T) {
+		require.Len(t, updates, 2)
+		assert.Contains(t, updates[0].Set, DrainingAnnotation)
+		assert.True(t, updates[0].Overwrite)
+		assert.Equal(t, []string{DrainingAnnotation}, updates[1].Remove)
+	})
+
+	t.Run("should report remaining migrations", func(t *testing.T) {
+		polls = -100
+		updates = nil
+		_, err := client.DrainAgent(context.Background(), "agent-1", DrainOptions{Poll: time.Millisecond, Timeout: 20 * time.Millisecond})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorContains(t, err, "still has")
+		require.Len(t, updates, 2)
+		assert.Equal(t, []string{DrainingAnnotation}, updates[1].Remove)
+	})
+
+	t.Run("should default the poll interval", func(t *testing.T) {
+		idle := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				_ = json.NewEncoder(w).Encode(Agent{ID: "agent-1"})
+			}
+		}))
+		defer idle.Close()
+
+		_, err := NewClient(idle.URL, 30*time.Second, "").DrainAgent(context.Background(), "agent-1", DrainOptions{})
+		require.NoError(t, err)
+	})
+}
+
+func TestClient_ListAgents_InvalidSelector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s", r.URL)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, 30*time.Second, "")
+	_, err := client.ListAgents(context.Background(), ListAgentsOptions{Selector: "zone in a"})
+	assert.ErrorContains(t, err, `invalid selector "zone in a"`)
+}
+
+func TestClient_UpdateAgentLabels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		assert.Equal(t, "/agents/agent-1/labels", r.URL.Path)
+
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]any{
+			"Set":       map[string]any{"site": "dc1"},
+			"Remove":    []any{"tier"},
+			"Overwrite": true,
+		}, body)
+
+		_, _ = w.Write([]byte(`{"ID":"agent-1","Labels":{"site":"dc1"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, 30*time.Second, "")
+	agent, err := client.UpdateAgentLabels(context.Background(), "agent-1", MetadataUpdate{
+		Set:       map[string]string{"site": "dc1"},
+		Remove:    []string{"tier"},
+		Overwrite: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"site": "dc1"}, agent.Labels)
+}
@@ -0,0 +1,361 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// APIVersion is the version of the controller API implemented by this package.
+const APIVersion = "v1"
+
+// ControllerVersionHeader is the response header carrying the controller version.
+const ControllerVersionHeader = "X-Controller-Version"
+
+// DefaultTimeout is the request timeout used when none is configured.
+const DefaultTimeout = 30 * time.Second
+
+// Client handles communication with the morpher controller.
+// A Client is safe for concurrent use.
+type Client struct {
+	baseURL      string
+	timeout      time.Duration
+	httpClient   *http.Client
+	streamClient *http.Client
+	token        string
+	tlsConfig    *tls.Config
+	transport    http.RoundTripper
+	retries      int
+	retryBackoff time.Duration
+	endpoints    []string
+	strategy     Strategy
+	cooldown     time.Duration
+	pool         *endpointPool
+	onVersion    func(version string)
+	userAgent    string
+	requestID    string
+	traceID      string
+}
+
+// PingResponse represents the response from a ping request.
+type PingResponse struct {
+	StatusCode   int    `json:"status_code"`
+	ResponseTime string `json:"response_time,omitempty"`
+	Success      bool   `json:"success"`
+	Message      string `json:"message,omitempty"`
+}
+
+// OSInfo represents operating system information.
+type OSInfo struct {
+	Name            string `json:"Name"`
+	PlatformName    string `json:"PlatformName"`
+	PlatformVersion string `json:"PlatformVersion"`
+	KernelVersion   string `json:"KernelVersion"`
+}
+
+// InfoResult represents the result data in info response.
+type InfoResult struct {
+	Version         string   `json:"Version"`
+	GitCommit       string   `json:"GitCommit"`
+	APIVersions     []string `json:"APIVersions"`
+	Features        []string `json:"Features"`
+	ListenAddresses []string `json:"ListenAddresses"`
+	ConnectedAgents int      `json:"ConnectedAgents"`
+	OS              OSInfo   `json:"OS"`
+	GoVersion       string   `json:"GoVersion"`
+	UpTime          Duration `json:"UpTime"`
+	// MinAgentVersion and MaxAgentVersion bound the agent versions the
+	// controller supports, for example "v1.2" and "v1.4".
+	MinAgentVersion string `json:"MinAgentVersion,omitempty"`
+	MaxAgentVersion string `json:"MaxAgentVersion,omitempty"`
+}
+
+// InfoResponse represents the response from an info request.
+type InfoResponse struct {
+	StatusCode int         `json:"status_code"`
+	Success    bool        `json:"success"`
+	Message    string      `json:"message,omitempty"`
+	Result     *InfoResult `json:"result,omitempty"`
+}
+
+// New creates a controller client for the given base URL, for example
+// "https://controller.example.com:9000".
+func New(baseURL string, opts ...Option) (*Client, error) {
+	if err := validateURL(baseURL); err != nil {
+		return nil, err
+	}
+
+	c := newClient(strings.TrimSuffix(baseURL, "/"), opts...)
+	for _, endpoint := range c.pool.urls[1:] {
+		if err := validateURL(endpoint); err != nil {
+			return nil, err
+		}
+	}
+	if err := ValidateTraceID(c.traceID); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// validateURL checks that a controller URL is an absolute http or https URL.
+func validateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid controller URL %q: %w", rawURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid controller URL %q: scheme must be http or https", rawURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid controller URL %q: missing host", rawURL)
+	}
+	return nil
+}
+
+// NewClient creates a new controller client with the given timeout and token.
+// Unlike New, it does not validate the base URL.
+func NewClient(baseURL string, timeout time.Duration, token string) *Client {
+	return newClient(baseURL, WithTimeout(timeout), WithToken(token))
+}
+
+func newClient(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:      baseURL,
+		timeout:      DefaultTimeout,
+		retryBackoff: defaultRetryBackoff,
+		cooldown:     DefaultCooldown,
+		userAgent:    defaultUserAgent,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.requestID == "" {
+		c.requestID = NewRequestID()
+	}
+	if c.traceID == "" {
+		c.traceID = NewTraceID()
+	}
+
+	urls := []string{baseURL}
+	for _, endpoint := range c.endpoints {
+		if endpoint = strings.TrimSuffix(endpoint, "/"); endpoint != baseURL {
+			urls = append(urls, endpoint)
+		}
+	}
+	c.pool = newEndpointPool(urls, c.strategy, c.cooldown)
+
+	transport := c.transport
+	if transport == nil {
+		defaultTransport := http.DefaultTransport.(*http.Transport).Clone()
+		if c.tlsConfig != nil {
+			defaultTransport.TLSClientConfig = c.tlsConfig
+		}
+		transport = defaultTransport
+	}
+	c.httpClient = &http.Client{
+		Timeout:   c.timeout,
+		Transport: transport,
+	}
+	// Streamed responses are read for as long as the caller's context allows.
+	c.streamClient = &http.Client{Transport: transport}
+
+	return c
+}
+
+// newRequest creates a new HTTP request with context, client identification,
+// trace context and authorization.
+func (c *Client) newRequest(ctx context.Context, method, path string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set(RequestIDHeader, c.requestID)
+	req.Header.Set(TraceParentHeader, traceParent(c.traceID))
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	return req, nil
+}
+
+// do sends the request, failing over between endpoints and retrying
+// idempotent requests on transient failures.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	return c.doWith(c.httpClient, req)
+}
+
+// doWith is do with the given HTTP client.
+func (c *Client) doWith(httpClient *http.Client, req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := c.sendWithFailover(httpClient, req)
+		if attempt >= c.retries || !shouldRetry(req, resp, err) {
+			if resp != nil && c.onVersion != nil {
+				if v := resp.Header.Get(ControllerVersionHeader); v != "" {
+					c.onVersion(v)
+				}
+			}
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(c.retryBackoff * time.Duration(attempt+1))
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// shouldRetry reports whether a failed attempt may be repeated. Only
+// idempotent methods are retried, on network errors and gateway failures.
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	return isIdempotent(req.Method) && endpointFailed(req, resp, err)
+}
+
+// getJSON sends a GET request and decodes a successful JSON response into out.
+// Non-2xx responses are returned as *StatusError.
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, out any) error {
+	return c.sendJSON(ctx, http.MethodGet, path, query, nil, out)
+}
+
+// sendJSON sends a request with in as JSON body, unless in is nil, and decodes
+// a successful JSON response into out, unless out is nil. Non-2xx responses
+// are returned as *StatusError.
+func (c *Client) sendJSON(ctx context.Context, method, path string, query url.Values, in, out any) error {
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	req, err := c.newRequest(ctx, method, path)
+	if err != nil {
+		return err
+	}
+
+	if in != nil {
+		body, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+		req.ContentLength = int64(len(body))
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return NewStatusError(resp.StatusCode, readErrorMessage(resp))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return nil
+}
+
+// Ping sends a ping request to the controller.
+func (c *Client) Ping(ctx context.Context) (*PingResponse, error) {
+	req, err := c.newRequest(ctx, "GET", "/ping")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ping request: %w", err)
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send ping request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	response := &PingResponse{
+		StatusCode:   resp.StatusCode,
+		ResponseTime: resp.Header.Get("X-Response-Time"),
+		Success:      resp.StatusCode == http.StatusOK,
+	}
+	if !response.Success {
+		response.Message = readErrorMessage(resp)
+	}
+
+	return response, nil
+}
+
+// GetInfo retrieves detailed controller information.
+func (c *Client) GetInfo(ctx context.Context) (*InfoResponse, error) {
+	req, err := c.newRequest(ctx, "GET", "/info")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create info request: %w", err)
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get controller info: %w", err)
+	}
+	defer resp.Body.Close()
+
+	response := &InfoResponse{
+		StatusCode: resp.StatusCode,
+		Success:    resp.StatusCode == http.StatusOK,
+	}
+
+	// If the request was successful, try to parse the response body.
+	if resp.StatusCode == http.StatusOK {
+		var result InfoResult
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return response, fmt.Errorf("failed to parse controller info response: %w", err)
+		}
+		response.Result = &result
+	} else {
+		response.Message = readErrorMessage(resp)
+	}
+
+	return response, nil
+}
+
+// IsHealthy checks if the controller is healthy based on ping response.
+func (c *Client) IsHealthy(ctx context.Context) (bool, error) {
+	response, err := c.Ping(ctx)
+	if err != nil {
+		return false, err
+	}
+	return response.Success, nil
+}
+
+// GetBaseURL returns the base URL of the controller.
+func (c *Client) GetBaseURL() string {
+	return c.baseURL
+}
+
+// RequestID returns the ID sent in the RequestIDHeader of every request.
+func (c *Client) RequestID() string {
+	return c.requestID
+}
+
+// TraceID returns the trace ID sent in the TraceParentHeader of every request.
+func (c *Client) TraceID() string {
+	return c.traceID
+}
+
+// GetTimeout returns the timeout setting of the client.
+func (c *Client) GetTimeout() time.Duration {
+	return c.timeout
+}
@@ -1,4 +1,4 @@
-package controller
+package client
 
 import (
 	"context"
@@ -150,6 +150,12 @@ func testHTTPResponse(t *testing.T, path string, statusCode int, expectedSuccess
 			if statusCode == 200 {
 				// Return valid JSON for info endpoint.
 				jsonResponse := `{
+					"Version": "v1.3.0",
+					"GitCommit": "abc1234",
+					"APIVersions": ["v1"],
+					"Features": ["live-migration"],
+					"ListenAddresses": [":9000"],
+					"ConnectedAgents": 4,
 					"OS": {
 						"Name": "darwin",
 						"PlatformName": "darwin",
@@ -193,6 +199,13 @@ func testHTTPResponse(t *testing.T, path string, statusCode int, expectedSuccess
 	case *InfoResponse:
 		assert.Equal(t, statusCode, r.StatusCode)
 		assert.Equal(t, expectedSuccess, r.Success)
+		if expectedSuccess {
+			require.NotNil(t, r.Result)
+			assert.Equal(t, "v1.3.0", r.Result.Version)
+			assert.Equal(t, 4, r.Result.ConnectedAgents)
+			assert.Equal(t, "darwin", r.Result.OS.PlatformName)
+			assert.Equal(t, Duration(32*time.Second), r.Result.UpTime)
+		}
 	default:
 		t.Fatalf("unexpected response type: %T", response)
 	}
@@ -0,0 +1,17 @@
+// Package client is a Go client for the morpher controller API.
+//
+// The package is the supported way to talk to a controller from other Go
+// programs and is used by morpherctl itself. It implements version APIVersion
+// of the controller API; exported types and functions follow semantic
+// versioning and are only changed in backwards compatible ways.
+//
+// Create a client with New and configure it with options:
+//
+//	c, err := client.New("https://controller.example.com:9000",
+//		client.WithToken(token),
+//		client.WithTimeout(10*time.Second),
+//		client.WithRetries(3, 0),
+//	)
+//
+// Requests that fail with a non-2xx status code return a *StatusError.
+package client
@@ -0,0 +1,46 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuration_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    Duration
+		expectError bool
+	}{
+		{name: "should parse duration string", input: `"1h2m3s"`, expected: Duration(time.Hour + 2*time.Minute + 3*time.Second)},
+		{name: "should parse fractional duration string", input: `"32.5s"`, expected: Duration(32500 * time.Millisecond)},
+		{name: "should parse seconds as number", input: `90`, expected: Duration(90 * time.Second)},
+		{name: "should treat empty string as zero", input: `""`, expected: 0},
+		{name: "should treat null as zero", input: `null`, expected: 0},
+		{name: "should reject invalid duration", input: `"forever"`, expectError: true},
+		{name: "should reject unsupported type", input: `true`, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d Duration
+			err := json.Unmarshal([]byte(tt.input), &d)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, d)
+		})
+	}
+}
+
+func TestDuration_MarshalJSON(t *testing.T) {
+	data, err := json.Marshal(Duration(90 * time.Second))
+	require.NoError(t, err)
+	assert.JSONEq(t, `"1m30s"`, string(data))
+}
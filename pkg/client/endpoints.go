@@ -0,0 +1,255 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCooldown is how long a failed endpoint is avoided when none is configured.
+const DefaultCooldown = 30 * time.Second
+
+// Strategy selects the order in which endpoints are tried.
+type Strategy int
+
+const (
+	// StrategyOrdered always tries endpoints in the configured order.
+	StrategyOrdered Strategy = iota
+	// StrategyRoundRobin starts each request at the next endpoint.
+	StrategyRoundRobin
+)
+
+// ParseStrategy parses "ordered" or "round-robin".
+func ParseStrategy(s string) (Strategy, error) {
+	switch s {
+	case "", "ordered":
+		return StrategyOrdered, nil
+	case "round-robin":
+		return StrategyRoundRobin, nil
+	default:
+		return StrategyOrdered, fmt.Errorf("invalid endpoint strategy %q: must be ordered or round-robin", s)
+	}
+}
+
+// String returns the strategy in the form accepted by ParseStrategy.
+func (s Strategy) String() string {
+	if s == StrategyRoundRobin {
+		return "round-robin"
+	}
+	return "ordered"
+}
+
+// endpointPool tracks the health of the controller endpoints of a client.
+type endpointPool struct {
+	mu             sync.Mutex
+	urls           []string
+	strategy       Strategy
+	cooldown       time.Duration
+	unhealthyUntil []time.Time
+	next           int
+	now            func() time.Time
+}
+
+func newEndpointPool(urls []string, strategy Strategy, cooldown time.Duration) *endpointPool {
+	return &endpointPool{
+		urls:           urls,
+		strategy:       strategy,
+		cooldown:       cooldown,
+		unhealthyUntil: make([]time.Time, len(urls)),
+		now:            time.Now,
+	}
+}
+
+// order returns the endpoint indexes to try for a request. Endpoints in
+// cooldown are tried last, the one recovering first ahead of the others.
+func (p *endpointPool) order() []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	start := 0
+	if p.strategy == StrategyRoundRobin {
+		start = p.next % len(p.urls)
+		p.next++
+	}
+
+	now := p.now()
+	var healthy, cooling []int
+	for k := range p.urls {
+		i := (start + k) % len(p.urls)
+		if now.Before(p.unhealthyUntil[i]) {
+			cooling = append(cooling, i)
+		} else {
+			healthy = append(healthy, i)
+		}
+	}
+	slices.SortStableFunc(cooling, func(a, b int) int {
+		return p.unhealthyUntil[a].Compare(p.unhealthyUntil[b])
+	})
+
+	return append(healthy, cooling...)
+}
+
+// markFailed puts the endpoint into cooldown.
+func (p *endpointPool) markFailed(i int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.unhealthyUntil[i] = p.now().Add(p.cooldown)
+}
+
+// markHealthy ends the cooldown of the endpoint.
+func (p *endpointPool) markHealthy(i int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.unhealthyUntil[i] = time.Time{}
+}
+
+// Endpoints returns the controller endpoints of the client, starting with the base URL.
+func (c *Client) Endpoints() []string {
+	return slices.Clone(c.pool.urls)
+}
+
+// ForEndpoint returns a copy of the client that only talks to the given endpoint.
+func (c *Client) ForEndpoint(endpoint string) *Client {
+	single := *c
+	single.baseURL = endpoint
+	single.endpoints = nil
+	single.pool = newEndpointPool([]string{endpoint}, c.pool.strategy, c.pool.cooldown)
+	return &single
+}
+
+// sendWithFailover sends the request to the endpoints in pool order. Idempotent
+// requests move on to the next endpoint when an endpoint fails; other requests
+// are only sent once since they may already have been applied.
+func (c *Client) sendWithFailover(httpClient *http.Client, req *http.Request) (*http.Response, error) {
+	order := c.pool.order()
+	for n, i := range order {
+		target, err := rebaseRequest(req, c.baseURL, c.pool.urls[i])
+		if err != nil {
+			return nil, err
+		}
+
+		var timeout time.Duration
+		if isIdempotent(req.Method) {
+			timeout = c.attemptTimeout(req.Context(), len(order)-n)
+		}
+		resp, err := sendToEndpoint(httpClient, target, timeout)
+		if !endpointFailed(req, resp, err) {
+			c.pool.markHealthy(i)
+			return resp, err
+		}
+		c.pool.markFailed(i)
+
+		if !isIdempotent(req.Method) || n == len(order)-1 {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+			resp.Body.Close()
+		}
+	}
+
+	return nil, errors.New("no controller endpoints configured")
+}
+
+// attemptTimeout returns how long an endpoint may take to respond when
+// remaining endpoints are left to try, so that an endpoint that never answers
+// leaves time to fail over. The deadline of ctx is shared evenly; without one
+// each endpoint gets the client timeout. The last endpoint is not limited.
+func (c *Client) attemptTimeout(ctx context.Context, remaining int) time.Duration {
+	if remaining <= 1 {
+		return 0
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		return time.Until(deadline) / time.Duration(remaining)
+	}
+	return c.timeout
+}
+
+// sendToEndpoint sends the request and gives up when the endpoint has not
+// responded within timeout, unless timeout is 0. Reading the response body is
+// only limited by the context of the request.
+func sendToEndpoint(httpClient *http.Client, req *http.Request, timeout time.Duration) (*http.Response, error) {
+	if timeout <= 0 {
+		return httpClient.Do(req)
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	timer := time.AfterFunc(timeout, cancel)
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	if !timer.Stop() {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		cancel()
+		return nil, fmt.Errorf("%s did not respond within %s: %w", req.URL.Host, timeout, context.DeadlineExceeded)
+	}
+	if err != nil {
+		cancel()
+		return resp, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose releases the context of a request when its response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the request context.
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// rebaseRequest returns a copy of the request addressed to endpoint instead of
+// base, with a fresh body.
+func rebaseRequest(req *http.Request, base, endpoint string) (*http.Request, error) {
+	target := req.Clone(req.Context())
+	if endpoint != base {
+		u, err := url.Parse(endpoint + strings.TrimPrefix(req.URL.String(), base))
+		if err != nil {
+			return nil, fmt.Errorf("failed to address endpoint %s: %w", endpoint, err)
+		}
+		target.URL = u
+		target.Host = u.Host
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to rewind request body: %w", err)
+		}
+		target.Body = body
+	}
+
+	return target, nil
+}
+
+// endpointFailed reports whether the endpoint itself failed to serve the
+// request: a network error or a gateway error in front of the controller.
+func endpointFailed(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		return req.Context().Err() == nil
+	}
+	return resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusGatewayTimeout
+}
+
+// isIdempotent reports whether a request with the method may be sent again.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingServer starts a server answering every request with status and
+// counting the requests it receives.
+func countingServer(t *testing.T, status int) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestClient_Failover(t *testing.T) {
+	t.Run("should fail over and avoid failed endpoint during cooldown", func(t *testing.T) {
+		bad, badRequests := countingServer(t, http.StatusBadGateway)
+		good, goodRequests := countingServer(t, http.StatusOK)
+
+		c, err := New(bad.URL, WithEndpoints(good.URL))
+		require.NoError(t, err)
+
+		for range 3 {
+			ping, err := c.Ping(context.Background())
+			require.NoError(t, err)
+			assert.True(t, ping.Success)
+		}
+		assert.Equal(t, int32(1), atomic.LoadInt32(badRequests))
+		assert.Equal(t, int32(3), atomic.LoadInt32(goodRequests))
+	})
+
+	t.Run("should retry failed endpoint after cooldown", func(t *testing.T) {
+		bad, badRequests := countingServer(t, http.StatusBadGateway)
+		good, _ := countingServer(t, http.StatusOK)
+
+		c, err := New(bad.URL, WithEndpoints(good.URL), WithCooldown(time.Minute))
+		require.NoError(t, err)
+		now := time.Now()
+		c.pool.now = func() time.Time { return now }
+
+		_, err = c.Ping(context.Background())
+		require.NoError(t, err)
+		now = now.Add(2 * time.Minute)
+		_, err = c.Ping(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, int32(2), atomic.LoadInt32(badRequests))
+	})
+
+	t.Run("should fail over on connection errors", func(t *testing.T) {
+		dead := httptest.NewServer(http.NotFoundHandler())
+		dead.Close()
+		good, _ := countingServer(t, http.StatusOK)
+
+		c, err := New(dead.URL, WithEndpoints(good.URL))
+		require.NoError(t, err)
+
+		ping, err := c.Ping(context.Background())
+		require.NoError(t, err)
+		assert.True(t, ping.Success)
+	})
+
+	t.Run("should return last failure when all endpoints fail", func(t *testing.T) {
+		first, _ := countingServer(t, http.StatusBadGateway)
+		second, _ := countingServer(t, http.StatusGatewayTimeout)
+
+		c, err := New(first.URL, WithEndpoints(second.URL))
+		require.NoError(t, err)
+
+		ping, err := c.Ping(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusGatewayTimeout, ping.StatusCode)
+	})
+
+	t.Run("should fail over from an endpoint that never responds", func(t *testing.T) {
+		hung := make(chan struct{})
+		blackhole := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-hung:
+			}
+		}))
+		t.Cleanup(blackhole.Close)
+		t.Cleanup(func() { close(hung) })
+		good, goodRequests := countingServer(t, http.StatusOK)
+
+		c, err := New(blackhole.URL, WithEndpoints(good.URL))
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		ping, err := c.Ping(ctx)
+		require.NoError(t, err)
+		assert.True(t, ping.Success)
+		assert.Equal(t, int32(1), atomic.LoadInt32(goodRequests))
+	})
+
+	t.Run("should not fail over non-idempotent requests", func(t *testing.T) {
+		bad, _ := countingServer(t, http.StatusBadGateway)
+		good, goodRequests := countingServer(t, http.StatusOK)
+
+		c, err := New(bad.URL, WithEndpoints(good.URL))
+		require.NoError(t, err)
+
+		req, err := c.newRequest(context.Background(), http.MethodPost, "/ping")
+		require.NoError(t, err)
+		resp, err := c.do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+		assert.Equal(t, int32(0), atomic.LoadInt32(goodRequests))
+	})
+
+	t.Run("should spread requests with round-robin", func(t *testing.T) {
+		first, firstRequests := countingServer(t, http.StatusOK)
+		second, secondRequests := countingServer(t, http.StatusOK)
+
+		c, err := New(first.URL, WithEndpoints(second.URL), WithStrategy(StrategyRoundRobin))
+		require.NoError(t, err)
+
+		for range 4 {
+			_, err := c.Ping(context.Background())
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int32(2), atomic.LoadInt32(firstRequests))
+		assert.Equal(t, int32(2), atomic.LoadInt32(secondRequests))
+	})
+}
+
+func TestClient_Endpoints(t *testing.T) {
+	c, err := New("http://a:9000/", WithEndpoints("http://b:9000", "http://a:9000"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http://a:9000", "http://b:9000"}, c.Endpoints())
+
+	single := c.ForEndpoint("http://b:9000")
+	assert.Equal(t, "http://b:9000", single.GetBaseURL())
+	assert.Equal(t, []string{"http://b:9000"}, single.Endpoints())
+	assert.Equal(t, []string{"http://a:9000", "http://b:9000"}, c.Endpoints())
+
+	_, err = New("http://a:9000", WithEndpoints("b:9000"))
+	assert.Error(t, err)
+}
+
+func TestParseStrategy(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    Strategy
+		expectError bool
+	}{
+		{input: "", expected: StrategyOrdered},
+		{input: "ordered", expected: StrategyOrdered},
+		{input: "round-robin", expected: StrategyRoundRobin},
+		{input: "random", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run("should parse "+tt.input, func(t *testing.T) {
+			strategy, err := ParseStrategy(tt.input)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, strategy)
+		})
+	}
+}
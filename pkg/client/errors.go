@@ -0,0 +1,86 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBody limits how much of an error response body is kept as message.
+const maxErrorBody = 4096
+
+// StatusError is returned when the controller answers with a non-2xx status code.
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+// NewStatusError creates an error for an unexpected controller response.
+func NewStatusError(statusCode int, message string) *StatusError {
+	return &StatusError{StatusCode: statusCode, Message: message}
+}
+
+// Error returns the status code and the message reported by the controller.
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf("controller returned %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// HTTPStatus returns the HTTP status code of the response.
+func (e *StatusError) HTTPStatus() int {
+	return e.StatusCode
+}
+
+// Err returns a StatusError if the ping was not successful.
+func (r *PingResponse) Err() error {
+	if r.Success {
+		return nil
+	}
+	return NewStatusError(r.StatusCode, r.Message)
+}
+
+// Err returns a StatusError if the info request was not successful.
+func (r *InfoResponse) Err() error {
+	if r.Success {
+		return nil
+	}
+	return NewStatusError(r.StatusCode, r.Message)
+}
+
+// Err returns a StatusError if the controller did not report its health.
+// An unhealthy controller that still describes its components is not an error.
+func (r *StatusResponse) Err() error {
+	if r.Result != nil {
+		return nil
+	}
+	return NewStatusError(r.StatusCode, r.Message)
+}
+
+// readErrorMessage extracts the error message from a failed response.
+// JSON bodies with an "error" or "message" field are reduced to that field.
+func readErrorMessage(resp *http.Response) string {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err != nil {
+		return ""
+	}
+
+	var payload struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &payload) == nil {
+		if payload.Error != "" {
+			return payload.Error
+		}
+		if payload.Message != "" {
+			return payload.Message
+		}
+	}
+
+	return strings.TrimSpace(string(body))
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+)
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		name         string
+		statusCode   int
+		body         string
+		expectedMsg  string
+		expectedKind errdefs.Kind
+	}{
+		{
+			name:         "should use error field of json body",
+			statusCode:   401,
+			body:         `{"error": "invalid token"}`,
+			expectedMsg:  "controller returned 401 Unauthorized: invalid token",
+			expectedKind: errdefs.KindAuth,
+		},
+		{
+			name:         "should use message field of json body",
+			statusCode:   404,
+			body:         `{"message": "no such endpoint"}`,
+			expectedMsg:  "controller returned 404 Not Found: no such endpoint",
+			expectedKind: errdefs.KindNotFound,
+		},
+		{
+			name:         "should use plain text body",
+			statusCode:   500,
+			body:         "database unavailable\n",
+			expectedMsg:  "controller returned 500 Internal Server Error: database unavailable",
+			expectedKind: errdefs.KindServer,
+		},
+		{
+			name:         "should omit empty body",
+			statusCode:   502,
+			expectedMsg:  "controller returned 502 Bad Gateway",
+			expectedKind: errdefs.KindServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				_, err := w.Write([]byte(tt.body))
+				require.NoError(t, err)
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, 30*time.Second, "")
+
+			ping, err := client.Ping(context.Background())
+			require.NoError(t, err)
+			pingErr := ping.Err()
+			require.Error(t, pingErr)
+			assert.Equal(t, tt.expectedMsg, pingErr.Error())
+			assert.Equal(t, tt.expectedKind, errdefs.KindOf(pingErr))
+
+			info, err := client.GetInfo(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedMsg, info.Err().Error())
+
+			status, err := client.GetStatus(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedMsg, status.Err().Error())
+		})
+	}
+}
+
+func TestResponse_ErrOnSuccess(t *testing.T) {
+	assert.NoError(t, (&PingResponse{StatusCode: 200, Success: true}).Err())
+	assert.NoError(t, (&InfoResponse{StatusCode: 200, Success: true}).Err())
+	assert.NoError(t, (&StatusResponse{StatusCode: 503, Result: &StatusResult{Status: HealthUnhealthy}}).Err())
+}
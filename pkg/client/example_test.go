@@ -0,0 +1,89 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/pkg/client"
+	"github.com/morpher-vm/morpherctl/pkg/fakecontroller"
+)
+
+// newExampleServer starts a fake controller for the examples.
+func newExampleServer() *httptest.Server {
+	return httptest.NewServer(fakecontroller.New(
+		fakecontroller.WithInfo(client.InfoResult{Version: "1.4.0", ConnectedAgents: 2}),
+		fakecontroller.WithAgents(client.Agent{ID: "agent-1", Hostname: "node1", Status: client.AgentReady}),
+	))
+}
+
+func ExampleNew() {
+	server := newExampleServer()
+	defer server.Close()
+
+	c, err := client.New(server.URL,
+		client.WithTimeout(5*time.Second),
+		client.WithToken("my-token"),
+		client.WithRetries(2, 0),
+	)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	healthy, err := c.IsHealthy(context.Background())
+	fmt.Println(healthy, err)
+	// Output: true <nil>
+}
+
+func ExampleClient_GetInfo() {
+	server := newExampleServer()
+	defer server.Close()
+
+	c, _ := client.New(server.URL)
+	response, err := c.GetInfo(context.Background())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := response.Err(); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(response.Result.Version, response.Result.ConnectedAgents)
+	// Output: 1.4.0 2
+}
+
+func ExampleClient_ListAgents() {
+	server := newExampleServer()
+	defer server.Close()
+
+	c, _ := client.New(server.URL)
+	agents, err := c.ListAgents(context.Background(), client.ListAgentsOptions{Status: client.AgentReady})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, agent := range agents {
+		fmt.Println(agent.ID, agent.Hostname, agent.Status)
+	}
+	// Output: agent-1 node1 ready
+}
+
+func ExampleStatusError() {
+	server := newExampleServer()
+	defer server.Close()
+
+	c, _ := client.New(server.URL)
+	_, err := c.GetMigration(context.Background(), "missing")
+
+	var statusErr *client.StatusError
+	if errors.As(err, &statusErr) {
+		fmt.Println(statusErr.StatusCode, statusErr.Message)
+	}
+	// Output: 404 migration not found
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Log levels of agents.
+const (
+	LogDebug = "debug"
+	LogInfo  = "info"
+	LogWarn  = "warn"
+	LogError = "error"
+)
+
+// ErrLogStreamClosed is returned by StreamAgentLogs when the controller ends
+// a followed log stream.
+var ErrLogStreamClosed = errors.New("log stream closed by the controller")
+
+// maxLogLine is the longest log entry accepted from a log stream.
+const maxLogLine = 1 << 20
+
+// AgentLogLevel is the runtime log level of an agent.
+type AgentLogLevel struct {
+	Level string `json:"Level"`
+	// Default is the level the agent starts with and reverts to.
+	Default string `json:"Default"`
+	// RevertAt is when a temporary level reverts to the default.
+	RevertAt *time.Time `json:"RevertAt,omitempty"`
+}
+
+// SetLogLevelRequest changes the log level of an agent.
+type SetLogLevelRequest struct {
+	Level string `json:"Level"`
+	// Duration makes the change temporary: the controller reverts the level
+	// to the default after it. Without it the level becomes the default.
+	Duration Duration `json:"Duration,omitempty"`
+}
+
+// GetAgentLogLevel returns the runtime log level of an agent.
+func (c *Client) GetAgentLogLevel(ctx context.Context, id string) (*AgentLogLevel, error) {
+	var level AgentLogLevel
+	if err := c.getJSON(ctx, "/agents/"+url.PathEscape(id)+"/log-level", nil, &level); err != nil {
+		return nil, fmt.Errorf("failed to get log level of agent %q: %w", id, err)
+	}
+	return &level, nil
+}
+
+// SetAgentLogLevel changes the runtime log level of an agent through the
+// controller, which forwards it to the agent.
+func (c *Client) SetAgentLogLevel(ctx context.Context, id string, req SetLogLevelRequest) (*AgentLogLevel, error) {
+	var level AgentLogLevel
+	if err := c.sendJSON(ctx, http.MethodPut, "/agents/"+url.PathEscape(id)+"/log-level", nil, req, &level); err != nil {
+		return nil, fmt.Errorf("failed to set log level of agent %q: %w", id, err)
+	}
+	return &level, nil
+}
+
+// LogEntry is a line logged by an agent.
+type LogEntry struct {
+	Time    time.Time         `json:"Time"`
+	Level   string            `json:"Level"`
+	Message string            `json:"Message"`
+	Fields  map[string]string `json:"Fields,omitempty"`
+}
+
+// AgentLogOptions selects the log entries streamed by StreamAgentLogs.
+type AgentLogOptions struct {
+	// Since only returns entries logged at or after the time.
+	Since time.Time
+	// Follow keeps the stream open and returns new entries as they are logged.
+	Follow bool
+}
+
+// StreamAgentLogs streams the logs of an agent through the controller and
+// calls fn for every entry, oldest first. It returns when the stream ends,
+// fn returns an error or ctx is done. Without opts.Follow the stream ends
+// after the entries logged so far; a followed stream only ends when the
+// controller closes it, which returns ErrLogStreamClosed.
+func (c *Client) StreamAgentLogs(ctx context.Context, id string, opts AgentLogOptions, fn func(LogEntry) error) error {
+	query := url.Values{}
+	if !opts.Since.IsZero() {
+		query.Set("since", opts.Since.Format(time.RFC3339Nano))
+	}
+	if opts.Follow {
+		query.Set("follow", "true")
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, "/agents/"+url.PathEscape(id)+"/logs?"+query.Encode())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/x-ndjson")
+
+	resp, err := c.doWith(c.streamClient, req)
+	if err != nil {
+		return fmt.Errorf("failed to stream logs of agent %q: %w", id, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to stream logs of agent %q: %w", id, NewStatusError(resp.StatusCode, readErrorMessage(resp)))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogLine)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry LogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("failed to parse log entry of agent %q: %w", id, err)
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read logs of agent %q: %w", id, err)
+	}
+	if opts.Follow {
+		return ErrLogStreamClosed
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+)
+
+func TestClient_SetAgentLogLevel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/agents/agent-1/log-level", r.URL.Path)
+
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]any{"Level": "debug", "Duration": "15m0s"}, body)
+
+		_, _ = w.Write([]byte(`{"Level":"debug","Default":"info","RevertAt":"2026-10-18T10:15:00Z"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, 30*time.Second, "")
+	level, err := client.SetAgentLogLevel(context.Background(), "agent-1", SetLogLevelRequest{
+		Level:    LogDebug,
+		Duration: Duration(15 * time.Minute),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, LogInfo, level.Default)
+	require.NotNil(t, level.RevertAt)
+	assert.Equal(t, time.Date(2026, 10, 18, 10, 15, 0, 0, time.UTC), *level.RevertAt)
+}
+
+func TestClient_StreamAgentLogs(t *testing.T) {
+	since := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/agents/agent-1/logs" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": "agent not found"}`))
+			return
+		}
+		assert.Equal(t, since.Format(time.RFC3339Nano), r.URL.Query().Get("since"))
+		_, _ = w.Write([]byte(`{"Time":"2026-10-18T10:00:01Z","Level":"info","Message":"agent started"}` + "\n\n" +
+			`{"Time":"2026-10-18T10:00:02Z","Level":"warn","Message":"disk slow","Fields":{"disk":"sda"}}` + "\n"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, 30*time.Second, "")
+
+	t.Run("should pass every entry", func(t *testing.T) {
+		var entries []LogEntry
+		err := client.StreamAgentLogs(context.Background(), "agent-1", AgentLogOptions{Since: since}, func(entry LogEntry) error {
+			entries = append(entries, entry)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, "agent started", entries[0].Message)
+		assert.Equal(t, map[string]string{"disk": "sda"}, entries[1].Fields)
+	})
+
+	t.Run("should report the end of a followed stream", func(t *testing.T) {
+		err := client.StreamAgentLogs(context.Background(), "agent-1", AgentLogOptions{Since: since, Follow: true}, func(LogEntry) error {
+			return nil
+		})
+		assert.ErrorIs(t, err, ErrLogStreamClosed)
+	})
+
+	t.Run("should stop when the callback fails", func(t *testing.T) {
+		calls := 0
+		err := client.StreamAgentLogs(context.Background(), "agent-1", AgentLogOptions{Since: since}, func(LogEntry) error {
+			calls++
+			return context.Canceled
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("should return status error for unknown agent", func(t *testing.T) {
+		err := client.StreamAgentLogs(context.Background(), "agent-9", AgentLogOptions{}, func(LogEntry) error { return nil })
+		assert.Equal(t, errdefs.KindNotFound, errdefs.KindOf(err))
+	})
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Migration phases reported by the controller.
+const (
+	MigrationPending   = "pending"
+	MigrationRunning   = "running"
+	MigrationCompleted = "completed"
+	MigrationFailed    = "failed"
+	MigrationCancelled = "cancelled"
+)
+
+// Migration represents a virtual machine migration managed by the controller.
+type Migration struct {
+	ID          string            `json:"ID"`
+	VMName      string            `json:"VMName"`
+	SourceAgent string            `json:"SourceAgent"`
+	TargetAgent string            `json:"TargetAgent"`
+	Phase       string            `json:"Phase"`
+	Progress    int               `json:"Progress"`
+	Labels      map[string]string `json:"Labels,omitempty"`
+	Message     string            `json:"Message,omitempty"`
+	CreatedAt   time.Time         `json:"CreatedAt"`
+	CompletedAt *time.Time        `json:"CompletedAt,omitempty"`
+}
+
+// ListMigrationsOptions filters the migrations returned by ListMigrations.
+type ListMigrationsOptions struct {
+	// Phase only returns migrations in the given phase.
+	Phase string
+	// Agent only returns migrations from or to the given agent.
+	Agent string
+	// Selector only returns migrations whose labels match the label selector.
+	Selector string
+}
+
+// ListMigrations returns the migrations known to the controller.
+func (c *Client) ListMigrations(ctx context.Context, opts ListMigrationsOptions) ([]Migration, error) {
+	query := url.Values{}
+	if opts.Phase != "" {
+		query.Set("phase", opts.Phase)
+	}
+	if opts.Agent != "" {
+		query.Set("agent", opts.Agent)
+	}
+	if opts.Selector != "" {
+		// Invalid selectors are reported before anything is sent.
+		if _, err := ParseSelector(opts.Selector); err != nil {
+			return nil, err
+		}
+		query.Set("selector", opts.Selector)
+	}
+
+	var migrations []Migration
+	if err := c.getJSON(ctx, "/migrations", query, &migrations); err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+	return migrations, nil
+}
+
+// GetMigration returns the migration with the given ID.
+func (c *Client) GetMigration(ctx context.Context, id string) (*Migration, error) {
+	var migration Migration
+	if err := c.getJSON(ctx, "/migrations/"+url.PathEscape(id), nil, &migration); err != nil {
+		return nil, fmt.Errorf("failed to get migration %q: %w", id, err)
+	}
+	return &migration, nil
+}
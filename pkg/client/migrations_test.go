@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Migrations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/migrations":
+			assert.Equal(t, "agent=agent-1&phase=running", r.URL.RawQuery)
+			_, _ = w.Write([]byte(`[{"ID": "mig-1", "VMName": "web", "Phase": "running", "Progress": 40}]`))
+		case "/migrations/mig-1":
+			_, _ = w.Write([]byte(`{"ID": "mig-1", "VMName": "web", "Phase": "completed", "Progress": 100}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, 30*time.Second, "")
+
+	t.Run("should list migrations with filters", func(t *testing.T) {
+		migrations, err := client.ListMigrations(context.Background(), ListMigrationsOptions{
+			Phase: MigrationRunning,
+			Agent: "agent-1",
+		})
+		require.NoError(t, err)
+		require.Len(t, migrations, 1)
+		assert.Equal(t, 40, migrations[0].Progress)
+	})
+
+	t.Run("should get migration", func(t *testing.T) {
+		migration, err := client.GetMigration(context.Background(), "mig-1")
+		require.NoError(t, err)
+		assert.Equal(t, MigrationCompleted, migration.Phase)
+	})
+
+	t.Run("should return status error for unknown migration", func(t *testing.T) {
+		_, err := client.GetMigration(context.Background(), "missing")
+		var statusErr *StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+	})
+}
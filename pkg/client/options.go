@@ -0,0 +1,114 @@
+package client
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// defaultRetryBackoff is the delay before the first retry; later retries wait longer.
+const defaultRetryBackoff = 200 * time.Millisecond
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithTimeout sets the timeout of a single HTTP request. Zero keeps DefaultTimeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		if timeout > 0 {
+			c.timeout = timeout
+		}
+	}
+}
+
+// WithToken sets the bearer token sent in the Authorization header.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used for https controllers.
+// It is ignored when a custom transport is set with WithTransport.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(c *Client) {
+		c.tlsConfig = cfg
+	}
+}
+
+// WithRetries retries idempotent requests up to n times on network errors and
+// 502 or 504 responses. The delay grows linearly from backoff; zero keeps the default.
+func WithRetries(n int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = max(n, 0)
+		if backoff > 0 {
+			c.retryBackoff = backoff
+		}
+	}
+}
+
+// WithTransport sets the round tripper used to send requests.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.transport = rt
+	}
+}
+
+// WithEndpoints adds controller endpoints to fail over to. The base URL is
+// always the first endpoint.
+func WithEndpoints(endpoints ...string) Option {
+	return func(c *Client) {
+		c.endpoints = append(c.endpoints, endpoints...)
+	}
+}
+
+// WithStrategy sets the order in which endpoints are tried.
+func WithStrategy(strategy Strategy) Option {
+	return func(c *Client) {
+		c.strategy = strategy
+	}
+}
+
+// WithCooldown sets how long a failed endpoint is tried only after the
+// healthy ones. Zero keeps DefaultCooldown.
+func WithCooldown(cooldown time.Duration) Option {
+	return func(c *Client) {
+		if cooldown > 0 {
+			c.cooldown = cooldown
+		}
+	}
+}
+
+// WithVersionHandler calls fn with the controller version reported in the
+// ControllerVersionHeader of every response that carries it.
+func WithVersionHandler(fn func(version string)) Option {
+	return func(c *Client) {
+		c.onVersion = fn
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		if userAgent != "" {
+			c.userAgent = userAgent
+		}
+	}
+}
+
+// WithRequestID sets the ID sent in the RequestIDHeader of every request.
+// A random ID is generated for each client by default.
+func WithRequestID(id string) Option {
+	return func(c *Client) {
+		c.requestID = id
+	}
+}
+
+// WithTraceID sets the W3C trace ID of the traceparent header sent with every
+// request; each request is a new span of the trace. A random trace ID is
+// generated for each client by default. New rejects invalid trace IDs.
+func WithTraceID(traceID string) Option {
+	return func(c *Client) {
+		c.traceID = traceID
+	}
+}
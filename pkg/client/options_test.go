@@ -0,0 +1,191 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name        string
+		baseURL     string
+		expectedURL string
+		expectError bool
+	}{
+		{
+			name:        "should accept http url",
+			baseURL:     "http://localhost:9000",
+			expectedURL: "http://localhost:9000",
+		},
+		{
+			name:        "should trim trailing slash",
+			baseURL:     "https://controller.example.com/",
+			expectedURL: "https://controller.example.com",
+		},
+		{
+			name:        "should reject unsupported scheme",
+			baseURL:     "ftp://localhost:9000",
+			expectError: true,
+		},
+		{
+			name:        "should reject url without host",
+			baseURL:     "http://",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(tt.baseURL)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedURL, c.GetBaseURL())
+			assert.Equal(t, DefaultTimeout, c.GetTimeout())
+		})
+	}
+}
+
+func TestOptions(t *testing.T) {
+	t.Run("should apply timeout and token", func(t *testing.T) {
+		c, err := New("http://localhost:9000", WithTimeout(5*time.Second), WithToken("secret"))
+		require.NoError(t, err)
+		assert.Equal(t, 5*time.Second, c.GetTimeout())
+		assert.Equal(t, 5*time.Second, c.httpClient.Timeout)
+		assert.Equal(t, "secret", c.token)
+	})
+
+	t.Run("should apply tls config to default transport", func(t *testing.T) {
+		cfg := &tls.Config{ServerName: "controller.example.com", MinVersion: tls.VersionTLS12}
+		c, err := New("https://localhost:9000", WithTLSConfig(cfg))
+		require.NoError(t, err)
+		transport, ok := c.httpClient.Transport.(*http.Transport)
+		require.True(t, ok)
+		assert.Same(t, cfg, transport.TLSClientConfig)
+	})
+
+	t.Run("should use custom transport", func(t *testing.T) {
+		var calls int32
+		rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&calls, 1)
+			return http.DefaultTransport.RoundTrip(req)
+		})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		c, err := New(server.URL, WithTransport(rt))
+		require.NoError(t, err)
+		_, err = c.Ping(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+}
+
+func TestWithRetries(t *testing.T) {
+	tests := []struct {
+		name             string
+		statuses         []int
+		retries          int
+		expectedStatus   int
+		expectedRequests int32
+	}{
+		{
+			name:             "should retry bad gateway until success",
+			statuses:         []int{http.StatusBadGateway, http.StatusGatewayTimeout, http.StatusOK},
+			retries:          3,
+			expectedStatus:   http.StatusOK,
+			expectedRequests: 3,
+		},
+		{
+			name:             "should stop after configured retries",
+			statuses:         []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway},
+			retries:          1,
+			expectedStatus:   http.StatusBadGateway,
+			expectedRequests: 2,
+		},
+		{
+			name:             "should not retry service unavailable",
+			statuses:         []int{http.StatusServiceUnavailable, http.StatusOK},
+			retries:          3,
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedRequests: 1,
+		},
+		{
+			name:             "should not retry without option",
+			statuses:         []int{http.StatusBadGateway, http.StatusOK},
+			expectedStatus:   http.StatusBadGateway,
+			expectedRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				n := atomic.AddInt32(&requests, 1)
+				w.WriteHeader(tt.statuses[min(int(n), len(tt.statuses))-1])
+			}))
+			defer server.Close()
+
+			c, err := New(server.URL, WithRetries(tt.retries, time.Millisecond))
+			require.NoError(t, err)
+
+			response, err := c.Ping(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, response.StatusCode)
+			assert.Equal(t, tt.expectedRequests, atomic.LoadInt32(&requests))
+		})
+	}
+}
+
+func TestShouldRetry(t *testing.T) {
+	post, err := http.NewRequest(http.MethodPost, "http://localhost", nil)
+	require.NoError(t, err)
+	assert.False(t, shouldRetry(post, &http.Response{StatusCode: http.StatusBadGateway}, nil))
+
+	get, err := http.NewRequest(http.MethodGet, "http://localhost", nil)
+	require.NoError(t, err)
+	assert.True(t, shouldRetry(get, nil, assert.AnError))
+	assert.False(t, shouldRetry(get, &http.Response{StatusCode: http.StatusNotFound}, nil))
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestWithVersionHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ping" {
+			w.Header().Set(ControllerVersionHeader, "v1.2.0")
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var versions []string
+	c, err := New(server.URL, WithVersionHandler(func(version string) {
+		versions = append(versions, version)
+	}))
+	require.NoError(t, err)
+
+	_, err = c.Ping(context.Background())
+	require.NoError(t, err)
+	_, err = c.GetStatus(context.Background())
+	require.Error(t, err)
+
+	assert.Equal(t, []string{"v1.2.0"}, versions)
+}
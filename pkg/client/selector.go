@@ -0,0 +1,339 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Selector operators.
+const (
+	selectorEquals       = "="
+	selectorNotEquals    = "!="
+	selectorIn           = "in"
+	selectorNotIn        = "notin"
+	selectorExists       = "exists"
+	selectorDoesNotExist = "!"
+)
+
+// maxLabelLength is the maximum length of a label value and of the name part
+// of a label key.
+const maxLabelLength = 63
+
+// maxLabelPrefixLength is the maximum length of the DNS prefix of a label key.
+const maxLabelPrefixLength = 253
+
+var (
+	labelNamePattern   = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+	labelPrefixPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+// ValidateLabelKey checks that a label or annotation key is a name of up to
+// 63 alphanumeric characters, '-', '_' and '.', optionally preceded by a DNS
+// subdomain prefix and '/', such as "example.com/tier".
+func ValidateLabelKey(key string) error {
+	name := key
+	if prefix, rest, ok := strings.Cut(key, "/"); ok {
+		if prefix == "" || len(prefix) > maxLabelPrefixLength || !labelPrefixPattern.MatchString(prefix) {
+			return fmt.Errorf("invalid key %q: prefix must be a lowercase DNS subdomain", key)
+		}
+		name = rest
+	}
+	switch {
+	case name == "":
+		return fmt.Errorf("invalid key %q: name must not be empty", key)
+	case len(name) > maxLabelLength:
+		return fmt.Errorf("invalid key %q: name must be at most %d characters", key, maxLabelLength)
+	case !labelNamePattern.MatchString(name):
+		return fmt.Errorf("invalid key %q: name must consist of alphanumeric characters, '-', '_' or '.' and start and end with an alphanumeric character", key)
+	}
+	return nil
+}
+
+// ValidateLabelValue checks that a label value is empty or up to 63
+// alphanumeric characters, '-', '_' and '.'.
+func ValidateLabelValue(value string) error {
+	switch {
+	case value == "":
+		return nil
+	case len(value) > maxLabelLength:
+		return fmt.Errorf("invalid value %q: must be at most %d characters", value, maxLabelLength)
+	case !labelNamePattern.MatchString(value):
+		return fmt.Errorf("invalid value %q: must consist of alphanumeric characters, '-', '_' or '.' and start and end with an alphanumeric character", value)
+	}
+	return nil
+}
+
+// Selector matches labels. The zero Selector matches everything.
+type Selector struct {
+	requirements []requirement
+}
+
+// requirement is a single term of a selector.
+type requirement struct {
+	key      string
+	operator string
+	values   []string
+}
+
+// ParseSelector parses a comma separated list of requirements, all of which
+// must match:
+//
+//	key=value, key==value  the label has the value
+//	key!=value             the label is missing or has another value
+//	key in (a,b)           the label has one of the values
+//	key notin (a,b)        the label is missing or has none of the values
+//	key                    the label exists
+//	!key                   the label is missing
+func ParseSelector(s string) (Selector, error) {
+	p := &selectorParser{tokens: lexSelector(s)}
+	selector, err := p.parse()
+	if err != nil {
+		return Selector{}, fmt.Errorf("invalid selector %q: %w", s, err)
+	}
+	return selector, nil
+}
+
+// Empty reports whether the selector matches everything.
+func (s Selector) Empty() bool {
+	return len(s.requirements) == 0
+}
+
+// Matches reports whether labels satisfy every requirement of the selector.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s.requirements {
+		if !r.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the selector in its canonical form.
+func (s Selector) String() string {
+	terms := make([]string, 0, len(s.requirements))
+	for _, r := range s.requirements {
+		terms = append(terms, r.String())
+	}
+	return strings.Join(terms, ",")
+}
+
+func (r requirement) matches(labels map[string]string) bool {
+	value, ok := labels[r.key]
+	switch r.operator {
+	case selectorEquals:
+		return ok && value == r.values[0]
+	case selectorNotEquals:
+		return !ok || value != r.values[0]
+	case selectorIn:
+		return ok && slices.Contains(r.values, value)
+	case selectorNotIn:
+		return !ok || !slices.Contains(r.values, value)
+	case selectorExists:
+		return ok
+	default:
+		return !ok
+	}
+}
+
+func (r requirement) String() string {
+	switch r.operator {
+	case selectorEquals, selectorNotEquals:
+		return r.key + r.operator + r.values[0]
+	case selectorIn, selectorNotIn:
+		return r.key + " " + r.operator + " (" + strings.Join(r.values, ",") + ")"
+	case selectorExists:
+		return r.key
+	default:
+		return "!" + r.key
+	}
+}
+
+// selectorToken is a token of a selector. Words are keys, values and the
+// in and notin operators; everything else is punctuation.
+type selectorToken struct {
+	text string
+	word bool
+	pos  int
+}
+
+// lexSelector splits a selector into tokens, dropping white space.
+func lexSelector(s string) []selectorToken {
+	var tokens []selectorToken
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == ' ' || s[i] == '\t':
+			i++
+		case strings.HasPrefix(s[i:], "!=") || strings.HasPrefix(s[i:], "=="):
+			tokens = append(tokens, selectorToken{text: s[i : i+2], pos: i})
+			i += 2
+		case strings.IndexByte("!=(),", s[i]) >= 0:
+			tokens = append(tokens, selectorToken{text: s[i : i+1], pos: i})
+			i++
+		default:
+			end := i + 1
+			for end < len(s) && strings.IndexByte(" \t!=(),", s[end]) < 0 {
+				end++
+			}
+			tokens = append(tokens, selectorToken{text: s[i:end], word: true, pos: i})
+			i = end
+		}
+	}
+	return tokens
+}
+
+// selectorParser parses the tokens of a selector.
+type selectorParser struct {
+	tokens []selectorToken
+	next   int
+}
+
+func (p *selectorParser) parse() (Selector, error) {
+	var selector Selector
+	if len(p.tokens) == 0 {
+		return selector, nil
+	}
+	for {
+		r, err := p.parseRequirement()
+		if err != nil {
+			return Selector{}, err
+		}
+		selector.requirements = append(selector.requirements, r)
+
+		token, ok := p.peek()
+		if !ok {
+			return selector, nil
+		}
+		if token.text != "," {
+			return Selector{}, p.unexpected(token, `"," between requirements`)
+		}
+		p.next++
+	}
+}
+
+func (p *selectorParser) parseRequirement() (requirement, error) {
+	if token, ok := p.peek(); ok && token.text == "!" {
+		p.next++
+		key, err := p.parseKey()
+		if err != nil {
+			return requirement{}, err
+		}
+		return requirement{key: key, operator: selectorDoesNotExist}, nil
+	}
+
+	key, err := p.parseKey()
+	if err != nil {
+		return requirement{}, err
+	}
+
+	token, ok := p.peek()
+	if !ok || token.text == "," {
+		return requirement{key: key, operator: selectorExists}, nil
+	}
+	p.next++
+	switch token.text {
+	case "=", "==", "!=":
+		operator := selectorEquals
+		if token.text == "!=" {
+			operator = selectorNotEquals
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return requirement{}, err
+		}
+		return requirement{key: key, operator: operator, values: []string{value}}, nil
+	case selectorIn, selectorNotIn:
+		values, err := p.parseValues(token.text)
+		if err != nil {
+			return requirement{}, err
+		}
+		return requirement{key: key, operator: token.text, values: values}, nil
+	default:
+		return requirement{}, p.unexpected(token, fmt.Sprintf("an operator after %q", key))
+	}
+}
+
+func (p *selectorParser) parseKey() (string, error) {
+	token, ok := p.take()
+	switch {
+	case !ok:
+		return "", errors.New("expected a key at the end")
+	case !token.word:
+		return "", p.unexpected(token, "a key")
+	}
+	if err := ValidateLabelKey(token.text); err != nil {
+		return "", err
+	}
+	return token.text, nil
+}
+
+// parseValue parses the value after = or !=, which may be empty.
+func (p *selectorParser) parseValue() (string, error) {
+	token, ok := p.peek()
+	if !ok || !token.word {
+		return "", nil
+	}
+	p.next++
+	if err := ValidateLabelValue(token.text); err != nil {
+		return "", err
+	}
+	return token.text, nil
+}
+
+// parseValues parses the parenthesized values after in or notin.
+func (p *selectorParser) parseValues(operator string) ([]string, error) {
+	token, ok := p.take()
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("expected \"(\" after %q at the end", operator)
+	case token.text != "(":
+		return nil, p.unexpected(token, fmt.Sprintf("\"(\" after %q", operator))
+	}
+
+	var values []string
+	for {
+		token, ok := p.take()
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("missing \")\" after the values of %q", operator)
+		case token.word:
+			if err := ValidateLabelValue(token.text); err != nil {
+				return nil, err
+			}
+			values = append(values, token.text)
+		default:
+			return nil, p.unexpected(token, "a value")
+		}
+
+		token, ok = p.take()
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("missing \")\" after the values of %q", operator)
+		case token.text == ")":
+			return values, nil
+		case token.text != ",":
+			return nil, p.unexpected(token, `"," or ")"`)
+		}
+	}
+}
+
+func (p *selectorParser) peek() (selectorToken, bool) {
+	if p.next >= len(p.tokens) {
+		return selectorToken{}, false
+	}
+	return p.tokens[p.next], true
+}
+
+func (p *selectorParser) take() (selectorToken, bool) {
+	token, ok := p.peek()
+	if ok {
+		p.next++
+	}
+	return token, ok
+}
+
+func (p *selectorParser) unexpected(token selectorToken, expected string) error {
+	return fmt.Errorf("expected %s at position %d, found %q", expected, token.pos+1, token.text)
+}
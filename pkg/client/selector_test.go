@@ -0,0 +1,93 @@
+package client
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSelector(t *testing.T) {
+	labels := map[string]string{"site": "dc1", "tier": "gold", "example.com/rack": "r7"}
+
+	tests := []struct {
+		name            string
+		selector        string
+		expectedString  string
+		expectedMatches bool
+	}{
+		{name: "should match everything when empty", selector: " ", expectedString: "", expectedMatches: true},
+		{name: "should parse equality", selector: "site=dc1, tier==gold", expectedString: "site=dc1,tier=gold", expectedMatches: true},
+		{name: "should parse inequality", selector: "site!=dc1", expectedString: "site!=dc1", expectedMatches: false},
+		{name: "should match inequality of missing labels", selector: "zone!=a", expectedString: "zone!=a", expectedMatches: true},
+		{name: "should parse set membership", selector: "site in (dc1, dc2)", expectedString: "site in (dc1,dc2)", expectedMatches: true},
+		{name: "should parse set exclusion", selector: "tier notin (gold,silver)", expectedString: "tier notin (gold,silver)", expectedMatches: false},
+		{name: "should match set exclusion of missing labels", selector: "zone notin (a)", expectedString: "zone notin (a)", expectedMatches: true},
+		{name: "should parse existence", selector: "example.com/rack,!maintenance", expectedString: "example.com/rack,!maintenance", expectedMatches: true},
+		{name: "should parse empty values", selector: "tier=", expectedString: "tier=", expectedMatches: false},
+		{name: "should parse keys named like operators", selector: "in=x,notin", expectedString: "in=x,notin", expectedMatches: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := ParseSelector(tt.selector)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedString, selector.String())
+			assert.Equal(t, tt.expectedString == "", selector.Empty())
+			assert.Equal(t, tt.expectedMatches, selector.Matches(labels))
+		})
+	}
+}
+
+func TestParseSelector_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		selector      string
+		expectedError string
+	}{
+		{name: "should reject missing operators", selector: "site dc1", expectedError: `expected an operator after "site" at position 6, found "dc1"`},
+		{name: "should reject trailing commas", selector: "site=dc1,", expectedError: "expected a key at the end"},
+		{name: "should reject empty requirements", selector: "site=dc1,,tier", expectedError: `expected a key at position 10, found ","`},
+		{name: "should reject sets without parentheses", selector: "site in dc1", expectedError: `expected "(" after "in" at position 9, found "dc1"`},
+		{name: "should reject unclosed sets", selector: "site in (dc1,dc2", expectedError: `missing ")" after the values of "in"`},
+		{name: "should reject empty sets", selector: "site notin ()", expectedError: `expected a value at position 13, found ")"`},
+		{name: "should reject invalid keys", selector: "-site=dc1", expectedError: `invalid key "-site": name must consist of`},
+		{name: "should reject invalid values", selector: "site=dc_", expectedError: `invalid value "dc_"`},
+		{name: "should reject values after existence", selector: "!site=dc1", expectedError: `expected "," between requirements at position 6, found "="`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSelector(tt.selector)
+			require.Error(t, err)
+			assert.True(t, strings.HasPrefix(err.Error(), "invalid selector "), err.Error())
+			assert.Contains(t, err.Error(), tt.expectedError)
+		})
+	}
+}
+
+func TestValidateLabelKey(t *testing.T) {
+	for _, key := range []string{"site", "a", "example.com/tier", "Node_Pool.v2"} {
+		assert.NoError(t, ValidateLabelKey(key), key)
+	}
+
+	tests := map[string]string{
+		"":                             "name must not be empty",
+		"example.com/":                 "name must not be empty",
+		"/site":                        "prefix must be a lowercase DNS subdomain",
+		"Example.com/site":             "prefix must be a lowercase DNS subdomain",
+		"site.":                        "start and end with an alphanumeric character",
+		"si te":                        "name must consist of",
+		strings.Repeat("x", 64):        "at most 63 characters",
+		"a/" + strings.Repeat("x", 63): "",
+	}
+	for key, expectedError := range tests {
+		err := ValidateLabelKey(key)
+		if expectedError == "" {
+			assert.NoError(t, err, key)
+			continue
+		}
+		assert.ErrorContains(t, err, expectedError, key)
+	}
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// PingStats accumulates the results of repeated ping probes.
+type PingStats struct {
+	Sent      int
+	Received  int
+	latencies []time.Duration
+}
+
+// PingSummary represents the summary of a series of ping probes.
+type PingSummary struct {
+	Sent     int      `json:"sent"`
+	Received int      `json:"received"`
+	Loss     float64  `json:"loss_percent"`
+	Min      Duration `json:"min"`
+	Avg      Duration `json:"avg"`
+	Max      Duration `json:"max"`
+	P50      Duration `json:"p50"`
+	P99      Duration `json:"p99"`
+	StdDev   Duration `json:"stddev"`
+}
+
+// AddSuccess records a successful probe with the given round-trip latency.
+func (s *PingStats) AddSuccess(latency time.Duration) {
+	s.Sent++
+	s.Received++
+	s.latencies = append(s.latencies, latency)
+}
+
+// AddFailure records a probe that did not receive a successful response.
+func (s *PingStats) AddFailure() {
+	s.Sent++
+}
+
+// Summary computes loss and latency statistics over all recorded probes.
+func (s *PingStats) Summary() PingSummary {
+	summary := PingSummary{
+		Sent:     s.Sent,
+		Received: s.Received,
+	}
+
+	if s.Sent > 0 {
+		summary.Loss = float64(s.Sent-s.Received) / float64(s.Sent) * 100
+	}
+
+	if len(s.latencies) == 0 {
+		return summary
+	}
+
+	sorted := slices.Clone(s.latencies)
+	slices.Sort(sorted)
+
+	var total time.Duration
+	for _, latency := range sorted {
+		total += latency
+	}
+	mean := float64(total) / float64(len(sorted))
+
+	var variance float64
+	for _, latency := range sorted {
+		diff := float64(latency) - mean
+		variance += diff * diff
+	}
+	variance /= float64(len(sorted))
+
+	summary.Min = Duration(sorted[0])
+	summary.Max = Duration(sorted[len(sorted)-1])
+	summary.Avg = Duration(mean)
+	summary.P50 = Duration(percentile(sorted, 50))
+	summary.P99 = Duration(percentile(sorted, 99))
+	summary.StdDev = Duration(math.Sqrt(variance))
+
+	return summary
+}
+
+// percentile returns the nearest-rank percentile of an ascending slice.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
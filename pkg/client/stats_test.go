@@ -0,0 +1,75 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPingStats_Summary(t *testing.T) {
+	tests := []struct {
+		name      string
+		latencies []time.Duration
+		failures  int
+		expected  PingSummary
+	}{
+		{
+			name:     "should report no latency when nothing was sent",
+			expected: PingSummary{},
+		},
+		{
+			name:     "should report full loss when every probe failed",
+			failures: 3,
+			expected: PingSummary{Sent: 3, Received: 0, Loss: 100},
+		},
+		{
+			name: "should compute latency statistics",
+			latencies: []time.Duration{
+				40 * time.Millisecond,
+				10 * time.Millisecond,
+				30 * time.Millisecond,
+				20 * time.Millisecond,
+			},
+			failures: 1,
+			expected: PingSummary{
+				Sent:     5,
+				Received: 4,
+				Loss:     20,
+				Min:      Duration(10 * time.Millisecond),
+				Avg:      Duration(25 * time.Millisecond),
+				Max:      Duration(40 * time.Millisecond),
+				P50:      Duration(20 * time.Millisecond),
+				P99:      Duration(40 * time.Millisecond),
+				StdDev:   Duration(11180339),
+			},
+		},
+		{
+			name:      "should handle a single probe",
+			latencies: []time.Duration{15 * time.Millisecond},
+			expected: PingSummary{
+				Sent:     1,
+				Received: 1,
+				Min:      Duration(15 * time.Millisecond),
+				Avg:      Duration(15 * time.Millisecond),
+				Max:      Duration(15 * time.Millisecond),
+				P50:      Duration(15 * time.Millisecond),
+				P99:      Duration(15 * time.Millisecond),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stats PingStats
+			for _, latency := range tt.latencies {
+				stats.AddSuccess(latency)
+			}
+			for range tt.failures {
+				stats.AddFailure()
+			}
+
+			assert.Equal(t, tt.expected, stats.Summary())
+		})
+	}
+}
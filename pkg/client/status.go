@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Health states reported by the controller health endpoint.
+const (
+	HealthHealthy   = "healthy"
+	HealthDegraded  = "degraded"
+	HealthUnhealthy = "unhealthy"
+	HealthUnknown   = "unknown"
+)
+
+// ComponentHealth represents the health of a single controller component.
+type ComponentHealth struct {
+	Name      string    `json:"Name"`
+	State     string    `json:"State"`
+	LastCheck time.Time `json:"LastCheck"`
+	Message   string    `json:"Message"`
+}
+
+// StatusResult represents the result data in status response.
+type StatusResult struct {
+	Status     string            `json:"Status"`
+	Components []ComponentHealth `json:"Components"`
+}
+
+// StatusResponse represents the response from a status request.
+type StatusResponse struct {
+	StatusCode int           `json:"status_code"`
+	Success    bool          `json:"success"`
+	Message    string        `json:"message,omitempty"`
+	Result     *StatusResult `json:"result,omitempty"`
+}
+
+// GetStatus retrieves the health of the controller and its components.
+func (c *Client) GetStatus(ctx context.Context) (*StatusResponse, error) {
+	req, err := c.newRequest(ctx, "GET", "/health")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create status request: %w", err)
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get controller status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	response := &StatusResponse{
+		StatusCode: resp.StatusCode,
+		Success:    resp.StatusCode == http.StatusOK,
+	}
+
+	// Unhealthy controllers answer with 503 but still describe their components.
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusServiceUnavailable {
+		var result StatusResult
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return response, fmt.Errorf("failed to parse controller status response: %w", err)
+		}
+		response.Result = &result
+	} else {
+		response.Message = readErrorMessage(resp)
+	}
+
+	return response, nil
+}
+
+// Overall returns the overall health, which is the worst of the reported
+// status and the state of every component.
+func (r *StatusResult) Overall() string {
+	overall := normalizeHealth(r.Status)
+	if r.Status == "" && len(r.Components) > 0 {
+		overall = HealthHealthy
+	}
+
+	for _, component := range r.Components {
+		if state := normalizeHealth(component.State); healthRank(state) > healthRank(overall) {
+			overall = state
+		}
+	}
+
+	return overall
+}
+
+// normalizeHealth maps unrecognized health states to HealthUnknown.
+func normalizeHealth(state string) string {
+	switch state {
+	case HealthHealthy, HealthDegraded, HealthUnhealthy:
+		return state
+	default:
+		return HealthUnknown
+	}
+}
+
+// healthRank orders health states from best to worst.
+func healthRank(state string) int {
+	switch state {
+	case HealthHealthy:
+		return 0
+	case HealthDegraded:
+		return 1
+	case HealthUnknown:
+		return 2
+	default:
+		return 3
+	}
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_GetStatus(t *testing.T) {
+	tests := []struct {
+		name            string
+		statusCode      int
+		body            string
+		expectedSuccess bool
+		expectedResult  bool
+	}{
+		{
+			name:       "should parse components for 200 response",
+			statusCode: 200,
+			body: `{
+				"Status": "healthy",
+				"Components": [
+					{"Name": "database", "State": "healthy", "LastCheck": "2025-01-02T03:04:05Z", "Message": "ok"}
+				]
+			}`,
+			expectedSuccess: true,
+			expectedResult:  true,
+		},
+		{
+			name:       "should parse components for 503 response",
+			statusCode: 503,
+			body: `{
+				"Status": "unhealthy",
+				"Components": [
+					{"Name": "queue", "State": "unhealthy", "LastCheck": "2025-01-02T03:04:05Z", "Message": "connection refused"}
+				]
+			}`,
+			expectedSuccess: false,
+			expectedResult:  true,
+		},
+		{
+			name:            "should not parse body for 500 response",
+			statusCode:      500,
+			body:            "Error",
+			expectedSuccess: false,
+			expectedResult:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/health", r.URL.Path)
+				w.WriteHeader(tt.statusCode)
+				_, err := w.Write([]byte(tt.body))
+				require.NoError(t, err)
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, 30*time.Second, "")
+
+			response, err := client.GetStatus(context.Background())
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.statusCode, response.StatusCode)
+			assert.Equal(t, tt.expectedSuccess, response.Success)
+			if !tt.expectedResult {
+				assert.Nil(t, response.Result)
+				return
+			}
+
+			require.NotNil(t, response.Result)
+			require.Len(t, response.Result.Components, 1)
+			assert.Equal(t, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), response.Result.Components[0].LastCheck)
+		})
+	}
+}
+
+func TestStatusResult_Overall(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   StatusResult
+		expected string
+	}{
+		{
+			name:     "should report unknown without any information",
+			result:   StatusResult{},
+			expected: HealthUnknown,
+		},
+		{
+			name:     "should use reported status",
+			result:   StatusResult{Status: HealthHealthy},
+			expected: HealthHealthy,
+		},
+		{
+			name: "should derive status from components",
+			result: StatusResult{Components: []ComponentHealth{
+				{Name: "database", State: HealthHealthy},
+				{Name: "storage", State: HealthDegraded},
+			}},
+			expected: HealthDegraded,
+		},
+		{
+			name: "should report the worst component state",
+			result: StatusResult{Status: HealthHealthy, Components: []ComponentHealth{
+				{Name: "database", State: HealthUnhealthy},
+				{Name: "queue", State: HealthDegraded},
+			}},
+			expected: HealthUnhealthy,
+		},
+		{
+			name: "should treat unrecognized states as unknown",
+			result: StatusResult{Status: HealthHealthy, Components: []ComponentHealth{
+				{Name: "agent-registry", State: "starting"},
+			}},
+			expected: HealthUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.result.Overall())
+		})
+	}
+}
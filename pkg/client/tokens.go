@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Join token states derived by JoinToken.Status.
+const (
+	TokenActive    = "active"
+	TokenExpired   = "expired"
+	TokenExhausted = "exhausted"
+	TokenRevoked   = "revoked"
+)
+
+// JoinToken is a bootstrap token agents register with. Agents that register
+// with a token get its labels.
+type JoinToken struct {
+	ID string `json:"ID"`
+	// Token is the secret agents register with. It is only returned when the
+	// token is created.
+	Token       string            `json:"Token,omitempty"`
+	Description string            `json:"Description,omitempty"`
+	Labels      map[string]string `json:"Labels,omitempty"`
+	// MaxUses limits the number of registrations, 0 means unlimited.
+	MaxUses   int       `json:"MaxUses"`
+	Uses      int       `json:"Uses"`
+	Revoked   bool      `json:"Revoked"`
+	CreatedAt time.Time `json:"CreatedAt"`
+	ExpiresAt time.Time `json:"ExpiresAt"`
+}
+
+// Status returns whether the token can still be used at the given time.
+func (t JoinToken) Status(now time.Time) string {
+	switch {
+	case t.Revoked:
+		return TokenRevoked
+	case !t.ExpiresAt.IsZero() && !now.Before(t.ExpiresAt):
+		return TokenExpired
+	case t.MaxUses > 0 && t.Uses >= t.MaxUses:
+		return TokenExhausted
+	default:
+		return TokenActive
+	}
+}
+
+// CreateJoinTokenRequest describes a join token to create.
+type CreateJoinTokenRequest struct {
+	// TTL is how long the token is valid.
+	TTL         Duration          `json:"TTL"`
+	Description string            `json:"Description,omitempty"`
+	Labels      map[string]string `json:"Labels,omitempty"`
+	// MaxUses limits the number of registrations, 0 means unlimited.
+	MaxUses int `json:"MaxUses,omitempty"`
+}
+
+// CreateJoinToken creates a join token. The returned token includes the secret.
+func (c *Client) CreateJoinToken(ctx context.Context, req CreateJoinTokenRequest) (*JoinToken, error) {
+	var token JoinToken
+	if err := c.sendJSON(ctx, http.MethodPost, "/join-tokens", nil, req, &token); err != nil {
+		return nil, fmt.Errorf("failed to create join token: %w", err)
+	}
+	return &token, nil
+}
+
+// ListJoinTokens returns the join tokens known to the controller, without
+// their secrets.
+func (c *Client) ListJoinTokens(ctx context.Context) ([]JoinToken, error) {
+	var tokens []JoinToken
+	if err := c.getJSON(ctx, "/join-tokens", nil, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to list join tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// RevokeJoinToken revokes the join token with the given ID so that no more
+// agents can register with it.
+func (c *Client) RevokeJoinToken(ctx context.Context, id string) error {
+	if err := c.sendJSON(ctx, http.MethodDelete, "/join-tokens/"+url.PathEscape(id), nil, nil, nil); err != nil {
+		return fmt.Errorf("failed to revoke join token %q: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_CreateJoinToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/join-tokens", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]any{
+			"TTL":     "1h0m0s",
+			"Labels":  map[string]any{"site": "dc1"},
+			"MaxUses": float64(5),
+		}, body)
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"ID":"abc123","Token":"abc123.0123456789abcdef","MaxUses":5}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, 30*time.Second, "")
+	token, err := client.CreateJoinToken(context.Background(), CreateJoinTokenRequest{
+		TTL:     Duration(time.Hour),
+		Labels:  map[string]string{"site": "dc1"},
+		MaxUses: 5,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "abc123.0123456789abcdef", token.Token)
+}
+
+func TestClient_RevokeJoinToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "/join-tokens/abc123", r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, 30*time.Second, "")
+	require.NoError(t, client.RevokeJoinToken(context.Background(), "abc123"))
+}
+
+func TestJoinToken_Status(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		token    JoinToken
+		expected string
+	}{
+		{name: "should be active", token: JoinToken{ExpiresAt: now.Add(time.Hour), MaxUses: 2, Uses: 1}, expected: TokenActive},
+		{name: "should be active without limits", token: JoinToken{Uses: 10}, expected: TokenActive},
+		{name: "should expire", token: JoinToken{ExpiresAt: now}, expected: TokenExpired},
+		{name: "should be exhausted", token: JoinToken{ExpiresAt: now.Add(time.Hour), MaxUses: 2, Uses: 2}, expected: TokenExhausted},
+		{name: "should prefer revoked", token: JoinToken{ExpiresAt: now, Revoked: true}, expected: TokenRevoked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.token.Status(now))
+		})
+	}
+}
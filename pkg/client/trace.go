@@ -0,0 +1,61 @@
+package client
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Headers identifying the client and correlating requests with controller logs.
+const (
+	RequestIDHeader   = "X-Request-Id"
+	TraceParentHeader = "traceparent"
+)
+
+// defaultUserAgent is sent when no user agent is configured.
+const defaultUserAgent = "morpher-go-client/" + APIVersion
+
+// NewRequestID returns a random request ID in UUID version 4 format.
+func NewRequestID() string {
+	b := randomBytes(16)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// NewTraceID returns a random W3C trace context trace ID.
+func NewTraceID() string {
+	return hex.EncodeToString(randomBytes(16))
+}
+
+// ValidateTraceID checks that id is a W3C trace context trace ID: 32
+// lowercase hexadecimal characters, not all zero.
+func ValidateTraceID(id string) error {
+	if len(id) != 32 {
+		return fmt.Errorf("invalid trace ID %q: must be 32 hexadecimal characters", id)
+	}
+	if strings.ToLower(id) != id {
+		return fmt.Errorf("invalid trace ID %q: must be lowercase", id)
+	}
+	if _, err := hex.DecodeString(id); err != nil {
+		return fmt.Errorf("invalid trace ID %q: must be 32 hexadecimal characters", id)
+	}
+	if strings.Trim(id, "0") == "" {
+		return fmt.Errorf("invalid trace ID %q: must not be all zeros", id)
+	}
+	return nil
+}
+
+// traceParent returns a traceparent header value for a new span of the trace.
+func traceParent(traceID string) string {
+	return "00-" + traceID + "-" + hex.EncodeToString(randomBytes(8)) + "-01"
+}
+
+// randomBytes returns n cryptographically random bytes.
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	// crypto/rand.Read never returns an error.
+	_, _ = rand.Read(b)
+	return b
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_IdentificationHeaders(t *testing.T) {
+	var headers []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Clone())
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	c, err := New(server.URL,
+		WithUserAgent("morpherctl/v1.2.0 (abc123; linux/amd64)"),
+		WithRequestID("run-1"),
+		WithTraceID(traceID),
+	)
+	require.NoError(t, err)
+
+	for range 2 {
+		_, err := c.Ping(context.Background())
+		require.NoError(t, err)
+	}
+
+	require.Len(t, headers, 2)
+	traceParent := regexp.MustCompile(`^00-` + traceID + `-[0-9a-f]{16}-01$`)
+	for _, h := range headers {
+		assert.Equal(t, "morpherctl/v1.2.0 (abc123; linux/amd64)", h.Get("User-Agent"))
+		assert.Equal(t, "run-1", h.Get(RequestIDHeader))
+		assert.Regexp(t, traceParent, h.Get(TraceParentHeader))
+	}
+	assert.NotEqual(t, headers[0].Get(TraceParentHeader), headers[1].Get(TraceParentHeader))
+}
+
+func TestClient_DefaultIdentification(t *testing.T) {
+	c, err := New("http://localhost:9000")
+	require.NoError(t, err)
+
+	assert.Equal(t, defaultUserAgent, c.userAgent)
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, c.RequestID())
+	assert.NoError(t, ValidateTraceID(c.TraceID()))
+
+	_, err = New("http://localhost:9000", WithTraceID("not-a-trace"))
+	assert.Error(t, err)
+}
+
+func TestValidateTraceID(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceID     string
+		expectError bool
+	}{
+		{name: "should accept valid trace id", traceID: "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{name: "should reject short trace id", traceID: "4bf92f3577b34da6", expectError: true},
+		{name: "should reject uppercase trace id", traceID: "4BF92F3577B34DA6A3CE929D0E0E4736", expectError: true},
+		{name: "should reject non-hex trace id", traceID: "4bf92f3577b34da6a3ce929d0e0e473z", expectError: true},
+		{name: "should reject all zero trace id", traceID: "00000000000000000000000000000000", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTraceID(tt.traceID)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/pkg/semver"
+)
+
+// Wait condition kinds supported by WaitFor.
+const (
+	WaitHealthy = "healthy"
+	WaitReady   = "ready"
+	WaitVersion = "version"
+)
+
+// ErrWaitTimeout is returned by WaitFor when the condition was not met in time.
+var ErrWaitTimeout = errors.New("timed out waiting for condition")
+
+// WaitCondition describes a controller state to wait for.
+type WaitCondition struct {
+	Kind       string
+	MinVersion string
+}
+
+// ParseWaitCondition parses a condition of the form "healthy", "ready" or "version>=X".
+func ParseWaitCondition(s string) (WaitCondition, error) {
+	switch {
+	case s == WaitHealthy:
+		return WaitCondition{Kind: WaitHealthy}, nil
+	case s == WaitReady:
+		return WaitCondition{Kind: WaitReady}, nil
+	case strings.HasPrefix(s, WaitVersion+">="):
+		minVersion := strings.TrimPrefix(s, WaitVersion+">=")
+		if _, err := semver.Parse(minVersion); err != nil {
+			return WaitCondition{}, fmt.Errorf("invalid wait condition %q: %w", s, err)
+		}
+		return WaitCondition{Kind: WaitVersion, MinVersion: minVersion}, nil
+	default:
+		return WaitCondition{}, fmt.Errorf("invalid wait condition %q: must be one of healthy, ready or version>=X", s)
+	}
+}
+
+// String returns the condition in the form accepted by ParseWaitCondition.
+func (c WaitCondition) String() string {
+	if c.Kind == WaitVersion {
+		return WaitVersion + ">=" + c.MinVersion
+	}
+	return c.Kind
+}
+
+// CheckCondition evaluates the condition once and returns whether it is met
+// along with a short description of the observed state.
+func (c *Client) CheckCondition(ctx context.Context, cond WaitCondition) (bool, string, error) {
+	switch cond.Kind {
+	case WaitHealthy:
+		response, err := c.Ping(ctx)
+		if err != nil {
+			return false, "", err
+		}
+		return response.Success, fmt.Sprintf("ping status %d", response.StatusCode), nil
+	case WaitReady:
+		response, err := c.GetStatus(ctx)
+		if err != nil {
+			return false, "", err
+		}
+		if response.Result == nil {
+			return false, fmt.Sprintf("health status %d", response.StatusCode), nil
+		}
+		overall := response.Result.Overall()
+		return overall == HealthHealthy, "controller is " + overall, nil
+	case WaitVersion:
+		response, err := c.GetInfo(ctx)
+		if err != nil {
+			return false, "", err
+		}
+		if response.Result == nil {
+			return false, fmt.Sprintf("info status %d", response.StatusCode), nil
+		}
+		current := response.Result.Version
+		cmp, err := semver.Compare(current, cond.MinVersion)
+		if err != nil {
+			return false, fmt.Sprintf("controller version %q is not comparable", current), nil
+		}
+		return cmp >= 0, "controller version " + current, nil
+	default:
+		return false, "", fmt.Errorf("unsupported wait condition %q", cond.Kind)
+	}
+}
+
+// WaitFor polls the controller until the condition is met or the context ends.
+// The progress callback, if set, is called after every unsuccessful attempt.
+// ErrWaitTimeout is returned when the context deadline is exceeded.
+func (c *Client) WaitFor(ctx context.Context, cond WaitCondition, poll time.Duration, progress func(attempt int, state string, err error)) error {
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	for attempt := 1; ; attempt++ {
+		met, state, err := c.checkWithTimeout(ctx, cond)
+		if err == nil && met {
+			return nil
+		}
+		if progress != nil && ctx.Err() == nil {
+			progress(attempt, state, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("%w %s", ErrWaitTimeout, cond)
+			}
+			return fmt.Errorf("stopped waiting for %s: %w", cond, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkWithTimeout bounds a single condition check by the client timeout.
+func (c *Client) checkWithTimeout(ctx context.Context, cond WaitCondition) (bool, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	return c.CheckCondition(ctx, cond)
+}
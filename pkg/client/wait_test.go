@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWaitCondition(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    WaitCondition
+		expectError bool
+	}{
+		{name: "should parse healthy", input: "healthy", expected: WaitCondition{Kind: WaitHealthy}},
+		{name: "should parse ready", input: "ready", expected: WaitCondition{Kind: WaitReady}},
+		{name: "should parse minimum version", input: "version>=v1.4.0", expected: WaitCondition{Kind: WaitVersion, MinVersion: "v1.4.0"}},
+		{name: "should reject invalid version", input: "version>=latest", expectError: true},
+		{name: "should reject unknown condition", input: "running", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cond, err := ParseWaitCondition(tt.input)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cond)
+			assert.Equal(t, tt.input, cond.String())
+		})
+	}
+}
+
+func TestClient_WaitFor(t *testing.T) {
+	t.Run("should succeed once the controller becomes healthy", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL, time.Second, "")
+
+		var attempts []int
+		err := client.WaitFor(context.Background(), WaitCondition{Kind: WaitHealthy}, 10*time.Millisecond,
+			func(attempt int, _ string, _ error) {
+				attempts = append(attempts, attempt)
+			})
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, attempts)
+	})
+
+	t.Run("should wait for minimum version", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/info", r.URL.Path)
+			versions := []string{"v1.3.9", "v1.4.0"}
+			i := min(int(calls.Add(1))-1, len(versions)-1)
+			_, err := fmt.Fprintf(w, `{"Version": %q}`, versions[i])
+			require.NoError(t, err)
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL, time.Second, "")
+
+		cond, err := ParseWaitCondition("version>=v1.4.0")
+		require.NoError(t, err)
+		require.NoError(t, client.WaitFor(context.Background(), cond, 10*time.Millisecond, nil))
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("should wait for ready status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, err := w.Write([]byte(`{"Status": "healthy", "Components": [{"Name": "database", "State": "healthy"}]}`))
+			require.NoError(t, err)
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL, time.Second, "")
+		require.NoError(t, client.WaitFor(context.Background(), WaitCondition{Kind: WaitReady}, 10*time.Millisecond, nil))
+	})
+
+	t.Run("should time out when the condition is never met", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL, time.Second, "")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err := client.WaitFor(ctx, WaitCondition{Kind: WaitHealthy}, 10*time.Millisecond, nil)
+		require.ErrorIs(t, err, ErrWaitTimeout)
+	})
+}
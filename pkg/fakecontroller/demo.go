@@ -0,0 +1,179 @@
+package fakecontroller
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/pkg/client"
+)
+
+// demoAgents returns the agents of the demo data set.
+func demoAgents(now time.Time) []client.Agent {
+	return []client.Agent{
+		{
+			ID:            "agent-1",
+			Hostname:      "node1.example.com",
+			Address:       "10.0.0.11:9100",
+			Status:        client.AgentReady,
+			Version:       "v1.0.0",
+			Labels:        map[string]string{"zone": "a", "role": "compute"},
+			Schedulable:   true,
+			LastHeartbeat: now,
+			RegisteredAt:  now.Add(-72 * time.Hour),
+		},
+		{
+			ID:            "agent-2",
+			Hostname:      "node2.example.com",
+			Address:       "10.0.0.12:9100",
+			Status:        client.AgentReady,
+			Version:       "v1.0.0",
+			Labels:        map[string]string{"zone": "b", "role": "compute"},
+			Schedulable:   true,
+			LastHeartbeat: now,
+			RegisteredAt:  now.Add(-48 * time.Hour),
+		},
+		{
+			ID:            "agent-3",
+			Hostname:      "node3.example.com",
+			Address:       "10.0.0.13:9100",
+			Status:        client.AgentNotReady,
+			Version:       "v0.9.2",
+			Labels:        map[string]string{"zone": "b", "role": "storage"},
+			Schedulable:   false,
+			LastHeartbeat: now.Add(-10 * time.Minute),
+			RegisteredAt:  now.Add(-24 * time.Hour),
+		},
+	}
+}
+
+// demoAgentDetails returns the demo agents with their host facts,
+// capabilities and configuration.
+func demoAgentDetails(now time.Time) []client.AgentDetails {
+	agents := demoAgents(now)
+	details := make([]client.AgentDetails, 0, len(agents))
+	for i, agent := range agents {
+		d := client.AgentDetails{
+			Agent: agent,
+			Host: client.HostFacts{
+				OS: client.OSInfo{
+					Name:            "linux",
+					PlatformName:    "Ubuntu",
+					PlatformVersion: "22.04",
+					KernelVersion:   "5.15.0-105-generic",
+				},
+				Architecture: "amd64",
+				CPUModel:     "Intel(R) Xeon(R) Gold 6338 CPU @ 2.00GHz",
+				CPUCores:     32,
+				MemoryBytes:  256 << 30,
+				Disks: []client.Disk{
+					{Name: "sda", Model: "SAMSUNG MZ7L3480", SizeBytes: 480 << 30, MountPoint: "/"},
+					{Name: "nvme0n1", Model: "INTEL SSDPE2KX040T8", SizeBytes: 4 << 40, MountPoint: "/var/lib/morpher"},
+				},
+				NICs: []client.NIC{
+					{Name: "eth0", MAC: fmt.Sprintf("52:54:00:00:00:%02x", 0x11+i), SpeedMbps: 25000, Addresses: []string{strings.Split(agent.Address, ":")[0] + "/24"}},
+				},
+			},
+			Capabilities: client.AgentCapabilities{
+				SourceHypervisors:       []string{"vmware", "hyperv"},
+				TargetHypervisors:       []string{"kvm"},
+				MaxConcurrentMigrations: 4,
+			},
+			Config: client.AgentConfig{
+				LogLevel:    "info",
+				InstallPath: "/opt/morpher",
+			},
+		}
+		if agent.Labels["role"] == "storage" {
+			d.Host.CPUCores = 8
+			d.Host.MemoryBytes = 64 << 30
+			d.Capabilities.SourceHypervisors = []string{"vmware"}
+			d.Capabilities.MaxConcurrentMigrations = 1
+			d.Config.LogLevel = "debug"
+		}
+		details = append(details, d)
+	}
+	return details
+}
+
+// demoHeartbeats returns the recent heartbeats of the demo agents by agent ID.
+func demoHeartbeats(now time.Time) map[string][]client.Heartbeat {
+	heartbeats := map[string][]client.Heartbeat{}
+	for _, agent := range demoAgents(now) {
+		for i := 11; i >= 0; i-- {
+			heartbeats[agent.ID] = append(heartbeats[agent.ID], client.Heartbeat{
+				Time:    agent.LastHeartbeat.Add(-time.Duration(i) * 10 * time.Second),
+				Latency: client.Duration(time.Duration(3+i%4) * time.Millisecond),
+				Status:  agent.Status,
+			})
+		}
+	}
+	return heartbeats
+}
+
+// demoEvents returns the recent events of the demo agents by agent ID.
+func demoEvents(now time.Time) map[string][]client.Event {
+	return map[string][]client.Event{
+		"agent-1": {
+			{Type: client.EventNormal, Reason: "Registered", Message: "Agent registered with the controller", Count: 1, LastSeen: now.Add(-72 * time.Hour)},
+			{Type: client.EventNormal, Reason: "MigrationStarted", Message: "Migration mig-2 of db-01 started as target", Count: 1, LastSeen: now.Add(-5 * time.Minute)},
+		},
+		"agent-2": {
+			{Type: client.EventNormal, Reason: "Registered", Message: "Agent registered with the controller", Count: 1, LastSeen: now.Add(-48 * time.Hour)},
+		},
+		"agent-3": {
+			{Type: client.EventNormal, Reason: "Registered", Message: "Agent registered with the controller", Count: 1, LastSeen: now.Add(-24 * time.Hour)},
+			{Type: client.EventWarning, Reason: "HeartbeatMissed", Message: "No heartbeat received for 5m", Count: 7, LastSeen: now.Add(-3 * time.Minute)},
+			{Type: client.EventWarning, Reason: "NotReady", Message: "Agent marked not ready", Count: 1, LastSeen: now.Add(-5 * time.Minute)},
+		},
+	}
+}
+
+// demoMigrations returns the migrations of the demo data set.
+func demoMigrations(now time.Time) []client.Migration {
+	completed := now.Add(-30 * time.Minute)
+	return []client.Migration{
+		{
+			ID:          "mig-1",
+			VMName:      "web-01",
+			SourceAgent: "agent-1",
+			TargetAgent: "agent-2",
+			Phase:       client.MigrationCompleted,
+			Progress:    100,
+			Labels:      map[string]string{"app": "web"},
+			CreatedAt:   now.Add(-time.Hour),
+			CompletedAt: &completed,
+		},
+		{
+			ID:          "mig-2",
+			VMName:      "db-01",
+			SourceAgent: "agent-2",
+			TargetAgent: "agent-1",
+			Phase:       client.MigrationRunning,
+			Progress:    42,
+			Labels:      map[string]string{"app": "db"},
+			CreatedAt:   now.Add(-5 * time.Minute),
+		},
+	}
+}
+
+// demoLogs returns the recent log entries of the demo agents by agent ID.
+func demoLogs(now time.Time) map[string][]client.LogEntry {
+	return map[string][]client.LogEntry{
+		"agent-1": {
+			{Time: now.Add(-72 * time.Hour), Level: client.LogInfo, Message: "agent started", Fields: map[string]string{"version": "v1.0.0"}},
+			{Time: now.Add(-72 * time.Hour), Level: client.LogInfo, Message: "registered with controller"},
+			{Time: now.Add(-5 * time.Minute), Level: client.LogInfo, Message: "migration started", Fields: map[string]string{"migration": "mig-2", "vm": "db-01"}},
+			{Time: now.Add(-2 * time.Minute), Level: client.LogWarn, Message: "disk copy throttled", Fields: map[string]string{"migration": "mig-2"}},
+		},
+		"agent-2": {
+			{Time: now.Add(-48 * time.Hour), Level: client.LogInfo, Message: "agent started", Fields: map[string]string{"version": "v1.0.0"}},
+			{Time: now.Add(-5 * time.Minute), Level: client.LogInfo, Message: "migration started", Fields: map[string]string{"migration": "mig-2", "vm": "db-01"}},
+		},
+		"agent-3": {
+			{Time: now.Add(-24 * time.Hour), Level: client.LogInfo, Message: "agent started", Fields: map[string]string{"version": "v0.9.2"}},
+			{Time: now.Add(-11 * time.Minute), Level: client.LogDebug, Message: "heartbeat sent", Fields: map[string]string{"latency": "4ms"}},
+			{Time: now.Add(-10 * time.Minute), Level: client.LogError, Message: "lost connection to controller", Fields: map[string]string{"error": "connection reset by peer"}},
+		},
+	}
+}
@@ -0,0 +1,611 @@
+// Package fakecontroller provides an in-memory morpher controller for tests,
+// demos and local development.
+//
+// The fake implements the controller endpoints used by package client with
+// state held in memory, optional bearer token authentication and scriptable
+// fault injection. It is an http.Handler and can be served with
+// httptest.NewServer or any http.Server:
+//
+//	fake := fakecontroller.New(fakecontroller.WithDemoData())
+//	server := httptest.NewServer(fake)
+//	defer server.Close()
+//
+// Faults can be changed at runtime with SetFaults or through the control
+// endpoint at /_fake/faults, which accepts GET and PUT with a JSON body.
+package fakecontroller
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math/rand"
+	"net/http"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/pkg/client"
+)
+
+// ControlPrefix is the path prefix of the control endpoints. Control
+// endpoints are not subject to authentication or fault injection.
+const ControlPrefix = "/_fake/"
+
+// Server is an in-memory fake controller. It is safe for concurrent use.
+type Server struct {
+	mu         sync.Mutex
+	mux        *http.ServeMux
+	token      string
+	info       client.InfoResult
+	health     client.StatusResult
+	agents     map[string]client.Agent
+	migrations map[string]client.Migration
+	details    map[string]client.AgentDetails
+	heartbeats map[string][]client.Heartbeat
+	events     map[string][]client.Event
+	tokens     map[string]client.JoinToken
+	logs       map[string][]client.LogEntry
+	logLevels  map[string]*logLevel
+	// logAppended and logClosed are closed and replaced to wake up log
+	// streams on new entries and to end them.
+	logAppended chan struct{}
+	logClosed   chan struct{}
+	upgrades    upgradeSettings
+	faults      Faults
+	rand        *rand.Rand
+	started     time.Time
+	requests    int
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithToken requires the given bearer token on every API request.
+func WithToken(token string) Option {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithInfo sets the controller information returned by /info.
+// ConnectedAgents and UpTime are computed when left empty.
+func WithInfo(info client.InfoResult) Option {
+	return func(s *Server) {
+		s.info = info
+	}
+}
+
+// WithAgents adds agents to the initial state.
+func WithAgents(agents ...client.Agent) Option {
+	return func(s *Server) {
+		for _, agent := range agents {
+			s.agents[agent.ID] = agent
+		}
+	}
+}
+
+// WithAgentDetails adds agents together with their host facts,
+// capabilities and configuration to the initial state.
+func WithAgentDetails(details ...client.AgentDetails) Option {
+	return func(s *Server) {
+		for _, d := range details {
+			s.agents[d.ID] = d.Agent
+			s.details[d.ID] = d
+		}
+	}
+}
+
+// WithMigrations adds migrations to the initial state.
+func WithMigrations(migrations ...client.Migration) Option {
+	return func(s *Server) {
+		for _, migration := range migrations {
+			s.migrations[migration.ID] = migration
+		}
+	}
+}
+
+// WithFaults sets the initial fault injection settings.
+func WithFaults(faults Faults) Option {
+	return func(s *Server) {
+		s.faults = faults
+	}
+}
+
+// WithSeed makes random fault injection reproducible.
+func WithSeed(seed int64) Option {
+	return func(s *Server) {
+		s.rand = rand.New(rand.NewSource(seed)) //nolint:gosec // Faults do not need cryptographic randomness.
+	}
+}
+
+// WithDemoData populates the fake with a small set of agents and migrations.
+func WithDemoData() Option {
+	return func(s *Server) {
+		WithAgentDetails(demoAgentDetails(s.started)...)(s)
+		WithMigrations(demoMigrations(s.started)...)(s)
+		for id, heartbeats := range demoHeartbeats(s.started) {
+			s.heartbeats[id] = heartbeats
+		}
+		for id, events := range demoEvents(s.started) {
+			s.events[id] = events
+		}
+		for id, entries := range demoLogs(s.started) {
+			s.logs[id] = entries
+		}
+	}
+}
+
+// New creates a healthy fake controller without agents or migrations.
+func New(opts ...Option) *Server {
+	now := time.Now()
+	s := &Server{
+		info: client.InfoResult{
+			Version:     "v1.0.0",
+			GitCommit:   "fake",
+			APIVersions: []string{client.APIVersion},
+			Features:    []string{"migrations", "drain"},
+			OS: client.OSInfo{
+				Name:         runtime.GOOS,
+				PlatformName: "fake",
+			},
+			GoVersion:       runtime.Version(),
+			MinAgentVersion: "v0.9",
+			MaxAgentVersion: "v1.0",
+		},
+		health: client.StatusResult{
+			Status: client.HealthHealthy,
+			Components: []client.ComponentHealth{
+				{Name: "api", State: client.HealthHealthy, LastCheck: now},
+				{Name: "database", State: client.HealthHealthy, LastCheck: now},
+				{Name: "scheduler", State: client.HealthHealthy, LastCheck: now},
+			},
+		},
+		agents:      map[string]client.Agent{},
+		migrations:  map[string]client.Migration{},
+		details:     map[string]client.AgentDetails{},
+		heartbeats:  map[string][]client.Heartbeat{},
+		events:      map[string][]client.Event{},
+		tokens:      map[string]client.JoinToken{},
+		logs:        map[string][]client.LogEntry{},
+		logLevels:   map[string]*logLevel{},
+		logAppended: make(chan struct{}),
+		logClosed:   make(chan struct{}),
+		rand:        rand.New(rand.NewSource(now.UnixNano())), //nolint:gosec // Faults do not need cryptographic randomness.
+		started:     now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("GET /ping", s.handlePing)
+	s.mux.HandleFunc("GET /info", s.handleInfo)
+	s.mux.HandleFunc("GET /health", s.handleHealth)
+	s.mux.HandleFunc("GET /agents", s.handleListAgents)
+	s.mux.HandleFunc("GET /agents/{id}", s.handleGetAgent)
+	s.mux.HandleFunc("DELETE /agents/{id}", s.handleDeleteAgent)
+	s.mux.HandleFunc("GET /agents/{id}/details", s.handleGetAgentDetails)
+	s.mux.HandleFunc("GET /agents/{id}/heartbeats", s.handleListHeartbeats)
+	s.mux.HandleFunc("GET /agents/{id}/events", s.handleListEvents)
+	s.mux.HandleFunc("POST /agents/{id}/cordon", s.handleSetSchedulable(false))
+	s.mux.HandleFunc("POST /agents/{id}/uncordon", s.handleSetSchedulable(true))
+	s.mux.HandleFunc("POST /agents/{id}/upgrade", s.handleUpgradeAgent)
+	s.mux.HandleFunc("POST /agents/{id}/handoff", s.handleHandOff)
+	s.mux.HandleFunc("PATCH /agents/{id}/labels", s.handleUpdateMetadata(true))
+	s.mux.HandleFunc("PATCH /agents/{id}/annotations", s.handleUpdateMetadata(false))
+	s.mux.HandleFunc("GET /agents/{id}/log-level", s.handleGetLogLevel)
+	s.mux.HandleFunc("PUT /agents/{id}/log-level", s.handleSetLogLevel)
+	s.mux.HandleFunc("GET /agents/{id}/logs", s.handleStreamLogs)
+	s.mux.HandleFunc("POST /join-tokens", s.handleCreateJoinToken)
+	s.mux.HandleFunc("GET /join-tokens", s.handleListJoinTokens)
+	s.mux.HandleFunc("DELETE /join-tokens/{id}", s.handleRevokeJoinToken)
+	s.mux.HandleFunc("GET /migrations", s.handleListMigrations)
+	s.mux.HandleFunc("GET /migrations/{id}", s.handleGetMigration)
+	s.mux.HandleFunc("GET "+ControlPrefix+"faults", s.handleGetFaults)
+	s.mux.HandleFunc("PUT "+ControlPrefix+"faults", s.handlePutFaults)
+
+	return s
+}
+
+// ServeHTTP authenticates the request, injects faults and dispatches it.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, ControlPrefix) {
+		s.mux.ServeHTTP(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests++
+	version := s.info.Version
+	s.mu.Unlock()
+	w.Header().Set(client.ControllerVersionHeader, version)
+
+	if !s.injectFaults(w, r) {
+		return
+	}
+	if !s.authorize(r) {
+		writeError(w, http.StatusUnauthorized, "invalid or missing token")
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// RequestCount returns the number of API requests received so far.
+func (s *Server) RequestCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// SetHealth replaces the health reported by /health.
+func (s *Server) SetHealth(health client.StatusResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.health = health
+}
+
+// SetVersion changes the version reported by /info and in the
+// client.ControllerVersionHeader of every response.
+func (s *Server) SetVersion(version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.info.Version = version
+}
+
+// PutAgent adds or replaces an agent.
+func (s *Server) PutAgent(agent client.Agent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.agents[agent.ID] = agent
+}
+
+// DeleteAgent removes an agent.
+func (s *Server) DeleteAgent(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.agents, id)
+}
+
+// Agent returns the agent with the given ID.
+func (s *Server) Agent(id string) (client.Agent, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	agent, ok := s.agents[id]
+	return agent, ok
+}
+
+// AddHeartbeat records a heartbeat of an agent.
+func (s *Server) AddHeartbeat(agentID string, heartbeat client.Heartbeat) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.heartbeats[agentID] = append(s.heartbeats[agentID], heartbeat)
+}
+
+// AddEvent records an event of an agent.
+func (s *Server) AddEvent(agentID string, event client.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events[agentID] = append(s.events[agentID], event)
+}
+
+// PutMigration adds or replaces a migration.
+func (s *Server) PutMigration(migration client.Migration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.migrations[migration.ID] = migration
+}
+
+func (s *Server) authorize(r *http.Request) bool {
+	if s.token == "" {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+func (s *Server) handlePing(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("X-Response-Time", "0.1ms")
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleInfo(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	info := s.info
+	if info.ConnectedAgents == 0 {
+		for _, agent := range s.agents {
+			if agent.Status == client.AgentReady {
+				info.ConnectedAgents++
+			}
+		}
+	}
+	if info.UpTime == 0 {
+		info.UpTime = client.Duration(time.Since(s.started).Round(time.Second))
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	health := s.health
+	s.mu.Unlock()
+
+	status := http.StatusOK
+	if health.Overall() == client.HealthUnhealthy {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, health)
+}
+
+func (s *Server) handleListAgents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	selector, err := client.ParseSelector(query.Get("selector"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	agents := make([]client.Agent, 0, len(s.agents))
+	for _, agent := range s.agents {
+		if status := query.Get("status"); status != "" && agent.Status != status {
+			continue
+		}
+		if !selector.Matches(agent.Labels) {
+			continue
+		}
+		agent.ActiveMigrations = s.activeMigrations(agent.ID)
+		agents = append(agents, agent)
+	}
+	s.mu.Unlock()
+
+	slices.SortFunc(agents, func(a, b client.Agent) int { return strings.Compare(a.ID, b.ID) })
+	writeJSON(w, http.StatusOK, agents)
+}
+
+func (s *Server) handleGetAgent(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	agent, ok := s.agents[r.PathValue("id")]
+	agent.ActiveMigrations = s.activeMigrations(agent.ID)
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "agent not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, agent)
+}
+
+func (s *Server) handleDeleteAgent(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.agents[id]; !ok {
+		writeError(w, http.StatusNotFound, "agent not found")
+		return
+	}
+	if active := s.activeMigrations(id); active > 0 && r.URL.Query().Get("force") != "true" {
+		writeError(w, http.StatusConflict, fmt.Sprintf("agent has %d active migrations", active))
+		return
+	}
+
+	delete(s.agents, id)
+	delete(s.details, id)
+	delete(s.heartbeats, id)
+	delete(s.events, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleSetSchedulable(schedulable bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		agent, ok := s.agents[id]
+		if !ok {
+			writeError(w, http.StatusNotFound, "agent not found")
+			return
+		}
+		agent.Schedulable = schedulable
+		s.agents[id] = agent
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleHandOff moves the active migrations of an agent to the ready and
+// schedulable agent with the fewest active migrations, or cancels them if
+// there is none.
+func (s *Server) handleHandOff(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.agents[id]; !ok {
+		writeError(w, http.StatusNotFound, "agent not found")
+		return
+	}
+
+	changed := []client.Migration{}
+	for _, migration := range s.migrations {
+		if migration.Phase != client.MigrationPending && migration.Phase != client.MigrationRunning {
+			continue
+		}
+		if migration.SourceAgent != id && migration.TargetAgent != id {
+			continue
+		}
+
+		other := migration.TargetAgent
+		if other == id {
+			other = migration.SourceAgent
+		}
+		replacement := s.handOffTarget(id, other)
+		switch {
+		case replacement == "":
+			migration.Phase = client.MigrationCancelled
+			migration.Message = fmt.Sprintf("cancelled while draining %s: no agent can take over", id)
+		case migration.SourceAgent == id:
+			migration.SourceAgent = replacement
+			migration.Message = fmt.Sprintf("handed off from %s to %s", id, replacement)
+		default:
+			migration.TargetAgent = replacement
+			migration.Message = fmt.Sprintf("handed off from %s to %s", id, replacement)
+		}
+		s.migrations[migration.ID] = migration
+		changed = append(changed, migration)
+	}
+
+	slices.SortFunc(changed, func(a, b client.Migration) int { return strings.Compare(a.ID, b.ID) })
+	writeJSON(w, http.StatusOK, changed)
+}
+
+// handOffTarget returns the agent to take over a migration from an agent,
+// excluding the other agent of the migration. The caller must hold s.mu.
+func (s *Server) handOffTarget(from, other string) string {
+	best, bestActive := "", 0
+	for _, id := range slices.Sorted(maps.Keys(s.agents)) {
+		agent := s.agents[id]
+		if id == from || id == other || agent.Status != client.AgentReady || !agent.Schedulable {
+			continue
+		}
+		if active := s.activeMigrations(id); best == "" || active < bestActive {
+			best, bestActive = id, active
+		}
+	}
+	return best
+}
+
+func (s *Server) handleGetAgentDetails(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	s.mu.Lock()
+	agent, ok := s.agents[id]
+	details := s.details[id]
+	details.Agent = agent
+	details.ActiveMigrations = s.activeMigrations(id)
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "agent not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, details)
+}
+
+func (s *Server) handleListHeartbeats(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	heartbeats := slices.Clone(s.heartbeats[r.PathValue("id")])
+	s.mu.Unlock()
+
+	slices.SortStableFunc(heartbeats, func(a, b client.Heartbeat) int { return b.Time.Compare(a.Time) })
+	writeRecent(s, w, r, heartbeats)
+}
+
+func (s *Server) handleListEvents(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	events := slices.Clone(s.events[r.PathValue("id")])
+	s.mu.Unlock()
+
+	slices.SortStableFunc(events, func(a, b client.Event) int { return b.LastSeen.Compare(a.LastSeen) })
+	writeRecent(s, w, r, events)
+}
+
+// writeRecent writes the newest-first items of an agent, limited by the
+// limit query parameter, or 404 if the agent does not exist.
+func writeRecent[T any](s *Server, w http.ResponseWriter, r *http.Request, items []T) {
+	s.mu.Lock()
+	_, ok := s.agents[r.PathValue("id")]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "agent not found")
+		return
+	}
+
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			writeError(w, http.StatusBadRequest, "invalid limit "+strconv.Quote(raw))
+			return
+		}
+		items = items[:min(limit, len(items))]
+	}
+	if items == nil {
+		items = []T{}
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+// activeMigrations counts the pending and running migrations of an agent.
+// The caller must hold s.mu.
+func (s *Server) activeMigrations(agentID string) int {
+	count := 0
+	for _, migration := range s.migrations {
+		if migration.Phase != client.MigrationPending && migration.Phase != client.MigrationRunning {
+			continue
+		}
+		if migration.SourceAgent == agentID || migration.TargetAgent == agentID {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *Server) handleListMigrations(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	selector, err := client.ParseSelector(query.Get("selector"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	migrations := make([]client.Migration, 0, len(s.migrations))
+	for _, migration := range s.migrations {
+		if phase := query.Get("phase"); phase != "" && migration.Phase != phase {
+			continue
+		}
+		if agent := query.Get("agent"); agent != "" && migration.SourceAgent != agent && migration.TargetAgent != agent {
+			continue
+		}
+		if !selector.Matches(migration.Labels) {
+			continue
+		}
+		migrations = append(migrations, migration)
+	}
+	s.mu.Unlock()
+
+	slices.SortFunc(migrations, func(a, b client.Migration) int { return strings.Compare(a.ID, b.ID) })
+	writeJSON(w, http.StatusOK, migrations)
+}
+
+func (s *Server) handleGetMigration(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	migration, ok := s.migrations[r.PathValue("id")]
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "migration not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, migration)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,437 @@
+package fakecontroller
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/morpher-vm/morpherctl/internal/errdefs"
+	"github.com/morpher-vm/morpherctl/pkg/client"
+)
+
+func newTestClient(t *testing.T, fake *Server, opts ...client.Option) *client.Client {
+	t.Helper()
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	c, err := client.New(server.URL, opts...)
+	require.NoError(t, err)
+	return c
+}
+
+func TestServer_Endpoints(t *testing.T) {
+	fake := New(WithDemoData())
+	c := newTestClient(t, fake)
+	ctx := context.Background()
+
+	ping, err := c.Ping(ctx)
+	require.NoError(t, err)
+	assert.True(t, ping.Success)
+
+	info, err := c.GetInfo(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, info.Result)
+	assert.Equal(t, "v1.0.0", info.Result.Version)
+	assert.Equal(t, 2, info.Result.ConnectedAgents)
+
+	status, err := c.GetStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, client.HealthHealthy, status.Result.Overall())
+
+	fake.SetHealth(client.StatusResult{Status: client.HealthUnhealthy})
+	status, err = c.GetStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, status.StatusCode)
+	assert.Equal(t, client.HealthUnhealthy, status.Result.Overall())
+
+	migration, err := c.GetMigration(ctx, "mig-2")
+	require.NoError(t, err)
+	assert.Equal(t, client.MigrationRunning, migration.Phase)
+
+	_, err = c.GetAgent(ctx, "missing")
+	assert.Equal(t, errdefs.KindNotFound, errdefs.KindOf(err))
+
+	assert.Equal(t, 6, fake.RequestCount())
+}
+
+func TestServer_ListAgents(t *testing.T) {
+	c := newTestClient(t, New(WithDemoData()))
+
+	tests := []struct {
+		name        string
+		opts        client.ListAgentsOptions
+		expectedIDs []string
+	}{
+		{
+			name:        "should list all agents sorted by id",
+			expectedIDs: []string{"agent-1", "agent-2", "agent-3"},
+		},
+		{
+			name:        "should filter by status",
+			opts:        client.ListAgentsOptions{Status: client.AgentNotReady},
+			expectedIDs: []string{"agent-3"},
+		},
+		{
+			name:        "should filter by selector",
+			opts:        client.ListAgentsOptions{Selector: "zone=b,role!=storage"},
+			expectedIDs: []string{"agent-2"},
+		},
+		{
+			name:        "should filter by missing label",
+			opts:        client.ListAgentsOptions{Selector: "!role"},
+			expectedIDs: []string{},
+		},
+		{
+			name:        "should filter by set membership",
+			opts:        client.ListAgentsOptions{Selector: "role notin (storage),zone in (a,b)"},
+			expectedIDs: []string{"agent-1", "agent-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agents, err := c.ListAgents(context.Background(), tt.opts)
+			require.NoError(t, err)
+
+			ids := []string{}
+			for _, agent := range agents {
+				ids = append(ids, agent.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
+
+func TestServer_AgentDetails(t *testing.T) {
+	fake := New(WithDemoData())
+	c := newTestClient(t, fake)
+	ctx := context.Background()
+
+	details, err := c.GetAgentDetails(ctx, "agent-1")
+	require.NoError(t, err)
+	assert.Equal(t, "node1.example.com", details.Hostname)
+	assert.Equal(t, 1, details.ActiveMigrations)
+	assert.Equal(t, []string{"kvm"}, details.Capabilities.TargetHypervisors)
+
+	heartbeats, err := c.ListAgentHeartbeats(ctx, "agent-1", 3)
+	require.NoError(t, err)
+	require.Len(t, heartbeats, 3)
+	assert.True(t, heartbeats[0].Time.After(heartbeats[1].Time), "heartbeats should be newest first")
+
+	fake.AddEvent("agent-1", client.Event{Type: client.EventWarning, Reason: "Test", LastSeen: time.Now().Add(time.Minute)})
+	events, err := c.ListAgentEvents(ctx, "agent-1", 0)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, "Test", events[0].Reason)
+
+	_, err = c.ListAgentEvents(ctx, "missing", 0)
+	assert.Equal(t, errdefs.KindNotFound, errdefs.KindOf(err))
+}
+
+func TestServer_UpdateMetadata(t *testing.T) {
+	fake := New(WithDemoData())
+	c := newTestClient(t, fake)
+	ctx := context.Background()
+
+	agent, err := c.UpdateAgentLabels(ctx, "agent-1", client.MetadataUpdate{
+		Set:    map[string]string{"site": "dc1"},
+		Remove: []string{"role", "missing"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"site": "dc1", "zone": "a"}, agent.Labels)
+
+	_, err = c.UpdateAgentLabels(ctx, "agent-1", client.MetadataUpdate{Set: map[string]string{"zone": "b"}})
+	assert.Equal(t, errdefs.KindConflict, errdefs.KindOf(err))
+	assert.Contains(t, err.Error(), `label "zone" already has value "a"`)
+
+	agent, err = c.UpdateAgentLabels(ctx, "agent-1", client.MetadataUpdate{Set: map[string]string{"zone": "b"}, Overwrite: true})
+	require.NoError(t, err)
+	assert.Equal(t, "b", agent.Labels["zone"])
+
+	_, err = c.UpdateAgentLabels(ctx, "agent-1", client.MetadataUpdate{Set: map[string]string{"zone": "not valid"}})
+	assert.Equal(t, errdefs.KindUsage, errdefs.KindOf(err))
+
+	agent, err = c.UpdateAgentAnnotations(ctx, "agent-1", client.MetadataUpdate{Set: map[string]string{"owner": "storage team"}})
+	require.NoError(t, err)
+	assert.Equal(t, "storage team", agent.Annotations["owner"])
+	assert.Equal(t, "dc1", agent.Labels["site"])
+
+	_, err = c.UpdateAgentAnnotations(ctx, "agent-9", client.MetadataUpdate{})
+	assert.Equal(t, errdefs.KindNotFound, errdefs.KindOf(err))
+}
+
+func TestServer_DeregisterAgent(t *testing.T) {
+	fake := New(WithDemoData())
+	c := newTestClient(t, fake)
+	ctx := context.Background()
+
+	err := c.DeregisterAgent(ctx, "agent-1", false)
+	assert.Equal(t, errdefs.KindConflict, errdefs.KindOf(err))
+	assert.Contains(t, err.Error(), "agent has 1 active migrations")
+
+	require.NoError(t, c.DeregisterAgent(ctx, "agent-1", true))
+	_, ok := fake.Agent("agent-1")
+	assert.False(t, ok)
+
+	require.NoError(t, c.DeregisterAgent(ctx, "agent-3", false))
+	err = c.DeregisterAgent(ctx, "agent-3", false)
+	assert.Equal(t, errdefs.KindNotFound, errdefs.KindOf(err))
+}
+
+func TestServer_HandOff(t *testing.T) {
+	agents := []client.Agent{
+		{ID: "agent-a", Status: client.AgentReady, Schedulable: true},
+		{ID: "agent-b", Status: client.AgentReady, Schedulable: true},
+		{ID: "agent-c", Status: client.AgentReady, Schedulable: true},
+		{ID: "agent-d", Status: client.AgentReady, Schedulable: false},
+	}
+	fake := New(WithAgents(agents...), WithMigrations(
+		client.Migration{ID: "mig-1", SourceAgent: "agent-a", TargetAgent: "agent-b", Phase: client.MigrationRunning},
+		client.Migration{ID: "mig-2", SourceAgent: "agent-c", TargetAgent: "agent-a", Phase: client.MigrationPending},
+		client.Migration{ID: "mig-3", SourceAgent: "agent-a", TargetAgent: "agent-b", Phase: client.MigrationCompleted},
+	))
+	c := newTestClient(t, fake)
+
+	migrations, err := c.HandOffMigrations(context.Background(), "agent-a")
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+	assert.Equal(t, "agent-c", migrations[0].SourceAgent)
+	assert.Equal(t, "handed off from agent-a to agent-c", migrations[0].Message)
+	assert.Equal(t, "agent-b", migrations[1].TargetAgent)
+
+	// With agent-a cordoned, no agent can take over from agent-b.
+	require.NoError(t, c.CordonAgent(context.Background(), "agent-a"))
+	migrations, err = c.HandOffMigrations(context.Background(), "agent-b")
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+	for _, migration := range migrations {
+		assert.Equal(t, client.MigrationCancelled, migration.Phase)
+	}
+
+	_, err = c.HandOffMigrations(context.Background(), "agent-9")
+	assert.Equal(t, errdefs.KindNotFound, errdefs.KindOf(err))
+}
+
+func TestServer_UpgradeAgent(t *testing.T) {
+	fake := New(WithDemoData(), WithUpgradeDelay(10*time.Millisecond), WithFailingUpgrades("agent-2"))
+	c := newTestClient(t, fake)
+	ctx := context.Background()
+
+	require.NoError(t, c.CordonAgent(ctx, "agent-1"))
+	agent, _ := fake.Agent("agent-1")
+	assert.False(t, agent.Schedulable)
+	require.NoError(t, c.UncordonAgent(ctx, "agent-1"))
+	agent, _ = fake.Agent("agent-1")
+	assert.True(t, agent.Schedulable)
+
+	require.NoError(t, c.UpgradeAgent(ctx, "agent-1", "v1.0.1"))
+	require.NoError(t, c.UpgradeAgent(ctx, "agent-2", "v1.0.1"))
+	agent, _ = fake.Agent("agent-1")
+	assert.Equal(t, client.AgentNotReady, agent.Status)
+
+	require.Eventually(t, func() bool {
+		agent, _ := fake.Agent("agent-1")
+		return agent.Version == "v1.0.1" && agent.Status == client.AgentReady
+	}, time.Second, 5*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		events, err := c.ListAgentEvents(ctx, "agent-2", 1)
+		return err == nil && len(events) == 1 && events[0].Reason == "UpgradeFailed"
+	}, time.Second, 5*time.Millisecond)
+	agent, _ = fake.Agent("agent-2")
+	assert.Equal(t, "v1.0.0", agent.Version)
+
+	err := c.UpgradeAgent(ctx, "agent-9", "v1.0.1")
+	assert.Equal(t, errdefs.KindNotFound, errdefs.KindOf(err))
+}
+
+func TestServer_LogLevel(t *testing.T) {
+	fake := New(WithDemoData())
+	c := newTestClient(t, fake)
+	ctx := context.Background()
+
+	level, err := c.GetAgentLogLevel(ctx, "agent-3")
+	require.NoError(t, err)
+	assert.Equal(t, client.AgentLogLevel{Level: client.LogDebug, Default: client.LogDebug}, *level)
+
+	level, err = c.SetAgentLogLevel(ctx, "agent-1", client.SetLogLevelRequest{
+		Level:    client.LogDebug,
+		Duration: client.Duration(20 * time.Millisecond),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, client.LogDebug, level.Level)
+	assert.Equal(t, client.LogInfo, level.Default)
+	assert.NotNil(t, level.RevertAt)
+
+	require.Eventually(t, func() bool {
+		level, err := c.GetAgentLogLevel(ctx, "agent-1")
+		return err == nil && level.Level == client.LogInfo && level.RevertAt == nil
+	}, time.Second, 5*time.Millisecond)
+
+	level, err = c.SetAgentLogLevel(ctx, "agent-1", client.SetLogLevelRequest{Level: client.LogWarn})
+	require.NoError(t, err)
+	assert.Equal(t, client.AgentLogLevel{Level: client.LogWarn, Default: client.LogWarn}, *level)
+	details, err := c.GetAgentDetails(ctx, "agent-1")
+	require.NoError(t, err)
+	assert.Equal(t, client.LogWarn, details.Config.LogLevel)
+
+	_, err = c.SetAgentLogLevel(ctx, "agent-1", client.SetLogLevelRequest{Level: "trace"})
+	assert.Equal(t, errdefs.KindUsage, errdefs.KindOf(err))
+	_, err = c.GetAgentLogLevel(ctx, "agent-9")
+	assert.Equal(t, errdefs.KindNotFound, errdefs.KindOf(err))
+}
+
+func TestServer_StreamLogs(t *testing.T) {
+	start := time.Now()
+	fake := New(WithAgents(client.Agent{ID: "agent-a"}), WithAgentLogs("agent-a",
+		client.LogEntry{Time: start.Add(-time.Hour), Level: client.LogInfo, Message: "old"},
+		client.LogEntry{Time: start, Level: client.LogInfo, Message: "recent"},
+	))
+	c := newTestClient(t, fake)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var messages []string
+	err := c.StreamAgentLogs(ctx, "agent-a", client.AgentLogOptions{Since: start.Add(-time.Minute), Follow: true}, func(entry client.LogEntry) error {
+		messages = append(messages, entry.Message)
+		if len(messages) == 1 {
+			fake.AppendAgentLog("agent-a", client.LogEntry{Time: time.Now(), Level: client.LogWarn, Message: "new"})
+		} else {
+			fake.CloseLogStreams()
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, client.ErrLogStreamClosed)
+	assert.Equal(t, []string{"recent", "new"}, messages)
+}
+
+func TestServer_JoinTokens(t *testing.T) {
+	fake := New()
+	c := newTestClient(t, fake)
+	ctx := context.Background()
+
+	created, err := c.CreateJoinToken(ctx, client.CreateJoinTokenRequest{
+		TTL:     client.Duration(time.Hour),
+		Labels:  map[string]string{"site": "dc1"},
+		MaxUses: 5,
+	})
+	require.NoError(t, err)
+	assert.Regexp(t, `^[a-z0-9]{6}\.[a-z0-9]{16}$`, created.Token)
+	assert.Equal(t, created.ID, created.Token[:6])
+	assert.WithinDuration(t, time.Now().Add(time.Hour), created.ExpiresAt, time.Minute)
+
+	tokens, err := c.ListJoinTokens(ctx)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.Empty(t, tokens[0].Token)
+	assert.Equal(t, map[string]string{"site": "dc1"}, tokens[0].Labels)
+	assert.Equal(t, client.TokenActive, tokens[0].Status(time.Now()))
+
+	require.NoError(t, c.RevokeJoinToken(ctx, created.ID))
+	token, ok := fake.JoinToken(created.ID)
+	require.True(t, ok)
+	assert.Equal(t, client.TokenRevoked, token.Status(time.Now()))
+
+	err = c.RevokeJoinToken(ctx, "nope00")
+	assert.Equal(t, errdefs.KindNotFound, errdefs.KindOf(err))
+
+	_, err = c.CreateJoinToken(ctx, client.CreateJoinTokenRequest{})
+	assert.Equal(t, errdefs.KindUsage, errdefs.KindOf(err))
+}
+
+func TestServer_Auth(t *testing.T) {
+	fake := New(WithToken("secret"))
+
+	t.Run("should reject missing token", func(t *testing.T) {
+		c := newTestClient(t, fake)
+		ping, err := c.Ping(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, ping.StatusCode)
+		assert.Equal(t, errdefs.KindAuth, errdefs.KindOf(ping.Err()))
+	})
+
+	t.Run("should accept valid token", func(t *testing.T) {
+		c := newTestClient(t, fake, client.WithToken("secret"))
+		ping, err := c.Ping(context.Background())
+		require.NoError(t, err)
+		assert.True(t, ping.Success)
+	})
+}
+
+func TestServer_Faults(t *testing.T) {
+	tests := []struct {
+		name         string
+		faults       Faults
+		expectedKind errdefs.Kind
+	}{
+		{
+			name:         "should inject error status",
+			faults:       Faults{ErrorRate: 1, ErrorStatus: http.StatusBadGateway},
+			expectedKind: errdefs.KindServer,
+		},
+		{
+			name:         "should drop connections",
+			faults:       Faults{DropRate: 1},
+			expectedKind: errdefs.KindNetwork,
+		},
+		{
+			name:         "should delay responses",
+			faults:       Faults{Latency: client.Duration(time.Second)},
+			expectedKind: errdefs.KindTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, New(WithFaults(tt.faults), WithSeed(1)), client.WithTimeout(100*time.Millisecond))
+
+			_, err := c.ListAgents(context.Background(), client.ListAgentsOptions{})
+			require.Error(t, err)
+			assert.Equal(t, tt.expectedKind, errdefs.KindOf(err))
+		})
+	}
+
+	t.Run("should only affect listed paths", func(t *testing.T) {
+		c := newTestClient(t, New(WithFaults(Faults{ErrorRate: 1, Paths: []string{"/agents"}})))
+
+		ping, err := c.Ping(context.Background())
+		require.NoError(t, err)
+		assert.True(t, ping.Success)
+
+		_, err = c.ListAgents(context.Background(), client.ListAgentsOptions{})
+		assert.Error(t, err)
+	})
+}
+
+func TestServer_ControlEndpoint(t *testing.T) {
+	fake := New(WithToken("secret"))
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	put := func(body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPut, server.URL+ControlPrefix+"faults", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	assert.Equal(t, http.StatusBadRequest, put(`{"error_rate": 2}`).StatusCode)
+	assert.Equal(t, http.StatusOK, put(`{"error_rate": 1, "error_status": 503}`).StatusCode)
+
+	c, err := client.New(server.URL, client.WithToken("secret"))
+	require.NoError(t, err)
+	ping, err := c.Ping(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, ping.StatusCode)
+	assert.Equal(t, 1, fake.RequestCount())
+}
@@ -0,0 +1,137 @@
+package fakecontroller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/pkg/client"
+)
+
+// Faults describes the failures injected into API requests.
+type Faults struct {
+	// Latency delays every response.
+	Latency client.Duration `json:"latency,omitempty"`
+	// Jitter adds a random delay of up to the given duration.
+	Jitter client.Duration `json:"jitter,omitempty"`
+	// ErrorRate is the fraction of requests, from 0 to 1, answered with ErrorStatus.
+	ErrorRate float64 `json:"error_rate,omitempty"`
+	// ErrorStatus is the status code of injected errors; 500 when zero.
+	ErrorStatus int `json:"error_status,omitempty"`
+	// DropRate is the fraction of requests, from 0 to 1, whose connection is
+	// closed without a response.
+	DropRate float64 `json:"drop_rate,omitempty"`
+	// Paths limits fault injection to request paths with one of the given
+	// prefixes. Faults apply to every path when empty.
+	Paths []string `json:"paths,omitempty"`
+}
+
+// Validate checks that rates are within range and the error status is valid.
+func (f Faults) Validate() error {
+	if f.ErrorRate < 0 || f.ErrorRate > 1 {
+		return fmt.Errorf("invalid error rate %g: must be between 0 and 1", f.ErrorRate)
+	}
+	if f.DropRate < 0 || f.DropRate > 1 {
+		return fmt.Errorf("invalid drop rate %g: must be between 0 and 1", f.DropRate)
+	}
+	if f.ErrorStatus != 0 && (f.ErrorStatus < 400 || f.ErrorStatus > 599) {
+		return fmt.Errorf("invalid error status %d: must be between 400 and 599", f.ErrorStatus)
+	}
+	if f.Latency < 0 || f.Jitter < 0 {
+		return fmt.Errorf("invalid latency: must not be negative")
+	}
+	return nil
+}
+
+// appliesTo reports whether the faults affect the given request path.
+func (f Faults) appliesTo(path string) bool {
+	if len(f.Paths) == 0 {
+		return true
+	}
+	for _, prefix := range f.Paths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetFaults replaces the fault injection settings.
+func (s *Server) SetFaults(faults Faults) error {
+	if err := faults.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = faults
+	return nil
+}
+
+// injectFaults applies the configured faults to the request and reports
+// whether the request should still be served.
+func (s *Server) injectFaults(w http.ResponseWriter, r *http.Request) bool {
+	s.mu.Lock()
+	faults := s.faults
+	delay := time.Duration(faults.Latency)
+	if faults.Jitter > 0 {
+		delay += time.Duration(s.rand.Int63n(int64(faults.Jitter)))
+	}
+	drop := s.rand.Float64() < faults.DropRate
+	fail := s.rand.Float64() < faults.ErrorRate
+	s.mu.Unlock()
+
+	if !faults.appliesTo(r.URL.Path) {
+		return true
+	}
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-r.Context().Done():
+			return false
+		case <-timer.C:
+		}
+	}
+
+	if drop {
+		// Aborting the handler closes the connection without a response.
+		panic(http.ErrAbortHandler)
+	}
+
+	if fail {
+		status := faults.ErrorStatus
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+		writeError(w, status, "injected fault")
+		return false
+	}
+
+	return true
+}
+
+func (s *Server) handleGetFaults(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	faults := s.faults
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, faults)
+}
+
+func (s *Server) handlePutFaults(w http.ResponseWriter, r *http.Request) {
+	var faults Faults
+	if err := json.NewDecoder(r.Body).Decode(&faults); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid faults: %v", err))
+		return
+	}
+	if err := s.SetFaults(faults); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, faults)
+}
@@ -0,0 +1,213 @@
+package fakecontroller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/pkg/client"
+)
+
+// logLevels are the log levels agents accept.
+var logLevels = []string{client.LogDebug, client.LogInfo, client.LogWarn, client.LogError}
+
+// logLevel is the runtime log level of an agent.
+type logLevel struct {
+	client.AgentLogLevel
+	// changes counts the changes so that a revert does not undo a later one.
+	changes int
+}
+
+// WithAgentLogs adds log entries of an agent to the initial state.
+func WithAgentLogs(id string, entries ...client.LogEntry) Option {
+	return func(s *Server) {
+		s.logs[id] = append(s.logs[id], entries...)
+	}
+}
+
+// AppendAgentLog adds a log entry of an agent and sends it to the streams
+// following the agent's logs.
+func (s *Server) AppendAgentLog(id string, entry client.LogEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.appendLog(id, entry)
+}
+
+// CloseLogStreams ends every open log stream, as a controller restart would.
+func (s *Server) CloseLogStreams() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	close(s.logClosed)
+	s.logClosed = make(chan struct{})
+}
+
+// appendLog adds a log entry and wakes up followers. The caller must hold s.mu.
+func (s *Server) appendLog(id string, entry client.LogEntry) {
+	s.logs[id] = append(s.logs[id], entry)
+	close(s.logAppended)
+	s.logAppended = make(chan struct{})
+}
+
+// agentLogLevel returns the log level state of an agent, starting from its
+// configured level. The caller must hold s.mu.
+func (s *Server) agentLogLevel(id string) *logLevel {
+	if level, ok := s.logLevels[id]; ok {
+		return level
+	}
+	configured := s.details[id].Config.LogLevel
+	if configured == "" {
+		configured = client.LogInfo
+	}
+	level := &logLevel{AgentLogLevel: client.AgentLogLevel{Level: configured, Default: configured}}
+	s.logLevels[id] = level
+	return level
+}
+
+// setLogLevel changes the level an agent logs at and records the change in
+// its log. The caller must hold s.mu.
+func (s *Server) setLogLevel(id string, level *logLevel, to string) {
+	level.Level = to
+	if details, ok := s.details[id]; ok {
+		details.Config.LogLevel = to
+		s.details[id] = details
+	}
+	s.appendLog(id, client.LogEntry{
+		Time:    time.Now(),
+		Level:   client.LogInfo,
+		Message: "log level changed",
+		Fields:  map[string]string{"level": to},
+	})
+}
+
+func (s *Server) handleGetLogLevel(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.agents[id]; !ok {
+		writeError(w, http.StatusNotFound, "agent not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.agentLogLevel(id).AgentLogLevel)
+}
+
+func (s *Server) handleSetLogLevel(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var req client.SetLogLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if !slices.Contains(logLevels, req.Level) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid log level %q", req.Level))
+		return
+	}
+	if req.Duration < 0 {
+		writeError(w, http.StatusBadRequest, "duration must not be negative")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.agents[id]; !ok {
+		writeError(w, http.StatusNotFound, "agent not found")
+		return
+	}
+
+	level := s.agentLogLevel(id)
+	level.changes++
+	level.RevertAt = nil
+	s.setLogLevel(id, level, req.Level)
+
+	if req.Duration == 0 {
+		level.Default = req.Level
+	} else {
+		revertAt := time.Now().Add(time.Duration(req.Duration))
+		level.RevertAt = &revertAt
+		change := level.changes
+		time.AfterFunc(time.Duration(req.Duration), func() { s.revertLogLevel(id, change) })
+	}
+	writeJSON(w, http.StatusOK, level.AgentLogLevel)
+}
+
+// revertLogLevel resets a temporary log level to the default, unless the
+// level was changed again since the given change.
+func (s *Server) revertLogLevel(id string, change int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	level, ok := s.logLevels[id]
+	if !ok || level.changes != change {
+		return
+	}
+	level.RevertAt = nil
+	s.setLogLevel(id, level, level.Default)
+}
+
+// handleStreamLogs writes the log entries of an agent as newline-delimited
+// JSON. With follow=true the stream stays open and new entries are written
+// as they are appended, until the client goes away or CloseLogStreams.
+func (s *Server) handleStreamLogs(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	query := r.URL.Query()
+
+	var since time.Time
+	if raw := query.Get("since"); raw != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339Nano, raw); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid since "+raw)
+			return
+		}
+	}
+	follow := query.Get("follow") == "true"
+
+	s.mu.Lock()
+	_, ok := s.agents[id]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "agent not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+
+	sent := 0
+	for {
+		s.mu.Lock()
+		entries := slices.Clone(s.logs[id][sent:])
+		appended, closed := s.logAppended, s.logClosed
+		s.mu.Unlock()
+
+		sent += len(entries)
+		for _, entry := range entries {
+			if entry.Time.Before(since) {
+				continue
+			}
+			if err := encoder.Encode(entry); err != nil {
+				return
+			}
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		if !follow {
+			return
+		}
+
+		select {
+		case <-appended:
+		case <-closed:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
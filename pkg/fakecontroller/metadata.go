@@ -0,0 +1,84 @@
+package fakecontroller
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+
+	"github.com/morpher-vm/morpherctl/pkg/client"
+)
+
+// handleUpdateMetadata changes the labels of an agent, or its annotations if
+// labels is false. Annotation values are not validated.
+func (s *Server) handleUpdateMetadata(labels bool) http.HandlerFunc {
+	kind := "annotation"
+	if labels {
+		kind = "label"
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+
+		var update client.MetadataUpdate
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		for key, value := range update.Set {
+			err := client.ValidateLabelKey(key)
+			if err == nil && labels {
+				err = client.ValidateLabelValue(value)
+			}
+			if err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid %s: %v", kind, err))
+				return
+			}
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		agent, ok := s.agents[id]
+		if !ok {
+			writeError(w, http.StatusNotFound, "agent not found")
+			return
+		}
+
+		current := agent.Annotations
+		if labels {
+			current = agent.Labels
+		}
+		if !update.Overwrite {
+			for key, value := range update.Set {
+				if old, ok := current[key]; ok && old != value {
+					writeError(w, http.StatusConflict, fmt.Sprintf("%s %q already has value %q", kind, key, old))
+					return
+				}
+			}
+		}
+
+		// Copy the map, which may be shared with the agent passed to WithAgents.
+		changed := maps.Clone(current)
+		if changed == nil {
+			changed = map[string]string{}
+		}
+		maps.Copy(changed, update.Set)
+		for _, key := range update.Remove {
+			delete(changed, key)
+		}
+		if len(changed) == 0 {
+			changed = nil
+		}
+
+		if labels {
+			agent.Labels = changed
+		} else {
+			agent.Annotations = changed
+		}
+		s.agents[id] = agent
+
+		agent.ActiveMigrations = s.activeMigrations(id)
+		writeJSON(w, http.StatusOK, agent)
+	}
+}
@@ -0,0 +1,87 @@
+package fakecontroller
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/pkg/client"
+)
+
+// JoinToken returns the join token with the given ID, without its secret.
+func (s *Server) JoinToken(id string) (client.JoinToken, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, ok := s.tokens[id]
+	token.Token = ""
+	return token, ok
+}
+
+func (s *Server) handleCreateJoinToken(w http.ResponseWriter, r *http.Request) {
+	var req client.CreateJoinTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if req.TTL <= 0 {
+		writeError(w, http.StatusBadRequest, "ttl must be positive")
+		return
+	}
+	if req.MaxUses < 0 {
+		writeError(w, http.StatusBadRequest, "max uses must not be negative")
+		return
+	}
+
+	// Tokens look like "<id>.<secret>" with a public ID, as kubeadm tokens do.
+	text := strings.ToLower(rand.Text())
+	now := time.Now()
+	token := client.JoinToken{
+		ID:          text[:6],
+		Token:       text[:6] + "." + text[6:22],
+		Description: req.Description,
+		Labels:      req.Labels,
+		MaxUses:     req.MaxUses,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Duration(req.TTL)),
+	}
+
+	s.mu.Lock()
+	s.tokens[token.ID] = token
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, token)
+}
+
+func (s *Server) handleListJoinTokens(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	tokens := make([]client.JoinToken, 0, len(s.tokens))
+	for _, token := range s.tokens {
+		token.Token = ""
+		tokens = append(tokens, token)
+	}
+	s.mu.Unlock()
+
+	slices.SortFunc(tokens, func(a, b client.JoinToken) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	writeJSON(w, http.StatusOK, tokens)
+}
+
+func (s *Server) handleRevokeJoinToken(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "join token not found")
+		return
+	}
+	token.Revoked = true
+	s.tokens[id] = token
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,106 @@
+package fakecontroller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/morpher-vm/morpherctl/pkg/client"
+)
+
+// upgradeSettings controls how agents behave when they are upgraded.
+type upgradeSettings struct {
+	delay   time.Duration
+	failing map[string]bool
+}
+
+// WithUpgradeDelay sets how long upgraded agents take to restart and report
+// the new version. Without it agents restart immediately.
+func WithUpgradeDelay(delay time.Duration) Option {
+	return func(s *Server) {
+		s.upgrades.delay = delay
+	}
+}
+
+// WithFailingUpgrades makes upgrades of the given agents fail: the agents
+// stay not ready with their old version.
+func WithFailingUpgrades(agentIDs ...string) Option {
+	return func(s *Server) {
+		if s.upgrades.failing == nil {
+			s.upgrades.failing = map[string]bool{}
+		}
+		for _, id := range agentIDs {
+			s.upgrades.failing[id] = true
+		}
+	}
+}
+
+func (s *Server) handleUpgradeAgent(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var req struct {
+		Version string `json:"Version"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Version == "" {
+		writeError(w, http.StatusBadRequest, "a version is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, ok := s.agents[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "agent not found")
+		return
+	}
+	if agent.Version == req.Version {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	// The agent goes away while it replaces its binary and restarts.
+	agent.Status = client.AgentNotReady
+	s.agents[id] = agent
+	time.AfterFunc(s.upgrades.delay, func() { s.finishUpgrade(id, req.Version) })
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// finishUpgrade restarts an agent with the new version, or records the
+// failure for agents whose upgrades fail.
+func (s *Server) finishUpgrade(id, version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, ok := s.agents[id]
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	if s.upgrades.failing[id] {
+		s.events[id] = append(s.events[id], client.Event{
+			Type:     client.EventWarning,
+			Reason:   "UpgradeFailed",
+			Message:  fmt.Sprintf("Agent failed to start with version %s", version),
+			Count:    1,
+			LastSeen: now,
+		})
+		return
+	}
+
+	agent.Version = version
+	agent.Status = client.AgentReady
+	agent.LastHeartbeat = now
+	s.agents[id] = agent
+	s.heartbeats[id] = append(s.heartbeats[id], client.Heartbeat{Time: now, Status: client.AgentReady})
+	s.events[id] = append(s.events[id], client.Event{
+		Type:     client.EventNormal,
+		Reason:   "Upgraded",
+		Message:  fmt.Sprintf("Agent restarted with version %s", version),
+		Count:    1,
+		LastSeen: now,
+	})
+}
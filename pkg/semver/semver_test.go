@@ -0,0 +1,62 @@
+package semver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    Version
+		expectError bool
+	}{
+		{name: "should parse full version", input: "v1.4.2", expected: Version{Major: 1, Minor: 4, Patch: 2}},
+		{name: "should parse version without prefix", input: "2.0.1", expected: Version{Major: 2, Patch: 1}},
+		{name: "should default missing components", input: "v1.4", expected: Version{Major: 1, Minor: 4}},
+		{name: "should parse prerelease", input: "v1.4.0-rc.1+build.5", expected: Version{Major: 1, Minor: 4, Prerelease: "rc.1"}},
+		{name: "should reject empty version", input: "", expectError: true},
+		{name: "should reject non-numeric component", input: "v1.x.0", expectError: true},
+		{name: "should reject too many components", input: "1.2.3.4", expectError: true},
+		{name: "should reject dev builds", input: "dev", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := Parse(tt.input)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, v)
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{a: "v1.2.3", b: "v1.2.3", expected: 0},
+		{a: "v1.2.3", b: "1.2.3", expected: 0},
+		{a: "v1.2.3", b: "v1.2.4", expected: -1},
+		{a: "v1.10.0", b: "v1.9.9", expected: 1},
+		{a: "v2.0.0", b: "v1.99.99", expected: 1},
+		{a: "v1.4.0-rc.1", b: "v1.4.0", expected: -1},
+		{a: "v1.4.0", b: "v1.4.0-rc.1", expected: 1},
+		{a: "v1.4.0-rc.2", b: "v1.4.0-rc.1", expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			result, err := Compare(tt.a, tt.b)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}